@@ -14,6 +14,9 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strings"
+
+	"github.com/mailru/easyjson/gen"
 )
 
 const genPackage = "github.com/mailru/easyjson/gen"
@@ -26,12 +29,41 @@ type Generator struct {
 	PkgPath, PkgName string
 	Types            []string
 
+	// Imports lists the additional import specs needed by the stub code to
+	// refer to the type arguments of generic type instantiations in Types.
+	Imports []string
+
+	// CaseInsensitiveTypes lists the types of Types that object keys are
+	// matched case-insensitively for if they do not match exactly.
+	CaseInsensitiveTypes []string
+
+	// Unions maps the names of the interface types encoded as discriminated
+	// unions to the discriminator key followed by value=Type pairs of the
+	// variants, e.g. 'type circle=Circle square=*Square'.
+	Unions map[string]string
+
+	// Enums maps the names of the integer types of Types encoded as the JSON
+	// strings of their constants to the options of the easyjson:enum
+	// directive: 'snake_case', 'trim_prefix=Prefix' and 'fallback=Name'.
+	Enums map[string]string
+
+	// EnumConsts maps the types of Enums to their constants in declaration
+	// order, 'Name' or 'Name=json' for the constants with explicit strings.
+	EnumConsts map[string][]string
+
 	NoStdMarshalers          bool
 	SnakeCase                bool
 	LowerCamelCase           bool
 	OmitEmpty                bool
 	DisallowUnknownFields    bool
 	SkipMemberNameUnescaping bool
+	JSONSchema               bool
+	CaseInsensitive          bool
+	DeepCopyEqual            bool
+	SortMapKeys              bool
+	FieldMask                bool
+	MergePatch               bool
+	DiffJSON                 bool
 
 	OutName       string
 	BuildTags     string
@@ -41,11 +73,112 @@ type Generator struct {
 	LeaveTemps  bool
 	NoFormat    bool
 	SimpleBytes bool
+
+	// InProcess makes Run generate the code from the type-checked package
+	// sources instead of writing stubs and running the bootstrapping code
+	// with 'go run'.
+	InProcess bool
+}
+
+// unionSpec is a parsed union of Generator.Unions.
+type unionSpec struct {
+	name     string
+	key      string
+	variants []unionVariant // Sorted by value.
+}
+
+type unionVariant struct {
+	value, typ string
+}
+
+// unionSpecs parses the unions, sorted by name.
+func (g *Generator) unionSpecs() ([]unionSpec, error) {
+	var specs []unionSpec
+	for name, args := range g.Unions {
+		fields := strings.Fields(args)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("union %v: expected a discriminator key and value=Type pairs, got %q", name, args)
+		}
+
+		spec := unionSpec{name: name, key: fields[0]}
+		seen := map[string]bool{}
+		for _, f := range fields[1:] {
+			i := strings.IndexByte(f, '=')
+			if i <= 0 || i == len(f)-1 {
+				return nil, fmt.Errorf("union %v: invalid variant %q, expected value=Type", name, f)
+			}
+			if seen[f[:i]] {
+				return nil, fmt.Errorf("union %v: duplicate discriminator value %q", name, f[:i])
+			}
+			seen[f[:i]] = true
+			spec.variants = append(spec.variants, unionVariant{value: f[:i], typ: f[i+1:]})
+		}
+		sort.Slice(spec.variants, func(i, j int) bool { return spec.variants[i].value < spec.variants[j].value })
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].name < specs[j].name })
+	return specs, nil
+}
+
+// enumSpec is a parsed enum of Generator.Enums.
+type enumSpec struct {
+	name       string
+	trimPrefix string
+	snakeCase  bool
+	fallback   string
+	consts     []enumConst // In declaration order.
+}
+
+type enumConst struct {
+	name, json string
+}
+
+// enumSpecs parses the enums, sorted by name.
+func (g *Generator) enumSpecs() ([]enumSpec, error) {
+	var specs []enumSpec
+	for name, args := range g.Enums {
+		spec := enumSpec{name: name}
+		for _, f := range strings.Fields(args) {
+			switch {
+			case f == "snake_case":
+				spec.snakeCase = true
+			case strings.HasPrefix(f, "trim_prefix="):
+				spec.trimPrefix = strings.TrimPrefix(f, "trim_prefix=")
+			case strings.HasPrefix(f, "fallback="):
+				spec.fallback = strings.TrimPrefix(f, "fallback=")
+			default:
+				return nil, fmt.Errorf("enum %v: unknown option %q", name, f)
+			}
+		}
+
+		for _, c := range g.EnumConsts[name] {
+			if i := strings.IndexByte(c, '='); i >= 0 {
+				spec.consts = append(spec.consts, enumConst{name: c[:i], json: c[i+1:]})
+			} else {
+				spec.consts = append(spec.consts, enumConst{name: c})
+			}
+		}
+		if len(spec.consts) == 0 {
+			return nil, fmt.Errorf("enum %v: no constants of the type found", name)
+		}
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].name < specs[j].name })
+	return specs, nil
 }
 
 // writeStub outputs an initial stub for marshalers/unmarshalers so that the package
 // using marshalers/unmarshales compiles correctly for boostrapping code.
 func (g *Generator) writeStub() error {
+	unions, err := g.unionSpecs()
+	if err != nil {
+		return err
+	}
+	enums, err := g.enumSpecs()
+	if err != nil {
+		return err
+	}
+
 	f, err := os.Create(g.OutName)
 	if err != nil {
 		return err
@@ -66,27 +199,86 @@ func (g *Generator) writeStub() error {
 		fmt.Fprintln(f, "import (")
 		fmt.Fprintln(f, `  "`+pkgWriter+`"`)
 		fmt.Fprintln(f, `  "`+pkgLexer+`"`)
+		for _, imp := range g.Imports {
+			fmt.Fprintln(f, "  "+imp)
+		}
 		fmt.Fprintln(f, ")")
 	}
 
 	sort.Strings(g.Types)
+	stubbed := map[string]bool{}
+	exported := map[string]bool{}
 	for _, t := range g.Types {
 		fmt.Fprintln(f)
-		if !g.NoStdMarshalers {
-			fmt.Fprintln(f, "func (", t, ") MarshalJSON() ([]byte, error) { return nil, nil }")
-			fmt.Fprintln(f, "func (*", t, ") UnmarshalJSON([]byte) error { return nil }")
+
+		// Methods of generic types are declared once for all instantiations.
+		recv := gen.GenericReceiver(t)
+		if !stubbed[recv] {
+			stubbed[recv] = true
+			if !g.NoStdMarshalers {
+				fmt.Fprintln(f, "func (", recv, ") MarshalJSON() ([]byte, error) { return nil, nil }")
+				fmt.Fprintln(f, "func (*", recv, ") UnmarshalJSON([]byte) error { return nil }")
+			}
+
+			fmt.Fprintln(f, "func (", recv, ") MarshalEasyJSON(w *jwriter.Writer) {}")
+			fmt.Fprintln(f, "func (*", recv, ") UnmarshalEasyJSON(l *jlexer.Lexer) {}")
+			fmt.Fprintln(f)
 		}
+		fmt.Fprintln(f, "type "+exporterName(t)+" *"+t)
+		exported[t] = true
+	}
 
-		fmt.Fprintln(f, "func (", t, ") MarshalEasyJSON(w *jwriter.Writer) {}")
-		fmt.Fprintln(f, "func (*", t, ") UnmarshalEasyJSON(l *jlexer.Lexer) {}")
+	// Enum types get text marshalers, and the values of their constants are
+	// referred to.
+	for _, e := range enums {
 		fmt.Fprintln(f)
-		fmt.Fprintln(f, "type EasyJSON_exporter_"+t+" *"+t)
+		fmt.Fprintln(f, "func (", e.name, ") MarshalText() ([]byte, error) { return nil, nil }")
+		fmt.Fprintln(f, "func (*", e.name, ") UnmarshalText([]byte) error { return nil }")
+		for _, c := range e.consts {
+			fmt.Fprintln(f, "const "+exporterName(c.name)+" = "+c.name)
+		}
+	}
+
+	// The union interfaces and the variant types are referred to as well.
+	for _, u := range unions {
+		types := []string{u.name}
+		for _, v := range u.variants {
+			types = append(types, v.typ)
+		}
+		for _, t := range types {
+			if !exported[t] {
+				exported[t] = true
+				fmt.Fprintln(f)
+				fmt.Fprintln(f, "type "+exporterName(t)+" *"+t)
+			}
+		}
 	}
 	return nil
 }
 
+// exporterName returns the name of the exported pointer type used to refer to
+// type t from the bootstrapping code.
+func exporterName(t string) string {
+	return "EasyJSON_exporter_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, strings.Replace(t, " ", "", -1))
+}
+
 // writeMain creates a .go file that launches the generator if 'go run'.
 func (g *Generator) writeMain() (path string, err error) {
+	unions, err := g.unionSpecs()
+	if err != nil {
+		return "", err
+	}
+	enums, err := g.enumSpecs()
+	if err != nil {
+		return "", err
+	}
+
 	f, err := ioutil.TempFile(filepath.Dir(g.OutName), "easyjson-bootstrap")
 	if err != nil {
 		return "", err
@@ -104,7 +296,7 @@ func (g *Generator) writeMain() (path string, err error) {
 	fmt.Fprintln(f, `  "os"`)
 	fmt.Fprintln(f)
 	fmt.Fprintf(f, "  %q\n", genPackage)
-	if len(g.Types) > 0 {
+	if len(g.Types) > 0 || len(unions) > 0 {
 		fmt.Fprintln(f)
 		fmt.Fprintf(f, "  pkg %q\n", g.PkgPath)
 	}
@@ -137,10 +329,52 @@ func (g *Generator) writeMain() (path string, err error) {
 	if g.SkipMemberNameUnescaping {
 		fmt.Fprintln(f, "  g.SkipMemberNameUnescaping()")
 	}
+	if g.JSONSchema {
+		fmt.Fprintln(f, "  g.EmitJSONSchema()")
+	}
+	if g.CaseInsensitive {
+		fmt.Fprintln(f, "  g.CaseInsensitiveKeys()")
+	}
+	if g.DeepCopyEqual {
+		fmt.Fprintln(f, "  g.EmitDeepCopyEqual()")
+	}
+	if g.SortMapKeys {
+		fmt.Fprintln(f, "  g.SortMapKeys()")
+	}
+	if g.FieldMask {
+		fmt.Fprintln(f, "  g.MaskFields()")
+	}
+	if g.MergePatch {
+		fmt.Fprintln(f, "  g.EmitMergePatch()")
+	}
+	if g.DiffJSON {
+		fmt.Fprintln(f, "  g.EmitDiffJSON()")
+	}
 
 	sort.Strings(g.Types)
 	for _, v := range g.Types {
-		fmt.Fprintln(f, "  g.Add(pkg.EasyJSON_exporter_"+v+"(nil))")
+		fmt.Fprintln(f, "  g.Add(pkg."+exporterName(v)+"(nil))")
+	}
+	sort.Strings(g.CaseInsensitiveTypes)
+	for _, v := range g.CaseInsensitiveTypes {
+		fmt.Fprintln(f, "  g.CaseInsensitiveKeysFor(pkg."+exporterName(v)+"(nil))")
+	}
+	for _, u := range unions {
+		fmt.Fprintf(f, "  g.AddUnion(pkg.%s(nil), %q, map[string]interface{}{\n", exporterName(u.name), u.key)
+		for _, v := range u.variants {
+			fmt.Fprintf(f, "    %q: pkg.%s(nil),\n", v.value, exporterName(v.typ))
+		}
+		fmt.Fprintln(f, "  })")
+	}
+	for _, e := range enums {
+		fmt.Fprintf(f, "  g.AddEnum(pkg.%s(nil), gen.Enum{\n", exporterName(e.name))
+		fmt.Fprintf(f, "    TrimPrefix: %q, SnakeCase: %v, Fallback: %q,\n", e.trimPrefix, e.snakeCase, e.fallback)
+		fmt.Fprintln(f, "    Consts: []gen.EnumConst{")
+		for _, c := range e.consts {
+			fmt.Fprintf(f, "      {Name: %q, JSON: %q, Value: fmt.Sprintf(\"%%d\", pkg.%s)},\n", c.name, c.json, exporterName(c.name))
+		}
+		fmt.Fprintln(f, "    },")
+		fmt.Fprintln(f, "  })")
 	}
 
 	fmt.Fprintln(f, "  if err := g.Run(os.Stdout); err != nil {")
@@ -159,6 +393,10 @@ func (g *Generator) writeMain() (path string, err error) {
 }
 
 func (g *Generator) Run() error {
+	if g.InProcess && !g.StubsOnly {
+		return g.runInProcess()
+	}
+
 	if err := g.writeStub(); err != nil {
 		return err
 	}
@@ -0,0 +1,242 @@
+package bootstrap
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mailru/easyjson/gen"
+)
+
+// The importer type-checks the imported packages from sources, so it is shared
+// by all generators to type-check each of the dependencies only once.
+var (
+	typesMu       sync.Mutex
+	typesFileSet  = token.NewFileSet()
+	typesImporter = importer.ForCompiler(typesFileSet, "source", nil)
+)
+
+// loadPackage type-checks the package of the output file. The output file is
+// excluded since it is going to be regenerated. Type errors do not abort the
+// type checking: the package may not compile before the code is generated.
+// The first type error is returned along with the package.
+func (g *Generator) loadPackage(fset *token.FileSet) (*types.Package, error) {
+	ctxt := build.Default
+	ctxt.BuildTags = strings.FieldsFunc(g.BuildTags, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+
+	dir := filepath.Dir(g.OutName)
+	bp, err := ctxt.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+	for _, name := range append(bp.GoFiles, bp.CgoFiles...) {
+		if name == filepath.Base(g.OutName) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	var typeErr error
+	conf := types.Config{
+		Importer:    typesImporter,
+		FakeImportC: true,
+		Error: func(err error) {
+			if typeErr == nil {
+				typeErr = err
+			}
+		},
+	}
+	pkg, _ := conf.Check(g.PkgPath, fset, files, nil)
+	return pkg, typeErr
+}
+
+// lookupType returns the type with the given name, which may be an
+// instantiation of a generic type, e.g. 'Page[opt.Int]'.
+func lookupType(fset *token.FileSet, pkg *types.Package, name string) (types.Type, error) {
+	base := name
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		base = name[:i]
+	}
+
+	obj, ok := pkg.Scope().Lookup(base).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %v not found in package %v", base, pkg.Path())
+	}
+	if base == name {
+		return obj.Type(), nil
+	}
+
+	// Evaluate the instantiation in the scope of the file declaring the
+	// generic type, so that the type arguments may refer to its imports.
+	tv, err := types.Eval(fset, pkg, obj.Pos(), name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot instantiate %v: %v", name, err)
+	}
+	return tv.Type, nil
+}
+
+// lookupVariantType returns the union variant type with the given name, which
+// may be a pointer type, e.g. '*Square'.
+func lookupVariantType(fset *token.FileSet, pkg *types.Package, name string) (types.Type, error) {
+	if strings.HasPrefix(name, "*") {
+		t, err := lookupType(fset, pkg, name[1:])
+		if err != nil {
+			return nil, err
+		}
+		return types.NewPointer(t), nil
+	}
+	return lookupType(fset, pkg, name)
+}
+
+// runInProcess generates the output file from the type-checked package
+// without running the bootstrapping code.
+func (g *Generator) runInProcess() error {
+	typesMu.Lock()
+	defer typesMu.Unlock()
+
+	fset := typesFileSet
+	pkg, typeErr := g.loadPackage(fset)
+	if pkg == nil {
+		return typeErr
+	}
+
+	gn := gen.NewGenerator(filepath.Base(g.OutName))
+	gn.SetPkg(g.PkgName, g.PkgPath)
+	if g.BuildTags != "" {
+		gn.SetBuildTags(g.BuildTags)
+	}
+	if g.SnakeCase {
+		gn.UseSnakeCase()
+	}
+	if g.LowerCamelCase {
+		gn.UseLowerCamelCase()
+	}
+	if g.OmitEmpty {
+		gn.OmitEmpty()
+	}
+	if g.NoStdMarshalers {
+		gn.NoStdMarshalers()
+	}
+	if g.DisallowUnknownFields {
+		gn.DisallowUnknownFields()
+	}
+	if g.SimpleBytes {
+		gn.SimpleBytes()
+	}
+	if g.SkipMemberNameUnescaping {
+		gn.SkipMemberNameUnescaping()
+	}
+	if g.JSONSchema {
+		gn.EmitJSONSchema()
+	}
+	if g.CaseInsensitive {
+		gn.CaseInsensitiveKeys()
+	}
+	if g.DeepCopyEqual {
+		gn.EmitDeepCopyEqual()
+	}
+	if g.SortMapKeys {
+		gn.SortMapKeys()
+	}
+	if g.FieldMask {
+		gn.MaskFields()
+	}
+	if g.MergePatch {
+		gn.EmitMergePatch()
+	}
+	if g.DiffJSON {
+		gn.EmitDiffJSON()
+	}
+
+	sort.Strings(g.Types)
+	for _, name := range g.Types {
+		t, err := lookupType(fset, pkg, name)
+		if err != nil {
+			return err
+		}
+		gn.AddType(t)
+	}
+	for _, name := range g.CaseInsensitiveTypes {
+		t, err := lookupType(fset, pkg, name)
+		if err != nil {
+			return err
+		}
+		gn.CaseInsensitiveKeysForType(t)
+	}
+
+	unions, err := g.unionSpecs()
+	if err != nil {
+		return err
+	}
+	for _, u := range unions {
+		t, err := lookupType(fset, pkg, u.name)
+		if err != nil {
+			return err
+		}
+		variants := make(map[string]types.Type, len(u.variants))
+		for _, v := range u.variants {
+			vt, err := lookupVariantType(fset, pkg, v.typ)
+			if err != nil {
+				return err
+			}
+			variants[v.value] = vt
+		}
+		gn.AddUnionType(t, u.key, variants)
+	}
+
+	enums, err := g.enumSpecs()
+	if err != nil {
+		return err
+	}
+	for _, e := range enums {
+		t, err := lookupType(fset, pkg, e.name)
+		if err != nil {
+			return err
+		}
+		consts := make([]gen.EnumConst, 0, len(e.consts))
+		for _, c := range e.consts {
+			obj, ok := pkg.Scope().Lookup(c.name).(*types.Const)
+			if !ok {
+				return fmt.Errorf("constant %v not found in package %v", c.name, pkg.Path())
+			}
+			consts = append(consts, gen.EnumConst{Name: c.name, JSON: c.json, Value: obj.Val().ExactString()})
+		}
+		gn.AddEnumType(t, gen.Enum{Consts: consts, TrimPrefix: e.trimPrefix, SnakeCase: e.snakeCase, Fallback: e.fallback})
+	}
+
+	var out bytes.Buffer
+	if err := gn.Run(&out); err != nil {
+		if typeErr != nil {
+			return fmt.Errorf("%v (type checking failed: %v)", err, typeErr)
+		}
+		return err
+	}
+
+	src := out.Bytes()
+	if !g.NoFormat {
+		var err error
+		if src, err = format.Source(src); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(g.OutName, src, 0644)
+}
@@ -0,0 +1,82 @@
+package easyjson
+
+import (
+	"math/big"
+	"reflect"
+)
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+)
+
+// DeepCopyValue returns a deep copy of v made with reflection, following the rules of the
+// generated DeepCopy methods: the fields that are encoded to JSON are copied recursively, while
+// unexported fields and fields tagged `json:"-"` are copied by assignment. Generated code uses
+// it for the instantiations of generic types that no DeepCopy method is generated for.
+func DeepCopyValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return deepCopyValue(reflect.ValueOf(v)).Interface()
+}
+
+// deepCopyValue returns a deep copy of v, see DeepCopyValue.
+func deepCopyValue(v reflect.Value) reflect.Value {
+	t := v.Type()
+	out := reflect.New(t).Elem()
+
+	switch {
+	case t == bigIntType:
+		x := v.Interface().(big.Int)
+		out.Set(reflect.ValueOf(new(big.Int).Set(&x)).Elem())
+		return out
+	case t == bigFloatType:
+		x := v.Interface().(big.Float)
+		out.Set(reflect.ValueOf(new(big.Float).Copy(&x)).Elem())
+		return out
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			out.Set(reflect.New(t.Elem()))
+			out.Elem().Set(deepCopyValue(v.Elem()))
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			out.Set(deepCopyValue(v.Elem()))
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			out.Set(reflect.MakeSlice(t, v.Len(), v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				out.Index(i).Set(deepCopyValue(v.Index(i)))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopyValue(v.Index(i)))
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			out.Set(reflect.MakeMapWithSize(t, v.Len()))
+			iter := v.MapRange()
+			for iter.Next() {
+				out.SetMapIndex(iter.Key(), deepCopyValue(iter.Value()))
+			}
+		}
+	case reflect.Struct:
+		out.Set(v)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" || f.Tag.Get("json") == "-" {
+				continue
+			}
+			out.Field(i).Set(deepCopyValue(v.Field(i)))
+		}
+	default:
+		out.Set(v)
+	}
+	return out
+}
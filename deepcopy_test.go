@@ -0,0 +1,52 @@
+package easyjson
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+type deepCopyStruct struct {
+	Items  []string
+	Labels map[string][]int
+	Next   *deepCopyStruct
+	Any    interface{}
+	Big    big.Int
+	Cache  []int `json:"-"`
+	hidden []int
+}
+
+func TestDeepCopyValue(t *testing.T) {
+	orig := &deepCopyStruct{
+		Items:  []string{"a"},
+		Labels: map[string][]int{"x": {1}},
+		Next:   &deepCopyStruct{Items: []string{"b"}},
+		Any:    []interface{}{map[string]interface{}{"y": 1.0}},
+		Big:    *big.NewInt(7),
+		Cache:  []int{2},
+		hidden: []int{3},
+	}
+
+	cp := DeepCopyValue(orig).(*deepCopyStruct)
+	if !reflect.DeepEqual(cp, orig) {
+		t.Fatalf("DeepCopyValue() = %+v; want %+v", cp, orig)
+	}
+
+	cp.Items[0] = "z"
+	cp.Labels["x"][0] = 0
+	cp.Next.Items[0] = "z"
+	cp.Any.([]interface{})[0].(map[string]interface{})["y"] = 0.0
+	cp.Big.SetInt64(8)
+	if orig.Items[0] != "a" || orig.Labels["x"][0] != 1 || orig.Next.Items[0] != "b" ||
+		orig.Any.([]interface{})[0].(map[string]interface{})["y"] != 1.0 || orig.Big.Int64() != 7 {
+		t.Errorf("original after modifying the copy = %+v", orig)
+	}
+
+	// Fields that are not encoded are copied by assignment.
+	if &cp.Cache[0] != &orig.Cache[0] || &cp.hidden[0] != &orig.hidden[0] {
+		t.Errorf("DeepCopyValue() copied the fields that are not encoded")
+	}
+	if got := DeepCopyValue(nil); got != nil {
+		t.Errorf("DeepCopyValue(nil) = %v; want nil", got)
+	}
+}
@@ -31,6 +31,14 @@ var specifiedName = flag.String("output_filename", "", "specify the filename of
 var processPkg = flag.Bool("pkg", false, "process the whole package instead of just the given file")
 var disallowUnknownFields = flag.Bool("disallow_unknown_fields", false, "return error if any unknown field in json appeared")
 var skipMemberNameUnescaping = flag.Bool("disable_members_unescape", false, "don't perform unescaping of member names to improve performance")
+var inProcess = flag.Bool("in_process", false, "generate code from type-checked sources without running bootstrapping code with 'go run'")
+var jsonSchema = flag.Bool("json_schema", false, "generate JSONSchema() methods returning JSON Schema documents of the types")
+var caseInsensitive = flag.Bool("case_insensitive", false, "match object keys case-insensitively if they do not match a field exactly")
+var deepCopyEqual = flag.Bool("deep_copy_equal", false, "generate DeepCopy and Equal methods")
+var sortMapKeys = flag.Bool("sort_map_keys", false, "encode maps with string keys in sorted key order")
+var fieldMask = flag.Bool("field_mask", false, "leave out the fields off the paths of the field mask of the writer when encoding")
+var mergePatch = flag.Bool("merge_patch", false, "generate ApplyMergePatch methods applying JSON merge patches")
+var diffJSON = flag.Bool("diff_json", false, "generate DiffJSON methods returning JSON patches between values")
 
 func generate(fname string) (err error) {
 	fInfo, err := os.Stat(fname)
@@ -74,11 +82,24 @@ func generate(fname string) (err error) {
 		PkgPath:                  p.PkgPath,
 		PkgName:                  p.PkgName,
 		Types:                    p.StructNames,
+		Imports:                  p.Imports,
+		CaseInsensitiveTypes:     p.CaseInsensitiveNames,
+		Unions:                   p.Unions,
+		Enums:                    p.Enums,
+		EnumConsts:               p.EnumConsts,
 		SnakeCase:                *snakeCase,
 		LowerCamelCase:           *lowerCamelCase,
 		NoStdMarshalers:          *noStdMarshalers,
 		DisallowUnknownFields:    *disallowUnknownFields,
 		SkipMemberNameUnescaping: *skipMemberNameUnescaping,
+		JSONSchema:               *jsonSchema,
+		CaseInsensitive:          *caseInsensitive,
+		DeepCopyEqual:            *deepCopyEqual,
+		SortMapKeys:              *sortMapKeys,
+		FieldMask:                *fieldMask,
+		MergePatch:               *mergePatch,
+		DiffJSON:                 *diffJSON,
+		InProcess:                *inProcess,
 		OmitEmpty:                *omitEmpty,
 		LeaveTemps:               *leaveTemps,
 		OutName:                  outName,
@@ -0,0 +1,83 @@
+package gen
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// isBigNumber reports whether t is big.Int or big.Float.
+func isBigNumber(t goType) bool {
+	return isType(t, reflect.TypeOf(big.Int{})) || isType(t, reflect.TypeOf(big.Float{}))
+}
+
+// isBigNumberType reports whether t is big.Int, big.Float or a pointer to one of them, the types
+// that are encoded by the generated code itself instead of their marshaler methods.
+func isBigNumberType(t goType) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return isBigNumber(t)
+}
+
+// bigNumberAsString reports whether the number of type t is encoded as a string: a big.Float
+// unless the format tag is 'number', like big.Float.MarshalText, and a big.Int with the string
+// tag or the format tag 'string'.
+func bigNumberAsString(t goType, tags fieldTags) (bool, error) {
+	switch tags.format {
+	case "":
+		return tags.asString || isType(t, reflect.TypeOf(big.Float{})), nil
+	case "string":
+		return true, nil
+	case "number":
+		return false, nil
+	}
+	return false, fmt.Errorf("unsupported format %q for %v", tags.format, t)
+}
+
+// genBigNumberEncoder generates code that encodes in of type big.Int, big.Float or a pointer to
+// one of them.
+func (g *Generator) genBigNumberEncoder(t goType, in string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	} else {
+		in = addressOf(in)
+	}
+	asString, err := bigNumberAsString(t, tags)
+	if err != nil {
+		return err
+	}
+
+	enc := "out.Big" + t.Name()
+	if asString {
+		enc += "Str"
+	}
+	fmt.Fprintln(g.out, ws+enc+"("+in+")")
+	return nil
+}
+
+// genBigNumberDecoder generates code that decodes out of type big.Int, big.Float or a pointer to
+// one of them. A null leaves a value unchanged like the UnmarshalJSON methods.
+func (g *Generator) genBigNumberDecoder(t goType, out string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+
+	if t.Kind() == reflect.Ptr {
+		if _, err := bigNumberAsString(t.Elem(), tags); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+out+" = in.Big"+t.Elem().Name()+"()")
+		return nil
+	}
+	if _, err := bigNumberAsString(t, tags); err != nil {
+		return err
+	}
+
+	tmpVar := g.uniqueVarName()
+	fmt.Fprintln(g.out, ws+"if "+tmpVar+" := in.Big"+t.Name()+"(); "+tmpVar+" != nil {")
+	fmt.Fprintln(g.out, ws+"  "+out+" = *"+tmpVar)
+	fmt.Fprintln(g.out, ws+"}")
+	return nil
+}
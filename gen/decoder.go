@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -15,7 +16,7 @@ import (
 // Target this byte size for initial slice allocation to reduce garbage collection.
 const minSliceBytes = 64
 
-func (g *Generator) getDecoderName(t reflect.Type) string {
+func (g *Generator) getDecoderName(t goType) string {
 	return g.functionName("decode", t)
 }
 
@@ -58,17 +59,24 @@ var customDecoders = map[string]string{
 }
 
 // genTypeDecoder generates decoding code for the type t, but uses unmarshaler interface if implemented by t.
-func (g *Generator) genTypeDecoder(t reflect.Type, out string, tags fieldTags, indent int) error {
+func (g *Generator) genTypeDecoder(t goType, out string, tags fieldTags, indent int) error {
 	ws := strings.Repeat("  ", indent)
 
+	if isTimeType(t, tags) {
+		return g.genTimeDecoder(t, out, tags, indent)
+	}
+	if isBigNumberType(t) {
+		return g.genBigNumberDecoder(t, out, tags, indent)
+	}
+
 	unmarshalerIface := reflect.TypeOf((*easyjson.Unmarshaler)(nil)).Elem()
-	if reflect.PtrTo(t).Implements(unmarshalerIface) {
+	if t.PtrTo().Implements(unmarshalerIface) {
 		fmt.Fprintln(g.out, ws+"("+out+").UnmarshalEasyJSON(in)")
 		return nil
 	}
 
 	unmarshalerIface = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
-	if reflect.PtrTo(t).Implements(unmarshalerIface) {
+	if t.PtrTo().Implements(unmarshalerIface) {
 		fmt.Fprintln(g.out, ws+"if data := in.Raw(); in.Ok() {")
 		fmt.Fprintln(g.out, ws+"  in.AddError( ("+out+").UnmarshalJSON(data) )")
 		fmt.Fprintln(g.out, ws+"}")
@@ -76,7 +84,7 @@ func (g *Generator) genTypeDecoder(t reflect.Type, out string, tags fieldTags, i
 	}
 
 	unmarshalerIface = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
-	if reflect.PtrTo(t).Implements(unmarshalerIface) {
+	if t.PtrTo().Implements(unmarshalerIface) {
 		fmt.Fprintln(g.out, ws+"if data := in.UnsafeBytes(); in.Ok() {")
 		fmt.Fprintln(g.out, ws+"  in.AddError( ("+out+").UnmarshalText(data) )")
 		fmt.Fprintln(g.out, ws+"}")
@@ -88,25 +96,25 @@ func (g *Generator) genTypeDecoder(t reflect.Type, out string, tags fieldTags, i
 }
 
 // returns true if the type t implements one of the custom unmarshaler interfaces
-func hasCustomUnmarshaler(t reflect.Type) bool {
-	t = reflect.PtrTo(t)
+func hasCustomUnmarshaler(t goType) bool {
+	t = t.PtrTo()
 	return t.Implements(reflect.TypeOf((*easyjson.Unmarshaler)(nil)).Elem()) ||
 		t.Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) ||
 		t.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
 }
 
-func hasUnknownsUnmarshaler(t reflect.Type) bool {
-	t = reflect.PtrTo(t)
+func hasUnknownsUnmarshaler(t goType) bool {
+	t = t.PtrTo()
 	return t.Implements(reflect.TypeOf((*easyjson.UnknownsUnmarshaler)(nil)).Elem())
 }
 
-func hasUnknownsMarshaler(t reflect.Type) bool {
-	t = reflect.PtrTo(t)
+func hasUnknownsMarshaler(t goType) bool {
+	t = t.PtrTo()
 	return t.Implements(reflect.TypeOf((*easyjson.UnknownsMarshaler)(nil)).Elem())
 }
 
 // genTypeDecoderNoCheck generates decoding code for the type t.
-func (g *Generator) genTypeDecoderNoCheck(t reflect.Type, out string, tags fieldTags, indent int) error {
+func (g *Generator) genTypeDecoderNoCheck(t goType, out string, tags fieldTags, indent int) error {
 	ws := strings.Repeat("  ", indent)
 	// Check whether type is primitive, needs to be done after interface check.
 	if dec := customDecoders[t.String()]; dec != "" {
@@ -170,11 +178,13 @@ func (g *Generator) genTypeDecoderNoCheck(t reflect.Type, out string, tags field
 			fmt.Fprintln(g.out, ws+"  }")
 			fmt.Fprintln(g.out, ws+"  for !in.IsDelim(']') {")
 			fmt.Fprintln(g.out, ws+"    var "+tmpVar+" "+g.getType(elem))
+			fmt.Fprintln(g.out, ws+"    in.PushIndex(len("+out+"))")
 
 			if err := g.genTypeDecoder(elem, tmpVar, tags, indent+2); err != nil {
 				return err
 			}
 
+			fmt.Fprintln(g.out, ws+"    in.PopPath()")
 			fmt.Fprintln(g.out, ws+"    "+out+" = append("+out+", "+tmpVar+")")
 			fmt.Fprintln(g.out, ws+"    in.WantComma()")
 			fmt.Fprintln(g.out, ws+"  }")
@@ -204,11 +214,13 @@ func (g *Generator) genTypeDecoderNoCheck(t reflect.Type, out string, tags field
 			fmt.Fprintln(g.out, ws+"  "+iterVar+" := 0")
 			fmt.Fprintln(g.out, ws+"  for !in.IsDelim(']') {")
 			fmt.Fprintln(g.out, ws+"    if "+iterVar+" < "+fmt.Sprint(length)+" {")
+			fmt.Fprintln(g.out, ws+"      in.PushIndex("+iterVar+")")
 
 			if err := g.genTypeDecoder(elem, "("+out+")["+iterVar+"]", tags, indent+3); err != nil {
 				return err
 			}
 
+			fmt.Fprintln(g.out, ws+"      in.PopPath()")
 			fmt.Fprintln(g.out, ws+"      "+iterVar+"++")
 			fmt.Fprintln(g.out, ws+"    } else {")
 			fmt.Fprintln(g.out, ws+"      in.SkipRecursive()")
@@ -271,7 +283,7 @@ func (g *Generator) genTypeDecoderNoCheck(t reflect.Type, out string, tags field
 
 		fmt.Fprintln(g.out, ws+"  for !in.IsDelim('}') {")
 		// NOTE: extra check for TextUnmarshaler. It overrides default methods.
-		if reflect.PtrTo(key).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
+		if key.PtrTo().Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
 			fmt.Fprintln(g.out, ws+"    var key "+g.getType(key))
 			fmt.Fprintln(g.out, ws+"if data := in.UnsafeBytes(); in.Ok() {")
 			fmt.Fprintln(g.out, ws+"  in.AddError(key.UnmarshalText(data) )")
@@ -286,12 +298,30 @@ func (g *Generator) genTypeDecoderNoCheck(t reflect.Type, out string, tags field
 		}
 
 		fmt.Fprintln(g.out, ws+"    in.WantColon()")
+
+		// Only string keys are tracked in the path to avoid key conversions.
+		trackKey := key.Kind() == reflect.String
+		if trackKey {
+			fmt.Fprintln(g.out, ws+"    if !in.Projected(string(key)) {")
+			fmt.Fprintln(g.out, ws+"      in.SkipRecursive()")
+			fmt.Fprintln(g.out, ws+"      in.WantComma()")
+			fmt.Fprintln(g.out, ws+"      continue")
+			fmt.Fprintln(g.out, ws+"    }")
+		}
+
 		fmt.Fprintln(g.out, ws+"    var "+tmpVar+" "+g.getType(elem))
+		if trackKey {
+			fmt.Fprintln(g.out, ws+"    in.PushField(string(key), \"\", \"\")")
+		}
 
 		if err := g.genTypeDecoder(elem, tmpVar, tags, indent+2); err != nil {
 			return err
 		}
 
+		if trackKey {
+			fmt.Fprintln(g.out, ws+"    in.PopPath()")
+		}
+
 		fmt.Fprintln(g.out, ws+"    ("+out+")[key] = "+tmpVar)
 		fmt.Fprintln(g.out, ws+"    in.WantComma()")
 		fmt.Fprintln(g.out, ws+"  }")
@@ -299,7 +329,16 @@ func (g *Generator) genTypeDecoderNoCheck(t reflect.Type, out string, tags field
 		fmt.Fprintln(g.out, ws+"}")
 
 	case reflect.Interface:
-		if t.NumMethod() != 0 {
+		if g.unions[t] != nil {
+			dec := g.getDecoderName(t)
+			g.addType(t)
+
+			if len(out) > 0 && out[0] == '*' {
+				fmt.Fprintln(g.out, ws+dec+"(in, "+out[1:]+")")
+			} else {
+				fmt.Fprintln(g.out, ws+dec+"(in, &"+out+")")
+			}
+		} else if t.NumMethod() != 0 {
 			if g.interfaceIsEasyjsonUnmarshaller(t) {
 				fmt.Fprintln(g.out, ws+out+".UnmarshalEasyJSON(in)")
 			} else if g.interfaceIsJsonUnmarshaller(t) {
@@ -323,17 +362,20 @@ func (g *Generator) genTypeDecoderNoCheck(t reflect.Type, out string, tags field
 
 }
 
-func (g *Generator) interfaceIsEasyjsonUnmarshaller(t reflect.Type) bool {
+func (g *Generator) interfaceIsEasyjsonUnmarshaller(t goType) bool {
 	return t.Implements(reflect.TypeOf((*easyjson.Unmarshaler)(nil)).Elem())
 }
 
-func (g *Generator) interfaceIsJsonUnmarshaller(t reflect.Type) bool {
+func (g *Generator) interfaceIsJsonUnmarshaller(t goType) bool {
 	return t.Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem())
 }
 
-func (g *Generator) genStructFieldDecoder(t reflect.Type, f reflect.StructField) error {
-	jsonName := g.fieldNamer.GetJSONFieldName(t, f)
-	tags := parseFieldTags(f)
+// genStructFieldDecoder generates the case of the key switch decoding field f.
+// If fold is set, the case matches the key case-insensitively. If project is
+// set, the case skips the value unless the field is projected.
+func (g *Generator) genStructFieldDecoder(t goType, f structField, fold, project bool) error {
+	jsonName := g.jsonFieldName(t, f)
+	tags := parseFieldTags(f.StructField)
 
 	if tags.omit {
 		return nil
@@ -342,31 +384,75 @@ func (g *Generator) genStructFieldDecoder(t reflect.Type, f reflect.StructField)
 		return errors.New("Mutually exclusive tags are specified: 'intern' and 'nocopy'")
 	}
 
-	fmt.Fprintf(g.out, "    case %q:\n", jsonName)
-	if err := g.genTypeDecoder(f.Type, "out."+f.Name, tags, 3); err != nil {
+	if fold {
+		fmt.Fprintf(g.out, "    case strings.EqualFold(key, %q):\n", jsonName)
+	} else {
+		fmt.Fprintf(g.out, "    case %q:\n", jsonName)
+	}
+	if project {
+		g.genProjectionCheck(strconv.Quote(jsonName))
+	}
+	fmt.Fprintf(g.out, "      in.PushField(%q, %q, %q)\n", jsonName, goFieldName(t, f), f.Type.String())
+	if err := g.genTypeDecoder(f.Type, "out."+f.selector(), tags, 3); err != nil {
+		return err
+	}
+	if err := g.genFieldValidation(t, f, 3); err != nil {
 		return err
 	}
+	fmt.Fprintln(g.out, "      in.PopPath()")
 
-	if tags.required {
-		fmt.Fprintf(g.out, "%sSet = true\n", f.Name)
+	if tags.required || tags.hasDefault {
+		fmt.Fprintf(g.out, "%s = true\n", setVarName(f))
 	}
 
 	return nil
 }
 
-func (g *Generator) genRequiredFieldSet(t reflect.Type, f reflect.StructField) {
-	tags := parseFieldTags(f)
+// genProjectionCheck generates code skipping the value of the member in the
+// key switch unless the member with the name in the expression name is projected.
+func (g *Generator) genProjectionCheck(name string) {
+	fmt.Fprintln(g.out, "      if !in.Projected("+name+") {")
+	fmt.Fprintln(g.out, "        in.SkipRecursive()")
+	fmt.Fprintln(g.out, "        break")
+	fmt.Fprintln(g.out, "      }")
+}
 
-	if !tags.required {
+// goFieldName returns the name of struct field f of type t for error messages,
+// e.g. "Item.Price".
+func goFieldName(t goType, f structField) string {
+	if t.Name() == "" {
+		return f.selector()
+	}
+	return pkgPathPrefixRegexp.ReplaceAllString(t.Name(), "") + "." + f.selector()
+}
+
+// setVarName returns the name of the variable tracking whether field f was
+// present in the input. The names of the fields of inline struct fields are
+// made of their index sequences, which cannot clash with the field names.
+func setVarName(f structField) string {
+	if f.prefix == "" {
+		return strings.Replace(f.Name, ".", "", -1) + "Set"
+	}
+	index := make([]string, len(f.index))
+	for i, v := range f.index {
+		index[i] = strconv.Itoa(v)
+	}
+	return "inline" + strings.Join(index, "_") + "Set"
+}
+
+func (g *Generator) genRequiredFieldSet(t goType, f structField) {
+	tags := parseFieldTags(f.StructField)
+
+	if !tags.required && !tags.hasDefault {
 		return
 	}
 
-	fmt.Fprintf(g.out, "var %sSet bool\n", f.Name)
+	fmt.Fprintf(g.out, "var %s bool\n", setVarName(f))
 }
 
-func (g *Generator) genRequiredFieldCheck(t reflect.Type, f reflect.StructField) {
-	jsonName := g.fieldNamer.GetJSONFieldName(t, f)
-	tags := parseFieldTags(f)
+func (g *Generator) genRequiredFieldCheck(t goType, f structField) {
+	jsonName := g.jsonFieldName(t, f)
+	tags := parseFieldTags(f.StructField)
 
 	if !tags.required {
 		return
@@ -374,12 +460,101 @@ func (g *Generator) genRequiredFieldCheck(t reflect.Type, f reflect.StructField)
 
 	g.imports["fmt"] = "fmt"
 
-	fmt.Fprintf(g.out, "if !%sSet {\n", f.Name)
+	fmt.Fprintf(g.out, "if !%s && in.Projected(%q) {\n", setVarName(f), jsonName)
 	fmt.Fprintf(g.out, "    in.AddError(fmt.Errorf(\"key '%s' is required\"))\n", jsonName)
 	fmt.Fprintf(g.out, "}\n")
 }
 
-func mergeStructFields(fields1, fields2 []reflect.StructField) (fields []reflect.StructField) {
+// genDefaultValue generates code setting field f to the value of its default
+// tag if the field is projected but was not present in the input.
+func (g *Generator) genDefaultValue(t goType, f structField) error {
+	tags := parseFieldTags(f.StructField)
+
+	if !tags.hasDefault {
+		return nil
+	}
+
+	value, err := g.defaultValue(f.Type, tags.defaultValue)
+	if err != nil {
+		return fmt.Errorf("invalid default value %q of field %v: %v", tags.defaultValue, goFieldName(t, f), err)
+	}
+
+	fmt.Fprintf(g.out, "if !%s && in.Projected(%q) {\n", setVarName(f), g.jsonFieldName(t, f))
+	fmt.Fprintf(g.out, "    out.%s = %s\n", f.selector(), value)
+	fmt.Fprintf(g.out, "}\n")
+	return nil
+}
+
+// defaultValue returns the Go expression of type t for the value of a default
+// tag. Values of slices are comma-separated lists of the elements.
+func (g *Generator) defaultValue(t goType, value string) (string, error) {
+	if t.PkgPath() == pkgOpt {
+		if f, ok := t.FieldByName("V"); ok {
+			v, err := g.defaultValue(f.Type, value)
+			if err != nil {
+				return "", err
+			}
+			return g.getType(t) + "{V: " + v + ", Defined: true}", nil
+		}
+	}
+
+	var lit string
+	switch t.Kind() {
+	case reflect.String:
+		lit = strconv.Quote(value)
+
+	case reflect.Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", err
+		}
+		lit = strconv.FormatBool(v)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(strings.TrimSpace(value), 10, int(t.Size())*8)
+		if err != nil {
+			return "", err
+		}
+		lit = strconv.FormatInt(v, 10)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v, err := strconv.ParseUint(strings.TrimSpace(value), 10, int(t.Size())*8)
+		if err != nil {
+			return "", err
+		}
+		lit = strconv.FormatUint(v, 10)
+
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), int(t.Size())*8)
+		if err != nil {
+			return "", err
+		}
+		lit = strconv.FormatFloat(v, 'g', -1, int(t.Size())*8)
+
+	case reflect.Slice:
+		var elems []string
+		if value != "" {
+			for _, s := range strings.Split(value, ",") {
+				v, err := g.defaultValue(t.Elem(), s)
+				if err != nil {
+					return "", err
+				}
+				elems = append(elems, v)
+			}
+		}
+		return g.getType(t) + "{" + strings.Join(elems, ", ") + "}", nil
+
+	default:
+		return "", fmt.Errorf("default values of type %v are not supported", t)
+	}
+
+	if typ := g.getType(t); typ != t.Kind().String() {
+		return typ + "(" + lit + ")", nil
+	}
+	return lit, nil
+}
+
+func mergeStructFields(fields1, fields2 []structField) (fields []structField) {
 	used := map[string]bool{}
 	for _, f := range fields2 {
 		used[f.Name] = true
@@ -394,17 +569,18 @@ func mergeStructFields(fields1, fields2 []reflect.StructField) (fields []reflect
 	return
 }
 
-func getStructFields(t reflect.Type) ([]reflect.StructField, error) {
+func getStructFields(t goType) ([]structField, error) {
 	if t.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("got %v; expected a struct", t)
 	}
 
-	var efields []reflect.StructField
-	var fields []reflect.StructField
+	var efields []structField
+	var fields []structField
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
-		tags := parseFieldTags(f)
-		if !f.Anonymous || tags.name != "" {
+		tags := parseFieldTags(f.StructField)
+		embedded := f.Anonymous && tags.name == ""
+		if !embedded && (!tags.inline || tags.omit) {
 			continue
 		}
 
@@ -418,9 +594,31 @@ func getStructFields(t reflect.Type) ([]reflect.StructField, error) {
 			if err != nil {
 				return nil, fmt.Errorf("error processing embedded field: %v", err)
 			}
+			for j := range fs {
+				fs[j].index = append([]int{i}, fs[j].index...)
+			}
+			if !embedded {
+				// Fields of inline struct fields are not promoted.
+				for i := range fs {
+					ptrs := make([]string, 0, len(fs[i].inlinePtrs)+1)
+					if f.Type.Kind() == reflect.Ptr {
+						ptrs = append(ptrs, f.Name)
+					}
+					for _, p := range fs[i].inlinePtrs {
+						ptrs = append(ptrs, f.Name+"."+p)
+					}
+					fs[i].prefix = f.Name + "." + fs[i].prefix
+					fs[i].inlinePtrs = ptrs
+				}
+			}
 			efields = mergeStructFields(efields, fs)
+		} else if !embedded {
+			if f.Type.Kind() != reflect.Map {
+				return nil, fmt.Errorf("inline field %v is not a struct or a map", f.Name)
+			}
 		} else if (t1.Kind() >= reflect.Bool && t1.Kind() < reflect.Complex128) || t1.Kind() == reflect.String {
 			if strings.Contains(f.Name, ".") || unicode.IsUpper([]rune(f.Name)[0]) {
+				f.index = []int{i}
 				fields = append(fields, f)
 			}
 		}
@@ -428,29 +626,59 @@ func getStructFields(t reflect.Type) ([]reflect.StructField, error) {
 
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
-		tags := parseFieldTags(f)
-		if f.Anonymous && tags.name == "" {
+		tags := parseFieldTags(f.StructField)
+		if f.Anonymous && tags.name == "" || tags.inline && !tags.omit {
 			continue
 		}
 
 		c := []rune(f.Name)[0]
 		if unicode.IsUpper(c) {
+			f.index = []int{i}
 			fields = append(fields, f)
 		}
 	}
 	return mergeStructFields(efields, fields), nil
 }
 
-func (g *Generator) genDecoder(t reflect.Type) error {
+// getInlineMapField returns the inline map field of struct t, which collects
+// the object members not matching other fields.
+func getInlineMapField(t goType) (structField, bool, error) {
+	var (
+		ret   structField
+		found bool
+	)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tags := parseFieldTags(f.StructField)
+		if !tags.inline || tags.omit || f.Type.Kind() != reflect.Map {
+			continue
+		}
+		if f.Type.Key().Kind() != reflect.String {
+			return structField{}, false, fmt.Errorf("inline map field %v must have string keys", f.Name)
+		}
+		if found {
+			return structField{}, false, fmt.Errorf("multiple inline map fields: %v and %v", ret.Name, f.Name)
+		}
+		ret, found = f, true
+	}
+	return ret, found, nil
+}
+
+func (g *Generator) genDecoder(t goType) error {
+	if g.enums[t] != nil {
+		return g.genEnumDecoder(t)
+	}
 	switch t.Kind() {
 	case reflect.Slice, reflect.Array, reflect.Map:
 		return g.genSliceArrayDecoder(t)
+	case reflect.Interface:
+		return g.genUnionDecoder(t)
 	default:
 		return g.genStructDecoder(t)
 	}
 }
 
-func (g *Generator) genSliceArrayDecoder(t reflect.Type) error {
+func (g *Generator) genSliceArrayDecoder(t goType) error {
 	switch t.Kind() {
 	case reflect.Slice, reflect.Array, reflect.Map:
 	default:
@@ -474,7 +702,7 @@ func (g *Generator) genSliceArrayDecoder(t reflect.Type) error {
 	return nil
 }
 
-func (g *Generator) genStructDecoder(t reflect.Type) error {
+func (g *Generator) genStructDecoder(t goType) error {
 	if t.Kind() != reflect.Struct {
 		return fmt.Errorf("cannot generate encoder/decoder for %v, not a struct type", t)
 	}
@@ -492,19 +720,16 @@ func (g *Generator) genStructDecoder(t reflect.Type) error {
 	fmt.Fprintln(g.out, "    return")
 	fmt.Fprintln(g.out, "  }")
 
-	// Init embedded pointer fields.
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		if !f.Anonymous || f.Type.Kind() != reflect.Ptr {
-			continue
-		}
-		fmt.Fprintln(g.out, "  out."+f.Name+" = new("+g.getType(f.Type.Elem())+")")
-	}
+	g.genInlinePtrsInit(t, "out", false)
 
 	fs, err := getStructFields(t)
 	if err != nil {
 		return fmt.Errorf("cannot generate decoder for %v: %v", t, err)
 	}
+	inlineMap, hasInlineMap, err := getInlineMapField(t)
+	if err != nil {
+		return fmt.Errorf("cannot generate decoder for %v: %v", t, err)
+	}
 
 	for _, f := range fs {
 		g.genRequiredFieldSet(t, f)
@@ -520,15 +745,50 @@ func (g *Generator) genStructDecoder(t reflect.Type) error {
 	fmt.Fprintln(g.out, "       continue")
 	fmt.Fprintln(g.out, "    }")
 
+	// Keys not matching exactly are matched case-insensitively in a separate
+	// switch, so that the exact matches are not slowed down. The projection is
+	// then checked against the names of the matched fields, not the keys.
+	fold := g.caseInsensitive || g.caseInsensitiveTypes[t]
+	if !fold {
+		fmt.Fprintln(g.out, "    if !in.Projected(key) {")
+		fmt.Fprintln(g.out, "      in.SkipRecursive()")
+		fmt.Fprintln(g.out, "      in.WantComma()")
+		fmt.Fprintln(g.out, "      continue")
+		fmt.Fprintln(g.out, "    }")
+	}
+
 	fmt.Fprintln(g.out, "    switch key {")
 	for _, f := range fs {
-		if err := g.genStructFieldDecoder(t, f); err != nil {
+		if err := g.genStructFieldDecoder(t, f, false, fold); err != nil {
 			return err
 		}
 	}
+	// Discriminators of the unions that t is a variant of are not unknown fields.
+	for _, key := range g.discriminatorKeys(t, fs) {
+		fmt.Fprintf(g.out, "    case %q:\n", key)
+		fmt.Fprintln(g.out, "      in.SkipRecursive()")
+	}
 
 	fmt.Fprintln(g.out, "    default:")
-	if g.disallowUnknownFields {
+
+	if fold {
+		g.imports["strings"] = "strings"
+
+		fmt.Fprintln(g.out, "    switch {")
+		for _, f := range fs {
+			if err := g.genStructFieldDecoder(t, f, true, true); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(g.out, "    default:")
+		g.genProjectionCheck("key")
+	}
+
+	if hasInlineMap {
+		if err := g.genInlineMapDecoder(t, inlineMap); err != nil {
+			return err
+		}
+	} else if g.disallowUnknownFields {
 		fmt.Fprintln(g.out, `      in.AddError(&jlexer.LexerError{
           Offset: in.GetPos(),
           Reason: "unknown field",
@@ -539,6 +799,9 @@ func (g *Generator) genStructDecoder(t reflect.Type) error {
 	} else {
 		fmt.Fprintln(g.out, "      in.SkipRecursive()")
 	}
+	if fold {
+		fmt.Fprintln(g.out, "    }")
+	}
 	fmt.Fprintln(g.out, "    }")
 	fmt.Fprintln(g.out, "    in.WantComma()")
 	fmt.Fprintln(g.out, "  }")
@@ -547,6 +810,12 @@ func (g *Generator) genStructDecoder(t reflect.Type) error {
 	fmt.Fprintln(g.out, "    in.Consumed()")
 	fmt.Fprintln(g.out, "  }")
 
+	for _, f := range fs {
+		if err := g.genDefaultValue(t, f); err != nil {
+			return fmt.Errorf("cannot generate decoder for %v: %v", t, err)
+		}
+	}
+
 	for _, f := range fs {
 		g.genRequiredFieldCheck(t, f)
 	}
@@ -556,11 +825,68 @@ func (g *Generator) genStructDecoder(t reflect.Type) error {
 	return nil
 }
 
-func (g *Generator) genStructUnmarshaler(t reflect.Type) error {
+// genInlinePtrsInit generates code allocating the embedded and inline pointer
+// fields of struct out of type t, including those nested in embedded and inline
+// struct fields, so that the fields they contain can be decoded. If nilOnly is
+// set, only the nil pointers are allocated.
+func (g *Generator) genInlinePtrsInit(t goType, out string, nilOnly bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tags := parseFieldTags(f.StructField)
+		if !f.Anonymous && !tags.inline || f.PkgPath != "" && t.PkgPath() != g.pkgPath {
+			continue
+		}
+
+		field, ft := out+"."+f.Name, f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+			if nilOnly {
+				fmt.Fprintln(g.out, "  if "+field+" == nil {")
+				fmt.Fprintln(g.out, "    "+field+" = new("+g.getType(ft)+")")
+				fmt.Fprintln(g.out, "  }")
+			} else {
+				fmt.Fprintln(g.out, "  "+field+" = new("+g.getType(ft)+")")
+			}
+		}
+
+		embedded := f.Anonymous && tags.name == ""
+		if ft.Kind() == reflect.Struct && (embedded || tags.inline && !tags.omit) {
+			g.genInlinePtrsInit(ft, field, nilOnly)
+		}
+	}
+}
+
+// genInlineMapDecoder generates code decoding the member with the name in key
+// into the inline map field f.
+func (g *Generator) genInlineMapDecoder(t goType, f structField) error {
+	out := "out." + f.selector()
+	tmpVar := g.uniqueVarName()
+
+	fmt.Fprintln(g.out, "      if "+out+" == nil {")
+	fmt.Fprintln(g.out, "        "+out+" = make("+g.getType(f.Type)+")")
+	fmt.Fprintln(g.out, "      }")
+	fmt.Fprintln(g.out, "      var "+tmpVar+" "+g.getType(f.Type.Elem()))
+	fmt.Fprintf(g.out, "      in.PushField(key, %q, %q)\n", goFieldName(t, f), f.Type.String())
+	if err := g.genTypeDecoder(f.Type.Elem(), tmpVar, parseFieldTags(f.StructField), 3); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, "      in.PopPath()")
+	// The key refers to the input buffer, so it is copied before being stored.
+	key := "string([]byte(key))"
+	if keyType := g.getType(f.Type.Key()); keyType != "string" {
+		key = keyType + "(" + key + ")"
+	}
+	fmt.Fprintln(g.out, "      "+out+"["+key+"] = "+tmpVar)
+	return nil
+}
+
+func (g *Generator) genStructUnmarshaler(t goType) error {
 	switch t.Kind() {
 	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
 	default:
-		return fmt.Errorf("cannot generate encoder/decoder for %v, not a struct/slice/array/map type", t)
+		if g.enums[t] == nil {
+			return fmt.Errorf("cannot generate encoder/decoder for %v, not a struct/slice/array/map type", t)
+		}
 	}
 
 	fname := g.getDecoderName(t)
@@ -582,3 +908,39 @@ func (g *Generator) genStructUnmarshaler(t reflect.Type) error {
 
 	return nil
 }
+
+// genGenericUnmarshaler generates the unmarshalers shared by the instantiations
+// of a generic type, see genGenericMarshaler.
+func (g *Generator) genGenericUnmarshaler(types []goType) error {
+	for _, t := range types {
+		if t.Kind() != reflect.Struct {
+			return fmt.Errorf("cannot generate encoder/decoder for %v, not a struct type", t)
+		}
+	}
+
+	g.imports["fmt"] = "fmt"
+	typ := genericReceiver(types[0])
+
+	if !g.noStdMarshalers {
+		fmt.Fprintln(g.out, "// UnmarshalJSON supports json.Unmarshaler interface")
+		fmt.Fprintln(g.out, "func (v *"+typ+") UnmarshalJSON(data []byte) error {")
+		fmt.Fprintln(g.out, "  r := jlexer.Lexer{Data: data}")
+		fmt.Fprintln(g.out, "  v.UnmarshalEasyJSON(&r)")
+		fmt.Fprintln(g.out, "  return r.Error()")
+		fmt.Fprintln(g.out, "}")
+	}
+
+	fmt.Fprintln(g.out, "// UnmarshalEasyJSON supports easyjson.Unmarshaler interface")
+	fmt.Fprintln(g.out, "func (v *"+typ+") UnmarshalEasyJSON(l *jlexer.Lexer) {")
+	fmt.Fprintln(g.out, "  switch v := interface{}(v).(type) {")
+	for _, t := range types {
+		fmt.Fprintln(g.out, "  case *"+g.getType(t)+":")
+		fmt.Fprintln(g.out, "    "+g.getDecoderName(t)+"(l, v)")
+	}
+	fmt.Fprintln(g.out, "  default:")
+	fmt.Fprintf(g.out, "    l.AddError(fmt.Errorf(%q, v))\n", "easyjson: no unmarshaler generated for %T")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "}")
+
+	return nil
+}
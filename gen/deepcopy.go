@@ -0,0 +1,491 @@
+package gen
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (g *Generator) getCopierName(t goType) string {
+	return g.functionName("copy", t)
+}
+
+func (g *Generator) getEqualName(t goType) string {
+	return g.functionName("equal", t)
+}
+
+// encodedFields returns the direct fields of struct t that are encoded: the
+// struct fields whose members are encoded as members of t, i.e. embedded and
+// inline struct fields, and the other fields.
+func (g *Generator) encodedFields(t goType) (structs, fields []structField) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tags := parseFieldTags(f.StructField)
+		if tags.omit || f.PkgPath != "" && f.PkgPath != g.pkgPath {
+			continue
+		}
+
+		embedded := f.Anonymous && tags.name == ""
+		t1 := f.Type
+		if t1.Kind() == reflect.Ptr {
+			t1 = t1.Elem()
+		}
+		switch {
+		case (embedded || tags.inline) && t1.Kind() == reflect.Struct:
+			structs = append(structs, f)
+		case f.PkgPath == "":
+			fields = append(fields, f)
+		}
+	}
+	return structs, fields
+}
+
+// needsDeepCopy reports whether copying a value of type t by assignment shares
+// memory between the copies.
+func (g *Generator) needsDeepCopy(t goType) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return true
+	case reflect.Interface:
+		// Non-empty interfaces cannot hold the maps and slices of decoded
+		// values.
+		return g.unions[t] != nil || t.NumMethod() == 0
+	case reflect.Array:
+		return g.needsDeepCopy(t.Elem())
+	case reflect.Struct:
+		if isType(t, reflect.TypeOf(time.Time{})) {
+			return false
+		}
+		if isBigNumber(t) {
+			return true
+		}
+		structs, fields := g.encodedFields(t)
+		for _, f := range structs {
+			if f.Type.Kind() == reflect.Ptr || g.needsDeepCopy(f.Type) {
+				return true
+			}
+		}
+		for _, f := range fields {
+			if g.needsDeepCopy(f.Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isComparable reports whether values of type t can be compared with ==, which
+// compares the same as comparing the encoded fields.
+func (g *Generator) isComparable(t goType) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface, reflect.Func:
+		return false
+	case reflect.Array:
+		return g.isComparable(t.Elem())
+	case reflect.Struct:
+		structs, fields := g.encodedFields(t)
+		if len(structs)+len(fields) != t.NumField() {
+			return false
+		}
+		for _, f := range structs {
+			if !g.isComparable(f.Type) {
+				return false
+			}
+		}
+		for _, f := range fields {
+			if !g.isComparable(f.Type) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// addressOf returns the expression of the address of v.
+func addressOf(v string) string {
+	if strings.HasPrefix(v, "*") {
+		return v[1:]
+	}
+	return "&" + v
+}
+
+// genTypeCopy generates code setting out to a deep copy of in of type t.
+func (g *Generator) genTypeCopy(t goType, in, out string, indent int) {
+	ws := strings.Repeat("  ", indent)
+
+	if !g.needsDeepCopy(t) {
+		fmt.Fprintln(g.out, ws+out+" = "+in)
+		return
+	}
+
+	// The numbers are copied into new values, so that they do not share the
+	// backing arrays of their digits.
+	if isType(t, reflect.TypeOf(big.Int{})) {
+		fmt.Fprintln(g.out, ws+out+" = *new("+g.getType(t)+").Set("+addressOf(in)+")")
+		return
+	}
+	if isType(t, reflect.TypeOf(big.Float{})) {
+		fmt.Fprintln(g.out, ws+out+" = *new("+g.getType(t)+").Copy("+addressOf(in)+")")
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Interface:
+		if t.Kind() == reflect.Interface && g.unions[t] == nil {
+			fmt.Fprintln(g.out, ws+out+" = easyjson.DeepCopyInterface("+in+")")
+			return
+		}
+		fn := g.getCopierName(t)
+		g.addType(t)
+
+		fmt.Fprintln(g.out, ws+fn+"("+addressOf(in)+", "+addressOf(out)+")")
+
+	case reflect.Ptr:
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = new("+g.getType(t.Elem())+")")
+		g.genTypeCopy(t.Elem(), "*"+in, "*"+out, indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Slice:
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = make("+g.getType(t)+", len("+in+"))")
+		if g.needsDeepCopy(t.Elem()) {
+			iVar := g.uniqueVarName()
+			fmt.Fprintln(g.out, ws+"  for "+iVar+" := range "+in+" {")
+			g.genTypeCopy(t.Elem(), "("+in+")["+iVar+"]", "("+out+")["+iVar+"]", indent+2)
+			fmt.Fprintln(g.out, ws+"  }")
+		} else {
+			fmt.Fprintln(g.out, ws+"  copy("+out+", "+in+")")
+		}
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Array:
+		iVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"for "+iVar+" := range "+in+" {")
+		g.genTypeCopy(t.Elem(), "("+in+")["+iVar+"]", "("+out+")["+iVar+"]", indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Map:
+		tmpVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = make("+g.getType(t)+", len("+in+"))")
+		fmt.Fprintln(g.out, ws+"  for "+tmpVar+"Key, "+tmpVar+"Value := range "+in+" {")
+		if g.needsDeepCopy(t.Elem()) {
+			fmt.Fprintln(g.out, ws+"    var "+tmpVar+" "+g.getType(t.Elem()))
+			g.genTypeCopy(t.Elem(), tmpVar+"Value", tmpVar, indent+2)
+			fmt.Fprintln(g.out, ws+"    ("+out+")["+tmpVar+"Key] = "+tmpVar)
+		} else {
+			fmt.Fprintln(g.out, ws+"    ("+out+")["+tmpVar+"Key] = "+tmpVar+"Value")
+		}
+		fmt.Fprintln(g.out, ws+"  }")
+		fmt.Fprintln(g.out, ws+"}")
+	}
+}
+
+// genStructFieldsCopy generates code deep-copying the encoded fields of struct
+// in of type t into out, which holds a copy of in made by assignment.
+func (g *Generator) genStructFieldsCopy(t goType, in, out string, indent int) {
+	ws := strings.Repeat("  ", indent)
+
+	structs, fields := g.encodedFields(t)
+	for _, f := range fields {
+		if g.needsDeepCopy(f.Type) {
+			g.genTypeCopy(f.Type, in+"."+f.Name, out+"."+f.Name, indent)
+		}
+	}
+	for _, f := range structs {
+		if f.Type.Kind() != reflect.Ptr {
+			g.genStructFieldsCopy(f.Type, in+"."+f.Name, out+"."+f.Name, indent)
+			continue
+		}
+
+		fmt.Fprintln(g.out, ws+"if "+in+"."+f.Name+" != nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+"."+f.Name+" = new("+g.getType(f.Type.Elem())+")")
+		fmt.Fprintln(g.out, ws+"  *"+out+"."+f.Name+" = *"+in+"."+f.Name)
+		g.genStructFieldsCopy(f.Type.Elem(), in+"."+f.Name, out+"."+f.Name, indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+	}
+}
+
+// genTypeEqual generates code returning false if a and b of type t are not
+// deeply equal.
+func (g *Generator) genTypeEqual(t goType, a, b string, indent int) {
+	ws := strings.Repeat("  ", indent)
+
+	if isType(t, reflect.TypeOf(time.Time{})) {
+		fmt.Fprintln(g.out, ws+"if !("+a+").Equal("+b+") {")
+		fmt.Fprintln(g.out, ws+"  return false")
+		fmt.Fprintln(g.out, ws+"}")
+		return
+	}
+	if isBigNumber(t) {
+		fmt.Fprintln(g.out, ws+"if ("+a+").Cmp("+addressOf(b)+") != 0 {")
+		fmt.Fprintln(g.out, ws+"  return false")
+		fmt.Fprintln(g.out, ws+"}")
+		return
+	}
+	if g.isComparable(t) {
+		fmt.Fprintln(g.out, ws+"if "+a+" != "+b+" {")
+		fmt.Fprintln(g.out, ws+"  return false")
+		fmt.Fprintln(g.out, ws+"}")
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fn := g.getEqualName(t)
+		g.addType(t)
+
+		fmt.Fprintln(g.out, ws+"if !"+fn+"("+addressOf(a)+", "+addressOf(b)+") {")
+		fmt.Fprintln(g.out, ws+"  return false")
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Interface:
+		if g.unions[t] != nil {
+			fn := g.getEqualName(t)
+			g.addType(t)
+
+			fmt.Fprintln(g.out, ws+"if !"+fn+"("+addressOf(a)+", "+addressOf(b)+") {")
+		} else {
+			g.imports["reflect"] = "reflect"
+			fmt.Fprintln(g.out, ws+"if !reflect.DeepEqual("+a+", "+b+") {")
+		}
+		fmt.Fprintln(g.out, ws+"  return false")
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Ptr:
+		fmt.Fprintln(g.out, ws+"if ("+a+" == nil) != ("+b+" == nil) {")
+		fmt.Fprintln(g.out, ws+"  return false")
+		fmt.Fprintln(g.out, ws+"}")
+		fmt.Fprintln(g.out, ws+"if "+a+" != "+b+" {")
+		g.genTypeEqual(t.Elem(), "*"+a, "*"+b, indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Slice:
+		iVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"if ("+a+" == nil) != ("+b+" == nil) || len("+a+") != len("+b+") {")
+		fmt.Fprintln(g.out, ws+"  return false")
+		fmt.Fprintln(g.out, ws+"}")
+		fmt.Fprintln(g.out, ws+"for "+iVar+" := range "+a+" {")
+		g.genTypeEqual(t.Elem(), "("+a+")["+iVar+"]", "("+b+")["+iVar+"]", indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Array:
+		iVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"for "+iVar+" := range "+a+" {")
+		g.genTypeEqual(t.Elem(), "("+a+")["+iVar+"]", "("+b+")["+iVar+"]", indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Map:
+		tmpVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"if ("+a+" == nil) != ("+b+" == nil) || len("+a+") != len("+b+") {")
+		fmt.Fprintln(g.out, ws+"  return false")
+		fmt.Fprintln(g.out, ws+"}")
+		fmt.Fprintln(g.out, ws+"for "+tmpVar+"Key, "+tmpVar+"A := range "+a+" {")
+		fmt.Fprintln(g.out, ws+"  "+tmpVar+"B, ok := ("+b+")["+tmpVar+"Key]")
+		fmt.Fprintln(g.out, ws+"  if !ok {")
+		fmt.Fprintln(g.out, ws+"    return false")
+		fmt.Fprintln(g.out, ws+"  }")
+		g.genTypeEqual(t.Elem(), tmpVar+"A", tmpVar+"B", indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Func:
+		// Like reflect.DeepEqual, funcs are equal only if both are nil.
+		fmt.Fprintln(g.out, ws+"if "+a+" != nil || "+b+" != nil {")
+		fmt.Fprintln(g.out, ws+"  return false")
+		fmt.Fprintln(g.out, ws+"}")
+	}
+}
+
+// genStructFieldsEqual generates code returning false if the encoded fields of
+// structs a and b of type t are not deeply equal.
+func (g *Generator) genStructFieldsEqual(t goType, a, b string, indent int) {
+	ws := strings.Repeat("  ", indent)
+
+	structs, fields := g.encodedFields(t)
+	for _, f := range fields {
+		g.genTypeEqual(f.Type, a+"."+f.Name, b+"."+f.Name, indent)
+	}
+	for _, f := range structs {
+		if f.Type.Kind() != reflect.Ptr {
+			g.genStructFieldsEqual(f.Type, a+"."+f.Name, b+"."+f.Name, indent)
+			continue
+		}
+
+		fmt.Fprintln(g.out, ws+"if ("+a+"."+f.Name+" == nil) != ("+b+"."+f.Name+" == nil) {")
+		fmt.Fprintln(g.out, ws+"  return false")
+		fmt.Fprintln(g.out, ws+"}")
+		fmt.Fprintln(g.out, ws+"if "+a+"."+f.Name+" != nil {")
+		g.genStructFieldsEqual(f.Type.Elem(), a+"."+f.Name, b+"."+f.Name, indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+	}
+}
+
+// genCopier generates the func deep-copying values of type t.
+func (g *Generator) genCopier(t goType) error {
+	fname := g.getCopierName(t)
+	typ := g.getType(t)
+
+	fmt.Fprintln(g.out, "func "+fname+"(in, out *"+typ+") {")
+	switch t.Kind() {
+	case reflect.Struct:
+		fmt.Fprintln(g.out, "  *out = *in")
+		g.genStructFieldsCopy(t, "in", "out", 1)
+
+	case reflect.Interface:
+		u := g.unions[t]
+		if u == nil {
+			return fmt.Errorf("cannot generate copier for %v, not a union type", t)
+		}
+		fmt.Fprintln(g.out, "  switch in := (*in).(type) {")
+		for _, v := range u.variants {
+			if !g.needsDeepCopy(v.t) {
+				continue
+			}
+			tmpVar := g.uniqueVarName()
+			fmt.Fprintln(g.out, "  case "+g.getType(v.t)+":")
+			fmt.Fprintln(g.out, "    var "+tmpVar+" "+g.getType(v.t))
+			g.genTypeCopy(v.t, "in", tmpVar, 2)
+			fmt.Fprintln(g.out, "    *out = "+tmpVar)
+		}
+		fmt.Fprintln(g.out, "  default:")
+		fmt.Fprintln(g.out, "    *out = in")
+		fmt.Fprintln(g.out, "  }")
+
+	default:
+		g.genTypeCopy(t, "*in", "*out", 1)
+	}
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+// genEqual generates the func reporting whether values of type t are deeply
+// equal.
+func (g *Generator) genEqual(t goType) error {
+	fname := g.getEqualName(t)
+	typ := g.getType(t)
+
+	fmt.Fprintln(g.out, "func "+fname+"(a, b *"+typ+") bool {")
+	switch t.Kind() {
+	case reflect.Struct:
+		g.genStructFieldsEqual(t, "a", "b", 1)
+
+	case reflect.Interface:
+		u := g.unions[t]
+		if u == nil {
+			return fmt.Errorf("cannot generate equality func for %v, not a union type", t)
+		}
+		g.imports["reflect"] = "reflect"
+
+		fmt.Fprintln(g.out, "  switch a := (*a).(type) {")
+		for _, v := range u.variants {
+			fmt.Fprintln(g.out, "  case "+g.getType(v.t)+":")
+			fmt.Fprintln(g.out, "    b, ok := (*b).("+g.getType(v.t)+")")
+			fmt.Fprintln(g.out, "    if !ok {")
+			fmt.Fprintln(g.out, "      return false")
+			fmt.Fprintln(g.out, "    }")
+			g.genTypeEqual(v.t, "a", "b", 2)
+		}
+		fmt.Fprintln(g.out, "  default:")
+		fmt.Fprintln(g.out, "    return reflect.DeepEqual(a, *b)")
+		fmt.Fprintln(g.out, "  }")
+
+	default:
+		g.genTypeEqual(t, "*a", "*b", 1)
+	}
+	fmt.Fprintln(g.out, "  return true")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+// genDeepCopyEqual generates the DeepCopy and Equal methods of type t.
+func (g *Generator) genDeepCopyEqual(t goType) error {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+	default:
+		return fmt.Errorf("cannot generate DeepCopy/Equal for %v, not a struct/slice/array/map type", t)
+	}
+
+	typ := g.getType(t)
+
+	fmt.Fprintln(g.out, "// DeepCopy returns a deep copy of the value")
+	fmt.Fprintln(g.out, "func (v "+typ+") DeepCopy() "+typ+" {")
+	fmt.Fprintln(g.out, "  var out "+typ)
+	fmt.Fprintln(g.out, "  "+g.getCopierName(t)+"(&v, &out)")
+	fmt.Fprintln(g.out, "  return out")
+	fmt.Fprintln(g.out, "}")
+
+	fmt.Fprintln(g.out, "// Equal reports whether the value is deeply equal to other")
+	fmt.Fprintln(g.out, "func (v "+typ+") Equal(other "+typ+") bool {")
+	fmt.Fprintln(g.out, "  return "+g.getEqualName(t)+"(&v, &other)")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+// genGenericDeepCopyEqual generates the DeepCopy and Equal methods shared by
+// the instantiations of a generic type, see genGenericMarshaler. The other
+// instantiations, which may be used by other packages, are copied with
+// easyjson.DeepCopyValue and compared with reflect.DeepEqual.
+func (g *Generator) genGenericDeepCopyEqual(types []goType) error {
+	for _, t := range types {
+		if t.Kind() != reflect.Struct {
+			return fmt.Errorf("cannot generate DeepCopy/Equal for %v, not a struct type", t)
+		}
+	}
+
+	g.imports["reflect"] = "reflect"
+	typ := genericReceiverParams(types[0])
+
+	fmt.Fprintln(g.out, "// DeepCopy returns a deep copy of the value, made with reflection for the")
+	fmt.Fprintln(g.out, "// instantiations that no DeepCopy is generated for")
+	fmt.Fprintln(g.out, "func (v "+typ+") DeepCopy() "+typ+" {")
+	fmt.Fprintln(g.out, "  switch v := interface{}(v).(type) {")
+	for _, t := range types {
+		fmt.Fprintln(g.out, "  case "+g.getType(t)+":")
+		fmt.Fprintln(g.out, "    var out "+g.getType(t))
+		fmt.Fprintln(g.out, "    "+g.getCopierName(t)+"(&v, &out)")
+		fmt.Fprintln(g.out, "    return interface{}(out).("+typ+")")
+	}
+	fmt.Fprintln(g.out, "  default:")
+	fmt.Fprintln(g.out, "    return easyjson.DeepCopyValue(v).("+typ+")")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "}")
+
+	fmt.Fprintln(g.out, "// Equal reports whether the value is deeply equal to other, as reported by")
+	fmt.Fprintln(g.out, "// reflect.DeepEqual for the instantiations that no Equal is generated for")
+	fmt.Fprintln(g.out, "func (v "+typ+") Equal(other "+typ+") bool {")
+	fmt.Fprintln(g.out, "  switch v := interface{}(v).(type) {")
+	for _, t := range types {
+		fmt.Fprintln(g.out, "  case "+g.getType(t)+":")
+		fmt.Fprintln(g.out, "    other := interface{}(other).("+g.getType(t)+")")
+		fmt.Fprintln(g.out, "    return "+g.getEqualName(t)+"(&v, &other)")
+	}
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "  return reflect.DeepEqual(v, other)")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+// genericReceiverParams returns the receiver type for methods of a generic
+// type with named type parameters, e.g. 'Pair[P0, P1]' for 'Pair[string, int]',
+// for methods referring to the receiver type in the signature.
+func genericReceiverParams(t goType) string {
+	recv := genericReceiver(t)
+	i := strings.IndexByte(recv, '[')
+
+	n := strings.Count(recv[i:], "_")
+	params := make([]string, 0, n)
+	for j := 0; j < n; j++ {
+		params = append(params, "P"+strconv.Itoa(j))
+	}
+	return recv[:i] + "[" + strings.Join(params, ", ") + "]"
+}
@@ -0,0 +1,324 @@
+package gen
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mailru/easyjson"
+)
+
+func (g *Generator) getDifferName(t goType) string {
+	return g.functionName("diff", t)
+}
+
+// encodedByGenerator reports whether values of type t are encoded by generated
+// code rather than by custom marshalers.
+func (g *Generator) encodedByGenerator(t goType) bool {
+	return g.marshalers[t] || !hasCustomMarshaler(t)
+}
+
+// diffsFields reports whether values of type t are diffed field by field: t is
+// a struct type that is encoded by generated code.
+func (g *Generator) diffsFields(t goType) bool {
+	return t.Kind() == reflect.Struct && g.encodedByGenerator(t)
+}
+
+// diffsMembers reports whether maps of type t are diffed entry by entry: the
+// keys are strings that are encoded as they are.
+func diffsMembers(t goType) bool {
+	key := t.Key()
+	return key.Kind() == reflect.String &&
+		!key.PtrTo().Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem())
+}
+
+// memberPath returns the expression of the JSON pointer to the member name of
+// the value at path.
+func memberPath(path, name string) string {
+	return path + "+" + strconv.Quote("/"+easyjson.EscapePointerToken(name))
+}
+
+// genPatchOp generates code appending the operation op at path to the patch
+// p. The value v of type t is encoded as the value of the operation unless v
+// is empty.
+func (g *Generator) genPatchOp(op, path string, t goType, v string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+	if v == "" {
+		fmt.Fprintf(g.out, ws+"p = append(p, easyjson.PatchOp{Op: %q, Path: %s})\n", op, path)
+		return nil
+	}
+
+	fmt.Fprintln(g.out, ws+"{")
+	fmt.Fprintln(g.out, ws+"  out := &jwriter.Writer{}")
+	if err := g.genTypeEncoder(t, v, tags, indent+1, false); err != nil {
+		return err
+	}
+	fmt.Fprintf(g.out, ws+"  p = append(p, easyjson.PatchOp{Op: %q, Path: %s, Value: out.Buffer.BuildBytes()})\n", op, path)
+	fmt.Fprintln(g.out, ws+"}")
+	return nil
+}
+
+// genTypeDiff generates code appending the operations transforming the JSON
+// of a into the JSON of b of type t, at the JSON pointer path, to the patch p.
+func (g *Generator) genTypeDiff(t goType, a, b, path string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+
+	switch {
+	case g.diffsFields(t):
+		fn := g.getDifferName(t)
+		g.addType(t)
+
+		fmt.Fprintln(g.out, ws+"p = "+fn+"(p, "+path+", "+addressOf(a)+", "+addressOf(b)+")")
+
+	case t.Kind() == reflect.Ptr && g.encodedByGenerator(t):
+		fmt.Fprintln(g.out, ws+"if "+a+" == nil || "+b+" == nil {")
+		fmt.Fprintln(g.out, ws+"  if "+a+" != "+b+" {")
+		if err := g.genPatchOp("replace", path, t, b, tags, indent+2); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"  }")
+		fmt.Fprintln(g.out, ws+"} else {")
+		if err := g.genTypeDiff(t.Elem(), "*"+a, "*"+b, path, tags, indent+1); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"}")
+
+	case t.Kind() == reflect.Slice && g.encodedByGenerator(t) && !(t.Elem().Kind() == reflect.Uint8 && t.Elem().Name() == "uint8"):
+		g.imports["strconv"] = "strconv"
+		iVar := g.uniqueVarName()
+		elemPath := path + "+\"/\"+strconv.Itoa(" + iVar + ")"
+
+		fmt.Fprintln(g.out, ws+"if ("+a+" == nil) != ("+b+" == nil) {")
+		if err := g.genPatchOp("replace", path, t, b, tags, indent+1); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprintln(g.out, ws+"  for "+iVar+" := 0; "+iVar+" < len("+a+") && "+iVar+" < len("+b+"); "+iVar+"++ {")
+		if err := g.genTypeDiff(t.Elem(), "("+a+")["+iVar+"]", "("+b+")["+iVar+"]", elemPath, tags, indent+2); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"  }")
+		// Extra elements are removed from the end, so that the indices of the
+		// other elements do not change.
+		fmt.Fprintln(g.out, ws+"  for "+iVar+" := len("+a+") - 1; "+iVar+" >= len("+b+"); "+iVar+"-- {")
+		if err := g.genPatchOp("remove", elemPath, nil, "", tags, indent+2); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"  }")
+		fmt.Fprintln(g.out, ws+"  for "+iVar+" := len("+a+"); "+iVar+" < len("+b+"); "+iVar+"++ {")
+		if err := g.genPatchOp("add", elemPath, t.Elem(), "("+b+")["+iVar+"]", tags, indent+2); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"  }")
+		fmt.Fprintln(g.out, ws+"}")
+
+	case t.Kind() == reflect.Array && g.encodedByGenerator(t):
+		g.imports["strconv"] = "strconv"
+		iVar := g.uniqueVarName()
+
+		fmt.Fprintln(g.out, ws+"for "+iVar+" := range "+a+" {")
+		if err := g.genTypeDiff(t.Elem(), "("+a+")["+iVar+"]", "("+b+")["+iVar+"]", path+"+\"/\"+strconv.Itoa("+iVar+")", tags, indent+1); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"}")
+
+	case t.Kind() == reflect.Map && g.encodedByGenerator(t) && diffsMembers(t):
+		fmt.Fprintln(g.out, ws+"if ("+a+" == nil) != ("+b+" == nil) {")
+		if err := g.genPatchOp("replace", path, t, b, tags, indent+1); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"} else {")
+		if err := g.genMapDiff(t, a, b, path, tags, indent+1); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"}")
+
+	default:
+		if g.isComparable(t) && g.encodedByGenerator(t) {
+			fmt.Fprintln(g.out, ws+"if "+a+" != "+b+" {")
+			if err := g.genPatchOp("replace", path, t, b, tags, indent+1); err != nil {
+				return err
+			}
+			fmt.Fprintln(g.out, ws+"}")
+			return nil
+		}
+
+		// Other values are compared by their JSON.
+		g.imports["bytes"] = "bytes"
+		tmpVar := g.uniqueVarName()
+
+		fmt.Fprintln(g.out, ws+"{")
+		fmt.Fprintln(g.out, ws+"  out := &jwriter.Writer{}")
+		if err := g.genTypeEncoder(t, a, tags, indent+1, false); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"  "+tmpVar+" := out.Buffer.BuildBytes()")
+		fmt.Fprintln(g.out, ws+"  out = &jwriter.Writer{}")
+		if err := g.genTypeEncoder(t, b, tags, indent+1, false); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"  if value := out.Buffer.BuildBytes(); !bytes.Equal("+tmpVar+", value) {")
+		fmt.Fprintf(g.out, ws+"    p = append(p, easyjson.PatchOp{Op: %q, Path: %s, Value: value})\n", "replace", path)
+		fmt.Fprintln(g.out, ws+"  }")
+		fmt.Fprintln(g.out, ws+"}")
+	}
+	return nil
+}
+
+// genMapDiff generates code appending the operations transforming the entries
+// of map a into the entries of map b of type t, members of the object at path,
+// to the patch p. The entries are diffed in sorted key order.
+func (g *Generator) genMapDiff(t goType, a, b, path string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+	g.imports["sort"] = "sort"
+
+	tmpVar := g.uniqueVarName()
+	keys := tmpVar + "Keys"
+	keyPath := path + "+\"/\"+easyjson.EscapePointerToken(string(" + tmpVar + "Name))"
+
+	fmt.Fprintln(g.out, ws+keys+" := make([]"+g.getType(t.Key())+", 0, len("+b+"))")
+	fmt.Fprintln(g.out, ws+"for "+tmpVar+"Name := range "+a+" {")
+	fmt.Fprintln(g.out, ws+"  "+keys+" = append("+keys+", "+tmpVar+"Name)")
+	fmt.Fprintln(g.out, ws+"}")
+	fmt.Fprintln(g.out, ws+"for "+tmpVar+"Name := range "+b+" {")
+	fmt.Fprintln(g.out, ws+"  if _, ok := ("+a+")["+tmpVar+"Name]; !ok {")
+	fmt.Fprintln(g.out, ws+"    "+keys+" = append("+keys+", "+tmpVar+"Name)")
+	fmt.Fprintln(g.out, ws+"  }")
+	fmt.Fprintln(g.out, ws+"}")
+	fmt.Fprintln(g.out, ws+"sort.Slice("+keys+", func(i, j int) bool { return "+keys+"[i] < "+keys+"[j] })")
+	fmt.Fprintln(g.out, ws+"for _, "+tmpVar+"Name := range "+keys+" {")
+	fmt.Fprintln(g.out, ws+"  "+tmpVar+"A, okA := ("+a+")["+tmpVar+"Name]")
+	fmt.Fprintln(g.out, ws+"  "+tmpVar+"B, okB := ("+b+")["+tmpVar+"Name]")
+	fmt.Fprintln(g.out, ws+"  switch {")
+	fmt.Fprintln(g.out, ws+"  case !okB:")
+	if err := g.genPatchOp("remove", keyPath, nil, "", tags, indent+2); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, ws+"  case !okA:")
+	if err := g.genPatchOp("add", keyPath, t.Elem(), tmpVar+"B", tags, indent+2); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, ws+"  default:")
+	if err := g.genTypeDiff(t.Elem(), tmpVar+"A", tmpVar+"B", keyPath, tags, indent+2); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, ws+"  }")
+	fmt.Fprintln(g.out, ws+"}")
+	return nil
+}
+
+// genStructFieldDiff generates code appending the operations transforming the
+// member of field f of struct a into the member of struct b of type t to the
+// patch p. Members left out by omitempty are added or removed.
+func (g *Generator) genStructFieldDiff(t goType, f structField) error {
+	tags := parseFieldTags(f.StructField)
+	if tags.omit {
+		return nil
+	}
+
+	a, b := "a."+f.selector(), "b."+f.selector()
+	path := memberPath("path", g.jsonFieldName(t, f))
+
+	noOmitEmpty := (!tags.omitEmpty && !g.omitEmpty) || tags.noOmitEmpty
+	if noOmitEmpty {
+		return g.genTypeDiff(f.Type, a, b, path, tags, 1)
+	}
+
+	fmt.Fprintln(g.out, "  switch aSet, bSet := "+g.notEmptyCheck(f.Type, a)+", "+g.notEmptyCheck(f.Type, b)+"; {")
+	fmt.Fprintln(g.out, "  case aSet && bSet:")
+	if err := g.genTypeDiff(f.Type, a, b, path, tags, 2); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, "  case aSet:")
+	if err := g.genPatchOp("remove", path, nil, "", tags, 2); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, "  case bSet:")
+	if err := g.genPatchOp("add", path, f.Type, b, tags, 2); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, "  }")
+	return nil
+}
+
+// genDiffer generates the func appending the operations of the JSON patch
+// (RFC 6902) transforming the JSON of a value of type t into the JSON of
+// another value to a patch.
+func (g *Generator) genDiffer(t goType) error {
+	fname := g.getDifferName(t)
+	typ := g.getType(t)
+
+	fmt.Fprintln(g.out, "func "+fname+"(p easyjson.Patch, path string, a, b *"+typ+") easyjson.Patch {")
+	if t.Kind() != reflect.Struct || !g.diffsFields(t) {
+		if err := g.genTypeDiff(t, "*a", "*b", "path", fieldTags{}, 1); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, "  return p")
+		fmt.Fprintln(g.out, "}")
+		return nil
+	}
+
+	fs, err := getStructFields(t)
+	if err != nil {
+		return fmt.Errorf("cannot generate diff func for %v: %v", t, err)
+	}
+	for _, f := range fs {
+		if err := g.genStructFieldDiff(t, f); err != nil {
+			return err
+		}
+	}
+
+	inlineMap, hasInlineMap, err := getInlineMapField(t)
+	if err != nil {
+		return fmt.Errorf("cannot generate diff func for %v: %v", t, err)
+	}
+	if hasInlineMap {
+		// The entries of inline maps are members of the struct object.
+		if err := g.genMapDiff(inlineMap.Type, "a."+inlineMap.selector(), "b."+inlineMap.selector(), "path", parseFieldTags(inlineMap.StructField), 1); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(g.out, "  return p")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+// genDiffJSON generates the DiffJSON method of type t.
+func (g *Generator) genDiffJSON(t goType) error {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+	default:
+		return fmt.Errorf("cannot generate DiffJSON for %v, not a struct/slice/array/map type", t)
+	}
+
+	typ := g.getType(t)
+
+	fmt.Fprintln(g.out, "// DiffJSON returns the JSON patch transforming the JSON of the value into the JSON of other")
+	fmt.Fprintln(g.out, "func (v "+typ+") DiffJSON(other "+typ+") (easyjson.Patch, error) {")
+	fmt.Fprintln(g.out, "  return "+g.getDifferName(t)+"(nil, \"\", &v, &other), nil")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+// genGenericDiffJSON generates the DiffJSON method shared by the
+// instantiations of a generic type, see genGenericMarshaler. It returns an
+// error for the other instantiations, which may be used by other packages.
+func (g *Generator) genGenericDiffJSON(types []goType) error {
+	g.imports["fmt"] = "fmt"
+	typ := genericReceiverParams(types[0])
+
+	fmt.Fprintln(g.out, "// DiffJSON returns the JSON patch transforming the JSON of the value into the JSON of other")
+	fmt.Fprintln(g.out, "func (v "+typ+") DiffJSON(other "+typ+") (easyjson.Patch, error) {")
+	fmt.Fprintln(g.out, "  switch v := interface{}(v).(type) {")
+	for _, t := range types {
+		fmt.Fprintln(g.out, "  case "+g.getType(t)+":")
+		fmt.Fprintln(g.out, "    other := interface{}(other).("+g.getType(t)+")")
+		fmt.Fprintln(g.out, "    return "+g.getDifferName(t)+"(nil, \"\", &v, &other), nil")
+	}
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintf(g.out, "  return nil, fmt.Errorf(%q, v)\n", "easyjson: no DiffJSON generated for %T")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/mailru/easyjson"
 )
 
-func (g *Generator) getEncoderName(t reflect.Type) string {
+func (g *Generator) getEncoderName(t goType) string {
 	return g.functionName("encode", t)
 }
 
@@ -60,6 +60,12 @@ type fieldTags struct {
 	required    bool
 	intern      bool
 	noCopy      bool
+	inline      bool
+
+	format string // Value of the `format=` option for time.Time and time.Duration.
+
+	defaultValue string // Value of the `default` tag.
+	hasDefault   bool
 }
 
 // parseFieldTags parses the json field tag into a structure.
@@ -84,30 +90,42 @@ func parseFieldTags(f reflect.StructField) fieldTags {
 			ret.intern = true
 		case s == "nocopy":
 			ret.noCopy = true
+		case s == "inline":
+			ret.inline = true
+		case strings.HasPrefix(s, "format="):
+			ret.format = strings.TrimPrefix(s, "format=")
 		}
 	}
+	ret.defaultValue, ret.hasDefault = f.Tag.Lookup("default")
 
 	return ret
 }
 
 // genTypeEncoder generates code that encodes in of type t into the writer, but uses marshaler interface if implemented by t.
-func (g *Generator) genTypeEncoder(t reflect.Type, in string, tags fieldTags, indent int, assumeNonEmpty bool) error {
+func (g *Generator) genTypeEncoder(t goType, in string, tags fieldTags, indent int, assumeNonEmpty bool) error {
 	ws := strings.Repeat("  ", indent)
 
+	if isTimeType(t, tags) {
+		return g.genTimeEncoder(t, in, tags, indent)
+	}
+	if isBigNumberType(t) {
+		return g.genBigNumberEncoder(t, in, tags, indent)
+	}
+
 	marshalerIface := reflect.TypeOf((*easyjson.Marshaler)(nil)).Elem()
-	if reflect.PtrTo(t).Implements(marshalerIface) {
+	if t.PtrTo().Implements(marshalerIface) {
 		fmt.Fprintln(g.out, ws+"("+in+").MarshalEasyJSON(out)")
 		return nil
 	}
 
 	marshalerIface = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
-	if reflect.PtrTo(t).Implements(marshalerIface) {
+	if t.PtrTo().Implements(marshalerIface) {
 		fmt.Fprintln(g.out, ws+"out.Raw( ("+in+").MarshalJSON() )")
 		return nil
 	}
 
 	marshalerIface = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
-	if reflect.PtrTo(t).Implements(marshalerIface) {
+	if t.PtrTo().Implements(marshalerIface) {
 		fmt.Fprintln(g.out, ws+"out.RawText( ("+in+").MarshalText() )")
 		return nil
 	}
@@ -117,15 +135,15 @@ func (g *Generator) genTypeEncoder(t reflect.Type, in string, tags fieldTags, in
 }
 
 // returns true if the type t implements one of the custom marshaler interfaces
-func hasCustomMarshaler(t reflect.Type) bool {
-	t = reflect.PtrTo(t)
+func hasCustomMarshaler(t goType) bool {
+	t = t.PtrTo()
 	return t.Implements(reflect.TypeOf((*easyjson.Marshaler)(nil)).Elem()) ||
 		t.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) ||
 		t.Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem())
 }
 
 // genTypeEncoderNoCheck generates code that encodes in of type t into the writer.
-func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldTags, indent int, assumeNonEmpty bool) error {
+func (g *Generator) genTypeEncoderNoCheck(t goType, in string, tags fieldTags, indent int, assumeNonEmpty bool) error {
 	ws := strings.Repeat("  ", indent)
 
 	// Check whether type is primitive, needs to be done after interface check.
@@ -159,18 +177,19 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 			} else {
 				fmt.Fprintln(g.out, ws+"{")
 			}
-			fmt.Fprintln(g.out, ws+"  out.RawByte('[')")
+			fmt.Fprintln(g.out, ws+"  out.ArrayStart()")
 			fmt.Fprintln(g.out, ws+"  for "+iVar+", "+vVar+" := range "+in+" {")
 			fmt.Fprintln(g.out, ws+"    if "+iVar+" > 0 {")
 			fmt.Fprintln(g.out, ws+"      out.RawByte(',')")
 			fmt.Fprintln(g.out, ws+"    }")
+			fmt.Fprintln(g.out, ws+"    out.ElemStart()")
 
 			if err := g.genTypeEncoder(elem, vVar, tags, indent+2, false); err != nil {
 				return err
 			}
 
 			fmt.Fprintln(g.out, ws+"  }")
-			fmt.Fprintln(g.out, ws+"  out.RawByte(']')")
+			fmt.Fprintln(g.out, ws+"  out.ArrayEnd()")
 			fmt.Fprintln(g.out, ws+"}")
 		}
 
@@ -185,18 +204,19 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 				fmt.Fprintln(g.out, ws+"out.Base64Bytes("+in+"[:])")
 			}
 		} else {
-			fmt.Fprintln(g.out, ws+"out.RawByte('[')")
+			fmt.Fprintln(g.out, ws+"out.ArrayStart()")
 			fmt.Fprintln(g.out, ws+"for "+iVar+" := range "+in+" {")
 			fmt.Fprintln(g.out, ws+"  if "+iVar+" > 0 {")
 			fmt.Fprintln(g.out, ws+"    out.RawByte(',')")
 			fmt.Fprintln(g.out, ws+"  }")
+			fmt.Fprintln(g.out, ws+"  out.ElemStart()")
 
 			if err := g.genTypeEncoder(elem, "("+in+")["+iVar+"]", tags, indent+1, false); err != nil {
 				return err
 			}
 
 			fmt.Fprintln(g.out, ws+"}")
-			fmt.Fprintln(g.out, ws+"out.RawByte(']')")
+			fmt.Fprintln(g.out, ws+"out.ArrayEnd()")
 		}
 
 	case reflect.Struct:
@@ -235,13 +255,22 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 		} else {
 			fmt.Fprintln(g.out, ws+"{")
 		}
-		fmt.Fprintln(g.out, ws+"  out.RawByte('{')")
+		fmt.Fprintln(g.out, ws+"  out.ObjectStart()")
 		fmt.Fprintln(g.out, ws+"  "+tmpVar+"First := true")
-		fmt.Fprintln(g.out, ws+"  for "+tmpVar+"Name, "+tmpVar+"Value := range "+in+" {")
+		g.genMapRange(t, in, tmpVar, ws+"  ")
+
+		// Only string keys are checked against the mask to avoid key conversions.
+		maskKey := g.fieldMask && key.Kind() == reflect.String
+		if maskKey {
+			fmt.Fprintln(g.out, ws+"    if !out.EnterField(string("+tmpVar+"Name)) {")
+			fmt.Fprintln(g.out, ws+"      continue")
+			fmt.Fprintln(g.out, ws+"    }")
+		}
 		fmt.Fprintln(g.out, ws+"    if "+tmpVar+"First { "+tmpVar+"First = false } else { out.RawByte(',') }")
+		fmt.Fprintln(g.out, ws+"    out.ElemStart()")
 
 		// NOTE: extra check for TextMarshaler. It overrides default methods.
-		if reflect.PtrTo(key).Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()) {
+		if key.PtrTo().Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()) {
 			fmt.Fprintln(g.out, ws+"    "+fmt.Sprintf("out.RawText(("+tmpVar+"Name).MarshalText()"+")"))
 		} else if keyEnc != "" {
 			fmt.Fprintln(g.out, ws+"    "+fmt.Sprintf(keyEnc, tmpVar+"Name"))
@@ -251,18 +280,26 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 			}
 		}
 
-		fmt.Fprintln(g.out, ws+"    out.RawByte(':')")
+		fmt.Fprintln(g.out, ws+"    out.Colon()")
 
 		if err := g.genTypeEncoder(t.Elem(), tmpVar+"Value", tags, indent+2, false); err != nil {
 			return err
 		}
+		if maskKey {
+			fmt.Fprintln(g.out, ws+"    out.LeaveField()")
+		}
 
 		fmt.Fprintln(g.out, ws+"  }")
-		fmt.Fprintln(g.out, ws+"  out.RawByte('}')")
+		fmt.Fprintln(g.out, ws+"  out.ObjectEnd()")
 		fmt.Fprintln(g.out, ws+"}")
 
 	case reflect.Interface:
-		if t.NumMethod() != 0 {
+		if g.unions[t] != nil {
+			enc := g.getEncoderName(t)
+			g.addType(t)
+
+			fmt.Fprintln(g.out, ws+enc+"(out, "+in+")")
+		} else if t.NumMethod() != 0 {
 			if g.interfaceIsEasyjsonMarshaller(t) {
 				fmt.Fprintln(g.out, ws+in+".MarshalEasyJSON(out)")
 			} else if g.interfaceIsJSONMarshaller(t) {
@@ -289,17 +326,17 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 	return nil
 }
 
-func (g *Generator) interfaceIsEasyjsonMarshaller(t reflect.Type) bool {
+func (g *Generator) interfaceIsEasyjsonMarshaller(t goType) bool {
 	return t.Implements(reflect.TypeOf((*easyjson.Marshaler)(nil)).Elem())
 }
 
-func (g *Generator) interfaceIsJSONMarshaller(t reflect.Type) bool {
+func (g *Generator) interfaceIsJSONMarshaller(t goType) bool {
 	return t.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem())
 }
 
-func (g *Generator) notEmptyCheck(t reflect.Type, v string) string {
+func (g *Generator) notEmptyCheck(t goType, v string) string {
 	optionalIface := reflect.TypeOf((*easyjson.Optional)(nil)).Elem()
-	if reflect.PtrTo(t).Implements(optionalIface) {
+	if t.PtrTo().Implements(optionalIface) {
 		return "(" + v + ").IsDefined()"
 	}
 
@@ -324,9 +361,12 @@ func (g *Generator) notEmptyCheck(t reflect.Type, v string) string {
 	}
 }
 
-func (g *Generator) genStructFieldEncoder(t reflect.Type, f reflect.StructField, first, firstCondition bool) (bool, error) {
-	jsonName := g.fieldNamer.GetJSONFieldName(t, f)
-	tags := parseFieldTags(f)
+// genStructFieldEncoder generates code encoding field f of struct type t. If
+// masked is set, the field is left out unless it is on a path of the mask of
+// the writer.
+func (g *Generator) genStructFieldEncoder(t goType, f structField, first, firstCondition, masked bool) (bool, error) {
+	jsonName := g.jsonFieldName(t, f)
+	tags := parseFieldTags(f.StructField)
 
 	if tags.omit {
 		return firstCondition, nil
@@ -334,52 +374,119 @@ func (g *Generator) genStructFieldEncoder(t reflect.Type, f reflect.StructField,
 
 	toggleFirstCondition := firstCondition
 
+	var conds []string
+	for _, p := range f.inlinePtrs {
+		conds = append(conds, "in."+p+" != nil")
+	}
 	noOmitEmpty := (!tags.omitEmpty && !g.omitEmpty) || tags.noOmitEmpty
-	if noOmitEmpty {
+	if !noOmitEmpty {
+		conds = append(conds, g.notEmptyCheck(f.Type, "in."+f.selector()))
+	}
+	if masked {
+		conds = append(conds, fmt.Sprintf("out.EnterField(%q)", jsonName))
+	}
+	if len(conds) == 0 {
 		fmt.Fprintln(g.out, "  {")
 		toggleFirstCondition = false
 	} else {
-		fmt.Fprintln(g.out, "  if", g.notEmptyCheck(f.Type, "in."+f.Name), "{")
-		// can be any in runtime, so toggleFirstCondition stay as is
+		// The fields of nil inline pointers, empty fields and the fields left
+		// out by the mask are skipped at runtime, so toggleFirstCondition stays as is.
+		fmt.Fprintln(g.out, "  if "+strings.Join(conds, " && ")+" {")
 	}
 
+	fmt.Fprintf(g.out, "    const prefix string = %q\n", ","+strconv.Quote(jsonName)+":")
 	if firstCondition {
-		fmt.Fprintf(g.out, "    const prefix string = %q\n", ","+strconv.Quote(jsonName)+":")
 		if first {
-			if !noOmitEmpty {
-				fmt.Fprintln(g.out, "      first = false")
-			}
-			fmt.Fprintln(g.out, "      out.RawString(prefix[1:])")
+			fmt.Fprintln(g.out, "      first = false")
+			fmt.Fprintln(g.out, "      out.RawField(prefix[1:])")
 		} else {
 			fmt.Fprintln(g.out, "    if first {")
 			fmt.Fprintln(g.out, "      first = false")
-			fmt.Fprintln(g.out, "      out.RawString(prefix[1:])")
+			fmt.Fprintln(g.out, "      out.RawField(prefix[1:])")
 			fmt.Fprintln(g.out, "    } else {")
-			fmt.Fprintln(g.out, "      out.RawString(prefix)")
+			fmt.Fprintln(g.out, "      out.RawField(prefix)")
 			fmt.Fprintln(g.out, "    }")
 		}
 	} else {
-		fmt.Fprintf(g.out, "    const prefix string = %q\n", ","+strconv.Quote(jsonName)+":")
-		fmt.Fprintln(g.out, "    out.RawString(prefix)")
+		fmt.Fprintln(g.out, "    out.RawField(prefix)")
 	}
 
-	if err := g.genTypeEncoder(f.Type, "in."+f.Name, tags, 2, !noOmitEmpty); err != nil {
+	if err := g.genTypeEncoder(f.Type, "in."+f.selector(), tags, 2, !noOmitEmpty); err != nil {
 		return toggleFirstCondition, err
 	}
+	if masked {
+		fmt.Fprintln(g.out, "    out.LeaveField()")
+	}
 	fmt.Fprintln(g.out, "  }")
 	return toggleFirstCondition, nil
 }
 
-func (g *Generator) genEncoder(t reflect.Type) error {
+// genMapRange generates the header of a loop over the entries of map m of type
+// t, declaring the variables tmpVar+"Name" and tmpVar+"Value". The entries of
+// maps with string keys are ranged over in sorted key order if SortMapKeys is
+// set.
+func (g *Generator) genMapRange(t goType, m, tmpVar, ws string) {
+	if !g.sortMapKeys || t.Key().Kind() != reflect.String {
+		fmt.Fprintln(g.out, ws+"for "+tmpVar+"Name, "+tmpVar+"Value := range "+m+" {")
+		return
+	}
+	g.imports["sort"] = "sort"
+
+	keys := tmpVar + "Keys"
+	fmt.Fprintln(g.out, ws+keys+" := make([]"+g.getType(t.Key())+", 0, len("+m+"))")
+	fmt.Fprintln(g.out, ws+"for "+tmpVar+"Name := range "+m+" {")
+	fmt.Fprintln(g.out, ws+"  "+keys+" = append("+keys+", "+tmpVar+"Name)")
+	fmt.Fprintln(g.out, ws+"}")
+	fmt.Fprintln(g.out, ws+"sort.Slice("+keys+", func(i, j int) bool { return "+keys+"[i] < "+keys+"[j] })")
+	fmt.Fprintln(g.out, ws+"for _, "+tmpVar+"Name := range "+keys+" {")
+	fmt.Fprintln(g.out, ws+"  "+tmpVar+"Value := ("+m+")["+tmpVar+"Name]")
+}
+
+// genInlineMapEncoder generates code encoding the entries of the inline map
+// field f as members of the struct object. If masked is set, the entries off
+// the paths of the mask of the writer are left out.
+func (g *Generator) genInlineMapEncoder(f structField, firstCondition, masked bool) error {
+	tmpVar := g.uniqueVarName()
+
+	g.genMapRange(f.Type, "in."+f.selector(), tmpVar, "  ")
+	if masked {
+		fmt.Fprintln(g.out, "    if !out.EnterField(string("+tmpVar+"Name)) {")
+		fmt.Fprintln(g.out, "      continue")
+		fmt.Fprintln(g.out, "    }")
+	}
+	if firstCondition {
+		fmt.Fprintln(g.out, "    if first { first = false } else { out.RawByte(',') }")
+	} else {
+		fmt.Fprintln(g.out, "    out.RawByte(',')")
+	}
+	fmt.Fprintln(g.out, "    out.ElemStart()")
+	fmt.Fprintln(g.out, "    out.String(string("+tmpVar+"Name))")
+	fmt.Fprintln(g.out, "    out.Colon()")
+	if err := g.genTypeEncoder(f.Type.Elem(), tmpVar+"Value", parseFieldTags(f.StructField), 2, false); err != nil {
+		return err
+	}
+	if masked {
+		fmt.Fprintln(g.out, "    out.LeaveField()")
+	}
+	fmt.Fprintln(g.out, "  }")
+	return nil
+}
+
+func (g *Generator) genEncoder(t goType) error {
+	if g.enums[t] != nil {
+		return g.genEnumEncoder(t)
+	}
 	switch t.Kind() {
 	case reflect.Slice, reflect.Array, reflect.Map:
 		return g.genSliceArrayMapEncoder(t)
+	case reflect.Interface:
+		return g.genUnionEncoder(t)
 	default:
 		return g.genStructEncoder(t)
 	}
 }
 
-func (g *Generator) genSliceArrayMapEncoder(t reflect.Type) error {
+func (g *Generator) genSliceArrayMapEncoder(t goType) error {
 	switch t.Kind() {
 	case reflect.Slice, reflect.Array, reflect.Map:
 	default:
@@ -398,7 +505,7 @@ func (g *Generator) genSliceArrayMapEncoder(t reflect.Type) error {
 	return nil
 }
 
-func (g *Generator) genStructEncoder(t reflect.Type) error {
+func (g *Generator) genStructEncoder(t goType) error {
 	if t.Kind() != reflect.Struct {
 		return fmt.Errorf("cannot generate encoder/decoder for %v, not a struct type", t)
 	}
@@ -407,7 +514,19 @@ func (g *Generator) genStructEncoder(t reflect.Type) error {
 	typ := g.getType(t)
 
 	fmt.Fprintln(g.out, "func "+fname+"(out *jwriter.Writer, in "+typ+") {")
-	fmt.Fprintln(g.out, "  out.RawByte('{')")
+	if err := g.genStructObjectEncoder(t, "", ""); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, "}")
+
+	return nil
+}
+
+// genStructObjectEncoder generates code encoding struct in of type t as an
+// object. If key is not empty, the object starts with the member with the
+// given key and value: the discriminator of a union variant.
+func (g *Generator) genStructObjectEncoder(t goType, key, value string) error {
+	fmt.Fprintln(g.out, "  out.ObjectStart()")
 	fmt.Fprintln(g.out, "  first := true")
 	fmt.Fprintln(g.out, "  _ = first")
 
@@ -417,14 +536,65 @@ func (g *Generator) genStructEncoder(t reflect.Type) error {
 	}
 
 	firstCondition := true
-	for i, f := range fs {
-		firstCondition, err = g.genStructFieldEncoder(t, f, i == 0, firstCondition)
+	if key != "" {
+		fmt.Fprintf(g.out, "  out.RawField(%q)\n", strconv.Quote(key)+":")
+		fmt.Fprintf(g.out, "  out.String(%q)\n", value)
+		firstCondition = false
+	}
+	hasFields := false
+	for _, f := range fs {
+		if parseFieldTags(f.StructField).omit {
+			continue
+		}
+		if key != "" && g.jsonFieldName(t, f) == key {
+			return fmt.Errorf("cannot generate encoder for union variant %v: field %v clashes with discriminator %q", t, f.Name, key)
+		}
+		hasFields = true
+	}
 
+	inlineMap, hasInlineMap, err := getInlineMapField(t)
+	if err != nil {
+		return fmt.Errorf("cannot generate encoder for %v: %v", t, err)
+	}
+
+	// The members are checked against the mask of the writer in a separate
+	// branch, so that the unmasked encoding is not slowed down.
+	if g.fieldMask && (hasFields || hasInlineMap) {
+		fmt.Fprintln(g.out, "  if out.Masked() {")
+		if err := g.genStructMembersEncoder(t, fs, inlineMap, hasInlineMap, firstCondition, true); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, "  } else {")
+		if err := g.genStructMembersEncoder(t, fs, inlineMap, hasInlineMap, firstCondition, false); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, "  }")
+	} else if err := g.genStructMembersEncoder(t, fs, inlineMap, hasInlineMap, firstCondition, false); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(g.out, "  out.ObjectEnd()")
+	return nil
+}
+
+// genStructMembersEncoder generates code encoding the fields fs and the inline
+// map of struct in of type t as object members. If masked is set, the members
+// off the paths of the mask of the writer are left out.
+func (g *Generator) genStructMembersEncoder(t goType, fs []structField, inlineMap structField, hasInlineMap, firstCondition, masked bool) error {
+	var err error
+	for i, f := range fs {
+		firstCondition, err = g.genStructFieldEncoder(t, f, i == 0, firstCondition, masked)
 		if err != nil {
 			return err
 		}
 	}
 
+	if hasInlineMap {
+		if err := g.genInlineMapEncoder(inlineMap, firstCondition, masked); err != nil {
+			return err
+		}
+	}
+
 	if hasUnknownsMarshaler(t) {
 		if !firstCondition {
 			fmt.Fprintln(g.out, "  in.MarshalUnknowns(out, false)")
@@ -432,18 +602,16 @@ func (g *Generator) genStructEncoder(t reflect.Type) error {
 			fmt.Fprintln(g.out, "  in.MarshalUnknowns(out, first)")
 		}
 	}
-
-	fmt.Fprintln(g.out, "  out.RawByte('}')")
-	fmt.Fprintln(g.out, "}")
-
 	return nil
 }
 
-func (g *Generator) genStructMarshaler(t reflect.Type) error {
+func (g *Generator) genStructMarshaler(t goType) error {
 	switch t.Kind() {
 	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
 	default:
-		return fmt.Errorf("cannot generate encoder/decoder for %v, not a struct/slice/array/map type", t)
+		if g.enums[t] == nil {
+			return fmt.Errorf("cannot generate encoder/decoder for %v, not a struct/slice/array/map type", t)
+		}
 	}
 
 	fname := g.getEncoderName(t)
@@ -465,3 +633,42 @@ func (g *Generator) genStructMarshaler(t reflect.Type) error {
 
 	return nil
 }
+
+// genGenericMarshaler generates the marshalers shared by the instantiations of a
+// generic type. Methods cannot be declared on instantiations, so the methods are
+// declared on the generic type and dispatch on the instantiation at run time.
+func (g *Generator) genGenericMarshaler(types []goType) error {
+	for _, t := range types {
+		if t.Kind() != reflect.Struct {
+			return fmt.Errorf("cannot generate encoder/decoder for %v, not a struct type", t)
+		}
+	}
+
+	g.imports["fmt"] = "fmt"
+	typ := genericReceiver(types[0])
+
+	if !g.noStdMarshalers {
+		fmt.Fprintln(g.out, "// MarshalJSON supports json.Marshaler interface")
+		fmt.Fprintln(g.out, "func (v "+typ+") MarshalJSON() ([]byte, error) {")
+		fmt.Fprintln(g.out, "  w := jwriter.Writer{}")
+		fmt.Fprintln(g.out, "  v.MarshalEasyJSON(&w)")
+		fmt.Fprintln(g.out, "  return w.Buffer.BuildBytes(), w.Error")
+		fmt.Fprintln(g.out, "}")
+	}
+
+	fmt.Fprintln(g.out, "// MarshalEasyJSON supports easyjson.Marshaler interface")
+	fmt.Fprintln(g.out, "func (v "+typ+") MarshalEasyJSON(w *jwriter.Writer) {")
+	fmt.Fprintln(g.out, "  switch v := interface{}(v).(type) {")
+	for _, t := range types {
+		fmt.Fprintln(g.out, "  case "+g.getType(t)+":")
+		fmt.Fprintln(g.out, "    "+g.getEncoderName(t)+"(w, v)")
+	}
+	fmt.Fprintln(g.out, "  default:")
+	fmt.Fprintln(g.out, "    if w.Error == nil {")
+	fmt.Fprintf(g.out, "      w.Error = fmt.Errorf(%q, v)\n", "easyjson: no marshaler generated for %T")
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "}")
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package gen
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+// Enum describes how the values of an integer type are encoded as the JSON
+// strings of its constants, see AddEnum.
+type Enum struct {
+	// Consts lists the constants of the type in declaration order.
+	Consts []EnumConst
+
+	// TrimPrefix is removed from the names of the constants without an
+	// explicit JSON string, e.g. 'Color' for 'ColorRed'.
+	TrimPrefix string
+
+	// SnakeCase converts the names of the constants without an explicit JSON
+	// string to snake_case.
+	SnakeCase bool
+
+	// Fallback is the name of the constant that unknown strings are decoded
+	// to and values other than the constants are encoded as. Unknown strings
+	// and values are errors if it is empty.
+	Fallback string
+}
+
+// EnumConst is a constant of an enum type.
+type EnumConst struct {
+	Name  string // Go identifier.
+	JSON  string // String the constant is encoded as, derived from Name if empty.
+	Value string // Exact value, constants with equal values are aliases.
+}
+
+// enum is an Enum with the JSON strings of the constants resolved.
+type enum struct {
+	Enum
+	consts []EnumConst // Without aliases encoded as the same string.
+}
+
+// AddEnum requests to encode and decode the values of the integer type of obj,
+// a pointer to the type, as the JSON strings of its constants. The type must
+// also be added with Add, MarshalText and UnmarshalText methods are generated
+// for it along with the marshalers.
+func (g *Generator) AddEnum(obj interface{}, e Enum) {
+	rt := reflect.TypeOf(obj)
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	g.enums[reflectType{rt}] = &enum{Enum: e}
+}
+
+// AddEnumType requests to encode and decode the values of integer type t as
+// the JSON strings of its constants, see AddEnum.
+func (g *Generator) AddEnumType(t types.Type, e Enum) {
+	g.enums[g.typesUniverse().typ(t)] = &enum{Enum: e}
+}
+
+// resolveEnum checks that enum type t can be encoded and decoded and resolves
+// the JSON strings of its constants.
+func (g *Generator) resolveEnum(t goType) (*enum, error) {
+	e := g.enums[t]
+	if e.consts != nil {
+		return e, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		return nil, fmt.Errorf("cannot generate encoder/decoder for enum %v, not an integer type", t)
+	}
+	if len(e.Consts) == 0 {
+		return nil, fmt.Errorf("cannot generate encoder/decoder for enum %v, no constants", t)
+	}
+
+	byJSON := map[string]EnumConst{}
+	fallback := false
+	for _, c := range e.Consts {
+		if c.JSON == "" {
+			c.JSON = strings.TrimPrefix(c.Name, e.TrimPrefix)
+			if e.SnakeCase {
+				c.JSON = camelToSnake(c.JSON)
+			}
+		}
+		fallback = fallback || c.Name == e.Fallback
+		if prev, ok := byJSON[c.JSON]; ok {
+			if prev.Value != c.Value {
+				return nil, fmt.Errorf("cannot generate encoder/decoder for enum %v: constants %v and %v are both encoded as %q", t, prev.Name, c.Name, c.JSON)
+			}
+			continue
+		}
+		byJSON[c.JSON] = c
+		e.consts = append(e.consts, c)
+	}
+	if e.Fallback != "" && !fallback {
+		return nil, fmt.Errorf("cannot generate encoder/decoder for enum %v: fallback %v is not a constant of the type", t, e.Fallback)
+	}
+	return e, nil
+}
+
+// encodedConsts returns the constants that the values are encoded as: the
+// first one declared with each value.
+func (e *enum) encodedConsts() []EnumConst {
+	var consts []EnumConst
+	seen := map[string]bool{}
+	for _, c := range e.consts {
+		if !seen[c.Value] {
+			seen[c.Value] = true
+			consts = append(consts, c)
+		}
+	}
+	return consts
+}
+
+// fallbackJSON returns the JSON string of the fallback constant.
+func (e *enum) fallbackJSON() string {
+	for _, c := range e.consts {
+		if c.Name == e.Fallback {
+			return c.JSON
+		}
+	}
+	return ""
+}
+
+func (g *Generator) genEnumDecoder(t goType) error {
+	e, err := g.resolveEnum(t)
+	if err != nil {
+		return err
+	}
+	fname := g.getDecoderName(t)
+	typ := g.getType(t)
+
+	fmt.Fprintln(g.out, "func "+fname+"(in *jlexer.Lexer, out *"+typ+") {")
+	fmt.Fprintln(g.out, "  isTopLevel := in.IsStart()")
+	fmt.Fprintln(g.out, "  if in.IsNull() {")
+	fmt.Fprintln(g.out, "    in.Skip()")
+	fmt.Fprintln(g.out, "  } else {")
+	fmt.Fprintln(g.out, "    switch s := in.UnsafeString(); s {")
+	for _, c := range e.consts {
+		fmt.Fprintf(g.out, "    case %q:\n", c.JSON)
+		fmt.Fprintln(g.out, "      *out = "+c.Name)
+	}
+	fmt.Fprintln(g.out, "    default:")
+	if e.Fallback != "" {
+		fmt.Fprintln(g.out, "      *out = "+e.Fallback)
+	} else {
+		fmt.Fprintln(g.out, "      if in.Ok() {")
+		fmt.Fprintf(g.out, `        in.AddError(&jlexer.LexerError{
+            Offset: in.GetPos(),
+            Reason: %q,
+            Data: s,
+        })
+`, "unknown "+t.Name()+" value")
+		fmt.Fprintln(g.out, "      }")
+	}
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "  if isTopLevel {")
+	fmt.Fprintln(g.out, "    in.Consumed()")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+func (g *Generator) genEnumEncoder(t goType) error {
+	e, err := g.resolveEnum(t)
+	if err != nil {
+		return err
+	}
+	fname := g.getEncoderName(t)
+	typ := g.getType(t)
+
+	fmt.Fprintln(g.out, "func "+fname+"(out *jwriter.Writer, in "+typ+") {")
+	fmt.Fprintln(g.out, "  switch in {")
+	for _, c := range e.encodedConsts() {
+		fmt.Fprintln(g.out, "  case "+c.Name+":")
+		fmt.Fprintf(g.out, "    out.String(%q)\n", c.JSON)
+	}
+	fmt.Fprintln(g.out, "  default:")
+	if e.Fallback != "" {
+		fmt.Fprintf(g.out, "    out.String(%q)\n", e.fallbackJSON())
+	} else {
+		g.imports["fmt"] = "fmt"
+		fmt.Fprintln(g.out, "    if out.Error == nil {")
+		fmt.Fprintf(g.out, "      out.Error = fmt.Errorf(%q, in)\n", "easyjson: unknown "+t.Name()+" value %d")
+		fmt.Fprintln(g.out, "    }")
+	}
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+// genEnumTextMarshaler generates the MarshalText and UnmarshalText methods of
+// enum type t, so that it is encoded as a string by other encoders and as a
+// map key.
+func (g *Generator) genEnumTextMarshaler(t goType) error {
+	e, err := g.resolveEnum(t)
+	if err != nil {
+		return err
+	}
+	typ := g.getType(t)
+
+	fmt.Fprintln(g.out, "// MarshalText supports encoding.TextMarshaler interface")
+	fmt.Fprintln(g.out, "func (v "+typ+") MarshalText() ([]byte, error) {")
+	fmt.Fprintln(g.out, "  switch v {")
+	for _, c := range e.encodedConsts() {
+		fmt.Fprintln(g.out, "  case "+c.Name+":")
+		fmt.Fprintf(g.out, "    return []byte(%q), nil\n", c.JSON)
+	}
+	fmt.Fprintln(g.out, "  }")
+	if e.Fallback != "" {
+		fmt.Fprintf(g.out, "  return []byte(%q), nil\n", e.fallbackJSON())
+	} else {
+		g.imports["fmt"] = "fmt"
+		fmt.Fprintf(g.out, "  return nil, fmt.Errorf(%q, v)\n", "easyjson: unknown "+t.Name()+" value %d")
+	}
+	fmt.Fprintln(g.out, "}")
+
+	fmt.Fprintln(g.out, "// UnmarshalText supports encoding.TextUnmarshaler interface")
+	fmt.Fprintln(g.out, "func (v *"+typ+") UnmarshalText(data []byte) error {")
+	fmt.Fprintln(g.out, "  switch string(data) {")
+	for _, c := range e.consts {
+		fmt.Fprintf(g.out, "  case %q:\n", c.JSON)
+		fmt.Fprintln(g.out, "    *v = "+c.Name)
+	}
+	fmt.Fprintln(g.out, "  default:")
+	if e.Fallback != "" {
+		fmt.Fprintln(g.out, "    *v = "+e.Fallback)
+	} else {
+		g.imports["fmt"] = "fmt"
+		fmt.Fprintf(g.out, "    return fmt.Errorf(%q, data)\n", "easyjson: unknown "+t.Name()+" value %q")
+	}
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "  return nil")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
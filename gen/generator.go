@@ -7,6 +7,7 @@ import (
 	"io"
 	"path"
 	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -17,7 +18,17 @@ const pkgWriter = "github.com/mailru/easyjson/jwriter"
 const pkgLexer = "github.com/mailru/easyjson/jlexer"
 const pkgEasyJSON = "github.com/mailru/easyjson"
 
+// qualifiedNameRegexp matches package-qualified type names such as
+// 'github.com/mailru/easyjson/opt.Int' in type arguments of generic types.
+var qualifiedNameRegexp = regexp.MustCompile(`[\w\-./]+\.\w+`)
+
+// pkgPathPrefixRegexp matches the leading directories of import paths.
+var pkgPathPrefixRegexp = regexp.MustCompile(`[\w\-.]*/`)
+
 // FieldNamer defines a policy for generating names for struct fields.
+//
+// The struct type passed to GetJSONFieldName is nil for the types added with
+// AddType, which are not known to reflect, and so is the Type of the field.
 type FieldNamer interface {
 	GetJSONFieldName(t reflect.Type, f reflect.StructField) string
 }
@@ -39,22 +50,44 @@ type Generator struct {
 	fieldNamer               FieldNamer
 	simpleBytes              bool
 	skipMemberNameUnescaping bool
+	jsonSchema               bool
+	caseInsensitive          bool
+	deepCopyEqual            bool
+	sortMapKeys              bool
+	fieldMask                bool
+	mergePatch               bool
+	diffJSON                 bool
 
 	// package path to local alias map for tracking imports
 	imports map[string]string
 
 	// types that marshalers were requested for by user
-	marshalers map[reflect.Type]bool
+	marshalers map[goType]bool
+
+	// types that object keys are matched case-insensitively for
+	caseInsensitiveTypes map[goType]bool
+
+	// interface types encoded as discriminated unions
+	unions map[goType]*union
+
+	// integer types encoded as the strings of their constants
+	enums map[goType]*enum
 
 	// types that encoders were already generated for
-	typesSeen map[reflect.Type]bool
+	typesSeen map[goType]bool
 
 	// types that encoders were requested for (e.g. by encoders of other types)
-	typesUnseen []reflect.Type
+	typesUnseen []goType
 
 	// function name to relevant type maps to track names of de-/encoders in
 	// case of a name clash or unnamed structs
-	functionNames map[string]reflect.Type
+	functionNames map[string]goType
+
+	// types added with AddType
+	universe *typesUniverse
+
+	// patterns of the jsonschema tags of the decoded fields
+	patterns []string
 }
 
 // NewGenerator initializes and returns a Generator.
@@ -66,10 +99,13 @@ func NewGenerator(filename string) *Generator {
 			pkgEasyJSON:     "easyjson",
 			"encoding/json": "json",
 		},
-		fieldNamer:    DefaultFieldNamer{},
-		marshalers:    make(map[reflect.Type]bool),
-		typesSeen:     make(map[reflect.Type]bool),
-		functionNames: make(map[string]reflect.Type),
+		fieldNamer:           DefaultFieldNamer{},
+		marshalers:           make(map[goType]bool),
+		caseInsensitiveTypes: make(map[goType]bool),
+		unions:               make(map[goType]*union),
+		enums:                make(map[goType]*enum),
+		typesSeen:            make(map[goType]bool),
+		functionNames:        make(map[string]goType),
 	}
 
 	// Use a file-unique prefix on all auxiliary funcs to avoid
@@ -123,18 +159,71 @@ func (g *Generator) SkipMemberNameUnescaping() {
 	g.skipMemberNameUnescaping = true
 }
 
+// CaseInsensitiveKeys instructs decoders to fall back to case-insensitive
+// matching of object keys, like encoding/json does, when a key does not match
+// any field exactly.
+func (g *Generator) CaseInsensitiveKeys() {
+	g.caseInsensitive = true
+}
+
+// CaseInsensitiveKeysFor enables case-insensitive matching of object keys, see
+// CaseInsensitiveKeys, for the decoder of the type of obj only.
+func (g *Generator) CaseInsensitiveKeysFor(obj interface{}) {
+	rt := reflect.TypeOf(obj)
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	g.caseInsensitiveTypes[reflectType{rt}] = true
+}
+
 // OmitEmpty triggers `json=",omitempty"` behaviour by default.
 func (g *Generator) OmitEmpty() {
 	g.omitEmpty = true
 }
 
+// EmitJSONSchema instructs to generate JSONSchema methods returning the JSON
+// Schema documents of the types marshalers are generated for.
+func (g *Generator) EmitJSONSchema() {
+	g.jsonSchema = true
+}
+
+// EmitDeepCopyEqual instructs to generate DeepCopy and Equal methods for the
+// types marshalers are generated for.
+func (g *Generator) EmitDeepCopyEqual() {
+	g.deepCopyEqual = true
+}
+
+// SortMapKeys instructs encoders to encode the entries of maps with string keys
+// in sorted key order rather than in the random map iteration order.
+func (g *Generator) SortMapKeys() {
+	g.sortMapKeys = true
+}
+
+// MaskFields instructs encoders to leave out the struct fields and the entries of
+// maps with string keys that are off the paths of the field mask of the writer.
+func (g *Generator) MaskFields() {
+	g.fieldMask = true
+}
+
+// EmitMergePatch instructs to generate ApplyMergePatch methods applying JSON
+// merge patches (RFC 7396) to the struct types marshalers are generated for.
+func (g *Generator) EmitMergePatch() {
+	g.mergePatch = true
+}
+
+// EmitDiffJSON instructs to generate DiffJSON methods returning the JSON
+// patches (RFC 6902) between values of the types marshalers are generated for.
+func (g *Generator) EmitDiffJSON() {
+	g.diffJSON = true
+}
+
 // SimpleBytes triggers generate output bytes as slice byte
 func (g *Generator) SimpleBytes() {
 	g.simpleBytes = true
 }
 
 // addTypes requests to generate encoding/decoding funcs for the given type.
-func (g *Generator) addType(t reflect.Type) {
+func (g *Generator) addType(t goType) {
 	if g.typesSeen[t] {
 		return
 	}
@@ -149,24 +238,25 @@ func (g *Generator) addType(t reflect.Type) {
 // Add requests to generate marshaler/unmarshalers and encoding/decoding
 // funcs for the type of given object.
 func (g *Generator) Add(obj interface{}) {
-	t := reflect.TypeOf(obj)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+	rt := reflect.TypeOf(obj)
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
 	}
+	t := reflectType{rt}
 	g.addType(t)
 	g.marshalers[t] = true
 }
 
 // printHeader prints package declaration and imports.
-func (g *Generator) printHeader() {
+func (g *Generator) printHeader(out io.Writer) {
 	if g.buildTags != "" {
-		fmt.Println("// +build ", g.buildTags)
-		fmt.Println()
+		fmt.Fprintln(out, "// +build ", g.buildTags)
+		fmt.Fprintln(out)
 	}
-	fmt.Println("// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.")
-	fmt.Println()
-	fmt.Println("package ", g.pkgName)
-	fmt.Println()
+	fmt.Fprintln(out, "// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "package ", g.pkgName)
+	fmt.Fprintln(out)
 
 	byAlias := make(map[string]string, len(g.imports))
 	aliases := make([]string, 0, len(g.imports))
@@ -177,28 +267,32 @@ func (g *Generator) printHeader() {
 	}
 
 	sort.Strings(aliases)
-	fmt.Println("import (")
+	fmt.Fprintln(out, "import (")
 	for _, alias := range aliases {
-		fmt.Printf("  %s %q\n", alias, byAlias[alias])
+		fmt.Fprintf(out, "  %s %q\n", alias, byAlias[alias])
 	}
 
-	fmt.Println(")")
-	fmt.Println("")
-	fmt.Println("// suppress unused package warning")
-	fmt.Println("var (")
-	fmt.Println("   _ *json.RawMessage")
-	fmt.Println("   _ *jlexer.Lexer")
-	fmt.Println("   _ *jwriter.Writer")
-	fmt.Println("   _ easyjson.Marshaler")
-	fmt.Println(")")
+	fmt.Fprintln(out, ")")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "// suppress unused package warning")
+	fmt.Fprintln(out, "var (")
+	fmt.Fprintln(out, "   _ *json.RawMessage")
+	fmt.Fprintln(out, "   _ *jlexer.Lexer")
+	fmt.Fprintln(out, "   _ *jwriter.Writer")
+	fmt.Fprintln(out, "   _ easyjson.Marshaler")
+	fmt.Fprintln(out, ")")
 
-	fmt.Println()
+	fmt.Fprintln(out)
 }
 
 // Run runs the generator and outputs generated code to out.
 func (g *Generator) Run(out io.Writer) error {
 	g.out = &bytes.Buffer{}
 
+	// instantiations of generic types grouped by the generic type name
+	var generics []string
+	instances := map[string][]goType{}
+
 	for len(g.typesUnseen) > 0 {
 		t := g.typesUnseen[len(g.typesUnseen)-1]
 		g.typesUnseen = g.typesUnseen[:len(g.typesUnseen)-1]
@@ -210,19 +304,106 @@ func (g *Generator) Run(out io.Writer) error {
 		if err := g.genEncoder(t); err != nil {
 			return err
 		}
+		if g.deepCopyEqual {
+			if err := g.genCopier(t); err != nil {
+				return err
+			}
+			if err := g.genEqual(t); err != nil {
+				return err
+			}
+		}
+		if g.mergePatch && t.Kind() == reflect.Struct {
+			if err := g.genMerger(t); err != nil {
+				return err
+			}
+		}
+		if g.diffJSON {
+			if err := g.genDiffer(t); err != nil {
+				return err
+			}
+		}
 
 		if !g.marshalers[t] {
 			continue
 		}
 
+		if base := genericName(t); base != "" {
+			if instances[base] == nil {
+				generics = append(generics, base)
+			}
+			instances[base] = append(instances[base], t)
+			continue
+		}
+
 		if err := g.genStructMarshaler(t); err != nil {
 			return err
 		}
 		if err := g.genStructUnmarshaler(t); err != nil {
 			return err
 		}
+		if g.jsonSchema {
+			if err := g.genJSONSchema(t); err != nil {
+				return err
+			}
+		}
+		if g.enums[t] != nil {
+			if err := g.genEnumTextMarshaler(t); err != nil {
+				return err
+			}
+			continue
+		}
+		if g.deepCopyEqual {
+			if err := g.genDeepCopyEqual(t); err != nil {
+				return err
+			}
+		}
+		if g.mergePatch && t.Kind() == reflect.Struct {
+			if err := g.genMergePatcher(t); err != nil {
+				return err
+			}
+		}
+		if g.diffJSON {
+			if err := g.genDiffJSON(t); err != nil {
+				return err
+			}
+		}
 	}
-	g.printHeader()
+
+	sort.Strings(generics)
+	for _, base := range generics {
+		types := instances[base]
+		sort.Slice(types, func(i, j int) bool { return types[i].Name() < types[j].Name() })
+
+		if err := g.genGenericMarshaler(types); err != nil {
+			return err
+		}
+		if err := g.genGenericUnmarshaler(types); err != nil {
+			return err
+		}
+		if g.jsonSchema {
+			if err := g.genGenericJSONSchema(types); err != nil {
+				return err
+			}
+		}
+		if g.deepCopyEqual {
+			if err := g.genGenericDeepCopyEqual(types); err != nil {
+				return err
+			}
+		}
+		if g.mergePatch {
+			if err := g.genGenericMergePatcher(types); err != nil {
+				return err
+			}
+		}
+		if g.diffJSON {
+			if err := g.genGenericDiffJSON(types); err != nil {
+				return err
+			}
+		}
+	}
+	g.genPatternVars()
+
+	g.printHeader(out)
 	_, err := out.Write(g.out.Bytes())
 	return err
 }
@@ -279,7 +460,7 @@ func (g *Generator) pkgAlias(pkgPath string) string {
 }
 
 // getType return the textual type name of given type that can be used in generated code.
-func (g *Generator) getType(t reflect.Type) string {
+func (g *Generator) getType(t goType) string {
 	if t.Name() == "" {
 		switch t.Kind() {
 		case reflect.Ptr:
@@ -318,9 +499,69 @@ func (g *Generator) getType(t reflect.Type) string {
 		}
 		return t.String()
 	} else if t.PkgPath() == g.pkgPath {
-		return t.Name()
+		return g.typeName(t)
 	}
-	return g.pkgAlias(t.PkgPath()) + "." + t.Name()
+	return g.pkgAlias(t.PkgPath()) + "." + g.typeName(t)
+}
+
+// typeName returns the unqualified name of a named type. Package paths in the
+// type arguments of generic type instantiations are replaced with the import
+// aliases, e.g. 'Page[github.com/mailru/easyjson/opt.Int]' becomes 'Page[opt.Int]'.
+func (g *Generator) typeName(t goType) string {
+	name := t.Name()
+	i := strings.IndexByte(name, '[')
+	if i < 0 {
+		return name
+	}
+
+	return name[:i] + qualifiedNameRegexp.ReplaceAllStringFunc(name[i:], func(s string) string {
+		dot := strings.LastIndexByte(s, '.')
+		if s[:dot] == g.pkgPath {
+			return s[dot+1:]
+		}
+		return g.pkgAlias(s[:dot]) + s[dot:]
+	})
+}
+
+// genericName returns the name of the generic type that t is an instantiation
+// of, or an empty string if t is not an instantiation of a generic type.
+func genericName(t goType) string {
+	if i := strings.IndexByte(t.Name(), '['); i >= 0 {
+		return t.Name()[:i]
+	}
+	return ""
+}
+
+// genericReceiver returns the receiver type for methods of a generic type with
+// blank type parameters, e.g. 'Pair[_, _]' for 'Pair[string, int]'.
+func genericReceiver(t goType) string {
+	return GenericReceiver(t.Name())
+}
+
+// GenericReceiver returns the receiver type for methods of the type named t:
+// the type itself or, for an instantiation of a generic type, the generic type
+// with blank type parameters, e.g. 'Pair[_, _]' for 'Pair[string, int]'.
+func GenericReceiver(t string) string {
+	i := strings.IndexByte(t, '[')
+	if i < 0 {
+		return t
+	}
+
+	params := 1
+	depth := 0
+	for _, c := range t[i+1 : len(t)-1] {
+		switch c {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				params++
+			}
+		}
+	}
+	return t[:i] + "[" + strings.Repeat("_, ", params-1) + "_]"
 }
 
 // escape a struct field tag string back to source code
@@ -341,12 +582,13 @@ func (g *Generator) uniqueVarName() string {
 
 // safeName escapes unsafe characters in pkg/type name and returns a string that can be used
 // in encoder/decoder names for the type.
-func (g *Generator) safeName(t reflect.Type) string {
+func (g *Generator) safeName(t goType) string {
 	name := t.PkgPath()
 	if t.Name() == "" {
 		name += "anonymous"
 	} else {
-		name += "." + t.Name()
+		// Shorten package paths in type arguments to package names.
+		name += "." + pkgPathPrefixRegexp.ReplaceAllString(t.Name(), "")
 	}
 
 	parts := []string{}
@@ -366,7 +608,7 @@ func (g *Generator) safeName(t reflect.Type) string {
 // with this prefix already exists for a type, it is returned.
 //
 // Method is used to track encoder/decoder names for the type.
-func (g *Generator) functionName(prefix string, t reflect.Type) string {
+func (g *Generator) functionName(prefix string, t goType) string {
 	prefix = joinFunctionNameParts(true, "easyjson", g.hashString, prefix)
 	name := joinFunctionNameParts(true, prefix, g.safeName(t))
 
@@ -394,6 +636,16 @@ func (g *Generator) functionName(prefix string, t reflect.Type) string {
 	}
 }
 
+// jsonFieldName returns the JSON name of field f of type t. The field namer
+// gets a nil reflect.Type for go/types types.
+func (g *Generator) jsonFieldName(t goType, f structField) string {
+	var rt reflect.Type
+	if t, ok := t.(reflectType); ok {
+		rt = t.Type
+	}
+	return g.fieldNamer.GetJSONFieldName(rt, f.StructField)
+}
+
 // DefaultFieldsNamer implements trivial naming policy equivalent to encoding/json.
 type DefaultFieldNamer struct{}
 
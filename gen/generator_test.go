@@ -1,6 +1,7 @@
 package gen
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -85,3 +86,32 @@ func TestFixVendorPath(t *testing.T) {
 	}
 
 }
+
+func TestDefaultValue(t *testing.T) {
+	type port uint16
+
+	for i, test := range []struct {
+		Type  reflect.Type
+		Value string
+		Want  string
+		Err   bool
+	}{
+		{Type: reflect.TypeOf(""), Value: "a,b", Want: `"a,b"`},
+		{Type: reflect.TypeOf(0), Value: " -5", Want: "-5"},
+		{Type: reflect.TypeOf(int8(0)), Value: "200", Err: true},
+		{Type: reflect.TypeOf(port(0)), Value: "8080", Want: "gen.port(8080)"},
+		{Type: reflect.TypeOf(0.0), Value: "1e3", Want: "1000"},
+		{Type: reflect.TypeOf(false), Value: "yes", Err: true},
+		{Type: reflect.TypeOf([]int{}), Value: "1,2", Want: "[]int{1, 2}"},
+		{Type: reflect.TypeOf([]string{}), Value: "", Want: "[]string{}"},
+		{Type: reflect.TypeOf(map[string]int{}), Value: "", Err: true},
+	} {
+		g := NewGenerator("test.go")
+		got, err := g.defaultValue(reflectType{test.Type}, test.Value)
+		if (err != nil) != test.Err {
+			t.Errorf("[%d, %v] defaultValue(%q) error = %v", i, test.Type, test.Value, err)
+		} else if got != test.Want {
+			t.Errorf("[%d, %v] defaultValue(%q) = %s; want %s", i, test.Type, test.Value, got, test.Want)
+		}
+	}
+}
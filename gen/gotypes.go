@@ -0,0 +1,493 @@
+package gen
+
+import (
+	"go/types"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+var basicKinds = map[types.BasicKind]reflect.Kind{
+	types.Bool:          reflect.Bool,
+	types.Int:           reflect.Int,
+	types.Int8:          reflect.Int8,
+	types.Int16:         reflect.Int16,
+	types.Int32:         reflect.Int32,
+	types.Int64:         reflect.Int64,
+	types.Uint:          reflect.Uint,
+	types.Uint8:         reflect.Uint8,
+	types.Uint16:        reflect.Uint16,
+	types.Uint32:        reflect.Uint32,
+	types.Uint64:        reflect.Uint64,
+	types.Uintptr:       reflect.Uintptr,
+	types.Float32:       reflect.Float32,
+	types.Float64:       reflect.Float64,
+	types.Complex64:     reflect.Complex64,
+	types.Complex128:    reflect.Complex128,
+	types.String:        reflect.String,
+	types.UnsafePointer: reflect.UnsafePointer,
+}
+
+// typesUniverse keeps the go/types types used by a generator, so that
+// identical types are represented by the same typesType.
+type typesUniverse struct {
+	g     *Generator
+	sizes types.Sizes
+	types map[string]*typesType
+
+	// generic types or types that marshalers are generated for
+	marshalers map[*types.TypeName]bool
+
+	// packages imported by the packages of the types, by path
+	pkgs map[string]*types.Package
+}
+
+func (u *typesUniverse) typ(t types.Type) *typesType {
+	t = unalias(t)
+	key := types.TypeString(t, nil)
+	if ret := u.types[key]; ret != nil {
+		return ret
+	}
+	ret := &typesType{u: u, t: t}
+	u.types[key] = ret
+	return ret
+}
+
+// hasMarshalers reports whether t is a type that marshalers are being
+// generated for. The methods are not declared yet, so the generator assumes
+// they exist the same way the bootstrapping code does by generating stubs.
+func (u *typesUniverse) hasMarshalers(t types.Type) bool {
+	n, ok := t.(*types.Named)
+	return ok && u.marshalers[n.Origin().Obj()]
+}
+
+// typesUniverse returns the universe of the types added with AddType.
+func (g *Generator) typesUniverse() *typesUniverse {
+	if g.universe == nil {
+		g.universe = &typesUniverse{
+			g:          g,
+			sizes:      types.SizesFor("gc", runtime.GOARCH),
+			types:      map[string]*typesType{},
+			marshalers: map[*types.TypeName]bool{},
+		}
+	}
+	return g.universe
+}
+
+// AddType requests to generate marshaler/unmarshalers and encoding/decoding
+// funcs for the given type from type-checked sources. The package of the type
+// must not include a previously generated file with the methods of the type.
+//
+// FieldNamer implementations get a nil reflect.Type and struct fields with a
+// nil Type for types added with AddType.
+func (g *Generator) AddType(t types.Type) {
+	u := g.typesUniverse()
+	if n, ok := t.(*types.Named); ok {
+		u.marshalers[n.Origin().Obj()] = true
+	}
+
+	gt := u.typ(t)
+	g.addType(gt)
+	g.marshalers[gt] = true
+}
+
+// CaseInsensitiveKeysForType enables case-insensitive matching of object keys,
+// see CaseInsensitiveKeys, for the decoder of the given type only.
+func (g *Generator) CaseInsensitiveKeysForType(t types.Type) {
+	g.caseInsensitiveTypes[g.typesUniverse().typ(t)] = true
+}
+
+// AddUnionType requests to encode and decode the values of interface type t
+// as a discriminated union, see AddUnion. The variants map the discriminator
+// values to the variant types.
+func (g *Generator) AddUnionType(t types.Type, key string, variants map[string]types.Type) {
+	u := g.typesUniverse()
+
+	vs := make(map[string]goType, len(variants))
+	for value, v := range variants {
+		vs[value] = u.typ(v)
+	}
+	g.addUnion(u.typ(t), key, vs)
+}
+
+// typesType implements goType for go/types type information.
+type typesType struct {
+	u *typesUniverse
+	t types.Type
+}
+
+func (t *typesType) Name() string {
+	switch tt := t.t.(type) {
+	case *types.Basic:
+		// return the names of aliased types, e.g. 'uint8' for 'byte'
+		return types.Typ[tt.Kind()].Name()
+	case *types.Named:
+		return namedTypeName(tt)
+	}
+	return ""
+}
+
+func (t *typesType) PkgPath() string {
+	if n, ok := t.t.(*types.Named); ok && n.Obj().Pkg() != nil {
+		return n.Obj().Pkg().Path()
+	}
+	return ""
+}
+
+func (t *typesType) String() string {
+	return typeString(t.t, pkgName)
+}
+
+func pkgPath(p *types.Package) string { return p.Path() }
+func pkgName(p *types.Package) string { return p.Name() }
+
+// namedTypeName returns the name of named type t like reflect.Type.Name does:
+// the type arguments of an instantiation of a generic type are qualified by
+// their package paths, e.g. 'Page[github.com/user/pkg.Item]'.
+func namedTypeName(t *types.Named) string {
+	name := t.Obj().Name()
+	if args := t.TypeArgs(); args.Len() > 0 {
+		list := make([]string, 0, args.Len())
+		for i := 0; i < args.Len(); i++ {
+			list = append(list, typeString(args.At(i), pkgPath))
+		}
+		name += "[" + strings.Join(list, ",") + "]"
+	}
+	return name
+}
+
+// typeString formats type t like reflect.Type.String does, the named types are
+// qualified by qualify.
+func typeString(t types.Type, qualify func(*types.Package) string) string {
+	switch t := unalias(t).(type) {
+	case *types.Basic:
+		if t.Kind() == types.UnsafePointer {
+			return "unsafe.Pointer"
+		}
+		return types.Typ[t.Kind()].Name()
+	case *types.Named:
+		if t.Obj().Pkg() == nil {
+			return t.Obj().Name()
+		}
+		return qualify(t.Obj().Pkg()) + "." + namedTypeName(t)
+	case *types.Pointer:
+		return "*" + typeString(t.Elem(), qualify)
+	case *types.Slice:
+		return "[]" + typeString(t.Elem(), qualify)
+	case *types.Array:
+		return "[" + strconv.FormatInt(t.Len(), 10) + "]" + typeString(t.Elem(), qualify)
+	case *types.Map:
+		return "map[" + typeString(t.Key(), qualify) + "]" + typeString(t.Elem(), qualify)
+	case *types.Chan:
+		switch t.Dir() {
+		case types.SendOnly:
+			return "chan<- " + typeString(t.Elem(), qualify)
+		case types.RecvOnly:
+			return "<-chan " + typeString(t.Elem(), qualify)
+		}
+		return "chan " + typeString(t.Elem(), qualify)
+	case *types.Signature:
+		return "func" + signatureString(t, qualify)
+	case *types.Interface:
+		if t.NumMethods() == 0 {
+			return "interface {}"
+		}
+		methods := make([]string, 0, t.NumMethods())
+		for i := 0; i < t.NumMethods(); i++ {
+			m := t.Method(i)
+			methods = append(methods, m.Name()+signatureString(m.Type().(*types.Signature), qualify))
+		}
+		return "interface { " + strings.Join(methods, "; ") + " }"
+	case *types.Struct:
+		if t.NumFields() == 0 {
+			return "struct {}"
+		}
+		fields := make([]string, 0, t.NumFields())
+		for i := 0; i < t.NumFields(); i++ {
+			f := t.Field(i)
+			s := typeString(f.Type(), qualify)
+			if !f.Embedded() {
+				s = f.Name() + " " + s
+			}
+			if tag := t.Tag(i); tag != "" {
+				s += " " + strconv.Quote(tag)
+			}
+			fields = append(fields, s)
+		}
+		return "struct { " + strings.Join(fields, "; ") + " }"
+	}
+	return types.TypeString(t, qualify)
+}
+
+// signatureString formats the parameters and results of function type t like
+// reflect.Type.String does.
+func signatureString(t *types.Signature, qualify func(*types.Package) string) string {
+	params := make([]string, 0, t.Params().Len())
+	for i := 0; i < t.Params().Len(); i++ {
+		typ := t.Params().At(i).Type()
+		if t.Variadic() && i == t.Params().Len()-1 {
+			params = append(params, "..."+typeString(typ.(*types.Slice).Elem(), qualify))
+			continue
+		}
+		params = append(params, typeString(typ, qualify))
+	}
+	s := "(" + strings.Join(params, ", ") + ")"
+
+	switch t.Results().Len() {
+	case 0:
+		return s
+	case 1:
+		return s + " " + typeString(t.Results().At(0).Type(), qualify)
+	}
+	results := make([]string, 0, t.Results().Len())
+	for i := 0; i < t.Results().Len(); i++ {
+		results = append(results, typeString(t.Results().At(i).Type(), qualify))
+	}
+	return s + " (" + strings.Join(results, ", ") + ")"
+}
+
+func (t *typesType) Kind() reflect.Kind {
+	switch u := t.t.Underlying().(type) {
+	case *types.Basic:
+		return basicKinds[u.Kind()]
+	case *types.Pointer:
+		return reflect.Ptr
+	case *types.Slice:
+		return reflect.Slice
+	case *types.Array:
+		return reflect.Array
+	case *types.Map:
+		return reflect.Map
+	case *types.Chan:
+		return reflect.Chan
+	case *types.Signature:
+		return reflect.Func
+	case *types.Struct:
+		return reflect.Struct
+	case *types.Interface:
+		return reflect.Interface
+	}
+	return reflect.Invalid
+}
+
+func (t *typesType) Size() uintptr {
+	return uintptr(t.u.sizes.Sizeof(t.t))
+}
+
+func (t *typesType) Elem() goType {
+	switch u := t.t.Underlying().(type) {
+	case *types.Pointer:
+		return t.u.typ(u.Elem())
+	case *types.Slice:
+		return t.u.typ(u.Elem())
+	case *types.Array:
+		return t.u.typ(u.Elem())
+	case *types.Map:
+		return t.u.typ(u.Elem())
+	case *types.Chan:
+		return t.u.typ(u.Elem())
+	}
+	panic("gen: Elem of invalid type " + t.String())
+}
+
+func (t *typesType) Key() goType {
+	if u, ok := t.t.Underlying().(*types.Map); ok {
+		return t.u.typ(u.Key())
+	}
+	panic("gen: Key of non-map type " + t.String())
+}
+
+func (t *typesType) Len() int {
+	if u, ok := t.t.Underlying().(*types.Array); ok {
+		return int(u.Len())
+	}
+	panic("gen: Len of non-array type " + t.String())
+}
+
+func (t *typesType) NumField() int {
+	if u, ok := t.t.Underlying().(*types.Struct); ok {
+		return u.NumFields()
+	}
+	panic("gen: NumField of non-struct type " + t.String())
+}
+
+func (t *typesType) Field(i int) structField {
+	u := t.t.Underlying().(*types.Struct)
+	v := u.Field(i)
+
+	f := structField{
+		StructField: reflect.StructField{
+			Name:      v.Name(),
+			Tag:       reflect.StructTag(u.Tag(i)),
+			Index:     []int{i},
+			Anonymous: v.Embedded(),
+		},
+		Type: t.u.typ(v.Type()),
+	}
+	if !v.Exported() && v.Pkg() != nil {
+		f.PkgPath = v.Pkg().Path()
+	}
+	return f
+}
+
+func (t *typesType) FieldByName(name string) (structField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Name == name {
+			return f, true
+		}
+	}
+	return structField{}, false
+}
+
+func (t *typesType) NumMethod() int {
+	n := 0
+	mset := types.NewMethodSet(t.t)
+	for i := 0; i < mset.Len(); i++ {
+		if mset.At(i).Obj().Exported() {
+			n++
+		}
+	}
+	return n
+}
+
+// Implements reports whether the type implements interface type u. The methods
+// that are going to be generated for the type are assumed to exist.
+func (t *typesType) Implements(u reflect.Type) bool {
+	var methods []*types.Func
+	for i := 0; i < u.NumMethod(); i++ {
+		m := u.Method(i)
+		if t.hasMarshalerMethod(m.Name) {
+			continue
+		}
+
+		sig, ok := t.u.fromReflect(m.Type).(*types.Signature)
+		if !ok {
+			// the type cannot refer to the types of the method signature
+			return false
+		}
+		methods = append(methods, types.NewFunc(0, nil, m.Name, sig))
+	}
+	return types.Implements(t.t, types.NewInterfaceType(methods, nil).Complete())
+}
+
+// fromReflect returns the go/types type identical to reflect type rt, or nil if
+// the named types used by rt are not imported by the packages of the types.
+func (u *typesUniverse) fromReflect(rt reflect.Type) types.Type {
+	if rt.Name() != "" {
+		if rt.PkgPath() == "" {
+			if obj := types.Universe.Lookup(rt.Name()); obj != nil {
+				return obj.Type()
+			}
+			return nil
+		}
+		pkg := u.packages()[rt.PkgPath()]
+		if pkg == nil {
+			return nil
+		}
+		obj, ok := pkg.Scope().Lookup(rt.Name()).(*types.TypeName)
+		if !ok {
+			return nil
+		}
+		return obj.Type()
+	}
+
+	switch rt.Kind() {
+	case reflect.Ptr:
+		if elem := u.fromReflect(rt.Elem()); elem != nil {
+			return types.NewPointer(elem)
+		}
+	case reflect.Slice:
+		if elem := u.fromReflect(rt.Elem()); elem != nil {
+			return types.NewSlice(elem)
+		}
+	case reflect.Array:
+		if elem := u.fromReflect(rt.Elem()); elem != nil {
+			return types.NewArray(elem, int64(rt.Len()))
+		}
+	case reflect.Map:
+		key, elem := u.fromReflect(rt.Key()), u.fromReflect(rt.Elem())
+		if key != nil && elem != nil {
+			return types.NewMap(key, elem)
+		}
+	case reflect.Interface:
+		if rt.NumMethod() == 0 {
+			return types.NewInterfaceType(nil, nil).Complete()
+		}
+	case reflect.Func:
+		params := make([]*types.Var, 0, rt.NumIn())
+		for i := 0; i < rt.NumIn(); i++ {
+			typ := u.fromReflect(rt.In(i))
+			if typ == nil {
+				return nil
+			}
+			params = append(params, types.NewParam(0, nil, "", typ))
+		}
+		results := make([]*types.Var, 0, rt.NumOut())
+		for i := 0; i < rt.NumOut(); i++ {
+			typ := u.fromReflect(rt.Out(i))
+			if typ == nil {
+				return nil
+			}
+			results = append(results, types.NewParam(0, nil, "", typ))
+		}
+		return types.NewSignatureType(nil, nil, nil, types.NewTuple(params...), types.NewTuple(results...), rt.IsVariadic())
+	}
+	return nil
+}
+
+// packages returns the packages of the types and the packages they import
+// directly or indirectly, by path.
+func (u *typesUniverse) packages() map[string]*types.Package {
+	if u.pkgs != nil {
+		return u.pkgs
+	}
+
+	u.pkgs = map[string]*types.Package{}
+	var add func(p *types.Package)
+	add = func(p *types.Package) {
+		if p == nil || u.pkgs[p.Path()] != nil {
+			return
+		}
+		u.pkgs[p.Path()] = p
+		for _, imp := range p.Imports() {
+			add(imp)
+		}
+	}
+	for obj := range u.marshalers {
+		add(obj.Pkg())
+	}
+	return u.pkgs
+}
+
+// hasMarshalerMethod reports whether the type has the method with the given
+// name that is going to be generated.
+func (t *typesType) hasMarshalerMethod(name string) bool {
+	typ, ptr := t.t, false
+	if p, ok := typ.(*types.Pointer); ok {
+		typ, ptr = p.Elem(), true
+	}
+	if !t.u.hasMarshalers(typ) {
+		return false
+	}
+
+	switch name {
+	case "MarshalEasyJSON":
+		return true
+	case "UnmarshalEasyJSON":
+		return ptr
+	case "MarshalJSON":
+		return !t.u.g.noStdMarshalers
+	case "UnmarshalJSON":
+		return ptr && !t.u.g.noStdMarshalers
+	case "MarshalText":
+		return t.u.g.enums[t.u.typ(typ)] != nil
+	case "UnmarshalText":
+		return ptr && t.u.g.enums[t.u.typ(typ)] != nil
+	}
+	return false
+}
+
+func (t *typesType) PtrTo() goType {
+	return t.u.typ(types.NewPointer(t.t))
+}
@@ -0,0 +1,184 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const typesTestSrc = `package p
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type Item struct {
+	Name  string ` + "`json:\"name,omitempty\"`" + `
+	Data  []byte
+	Sizes [2]uint16
+	Next  *Item
+	Attrs map[string]rune
+	Pairs []Pair[string, Item]
+}
+`
+
+func checkTypesTestSrc(t *testing.T) *types.Package {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", typesTestSrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := (&types.Config{}).Check("example.com/p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+func TestTypesType(t *testing.T) {
+	pkg := checkTypesTestSrc(t)
+	g := NewGenerator("p_easyjson.go")
+	item := g.typesUniverse().typ(pkg.Scope().Lookup("Item").Type())
+
+	for i, test := range []struct {
+		Type    goType
+		Name    string
+		PkgPath string
+		Kind    reflect.Kind
+	}{
+		{item, "Item", "example.com/p", reflect.Struct},
+		{item.Field(0).Type, "string", "", reflect.String},
+		{item.Field(1).Type.Elem(), "uint8", "", reflect.Uint8},
+		{item.Field(2).Type, "", "", reflect.Array},
+		{item.Field(3).Type, "", "", reflect.Ptr},
+		{item.Field(4).Type.Elem(), "int32", "", reflect.Int32},
+		{item.Field(5).Type.Elem(), "Pair[string,example.com/p.Item]", "example.com/p", reflect.Struct},
+	} {
+		if got := test.Type.Name(); got != test.Name {
+			t.Errorf("[%d, %v] Name() = %q; want %q", i, test.Type, got, test.Name)
+		}
+		if got := test.Type.PkgPath(); got != test.PkgPath {
+			t.Errorf("[%d, %v] PkgPath() = %q; want %q", i, test.Type, got, test.PkgPath)
+		}
+		if got := test.Type.Kind(); got != test.Kind {
+			t.Errorf("[%d, %v] Kind() = %v; want %v", i, test.Type, got, test.Kind)
+		}
+	}
+
+	if item.Field(3).Type.Elem() != item {
+		t.Errorf("Elem() of *Item is not Item")
+	}
+	if tags := parseFieldTags(item.Field(0).StructField); tags.name != "name" || !tags.omitEmpty {
+		t.Errorf("parseFieldTags() = %+v; want name and omitempty", tags)
+	}
+}
+
+func TestAddType(t *testing.T) {
+	pkg := checkTypesTestSrc(t)
+	g := NewGenerator("p_easyjson.go")
+	g.SetPkg("p", "example.com/p")
+	g.AddType(pkg.Scope().Lookup("Item").Type())
+
+	var out bytes.Buffer
+	if err := g.Run(&out); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		t.Fatalf("generated code does not parse: %v", err)
+	}
+
+	for _, want := range []string{
+		"func (v Item) MarshalEasyJSON(w *jwriter.Writer)",
+		"func (v *Item) UnmarshalEasyJSON(l *jlexer.Lexer)",
+		"out *Pair[string, Item]",
+		"(*out.Next).UnmarshalEasyJSON(in)",
+		"out.Base64Bytes(in.Data)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, src)
+		}
+	}
+}
+
+const typesStringTestSrc = `package p
+
+import "encoding/json"
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type Text string
+
+func (Text) MarshalJSON() ([]byte, error) { return nil, nil }
+
+type BadText string
+
+func (BadText) MarshalJSON() (string, error) { return "", nil }
+
+type Types struct {
+	Inner  struct{ V string ` + "`json:\"v\"`" + `; Text }
+	Empty  struct{}
+	Any    any
+	Iface  interface{ M(a int, b ...string) (bool, error) }
+	Func   func(json.Marshaler) error
+	Chan   <-chan byte
+	Pairs  map[string][]Pair[string, *Text]
+}
+`
+
+func TestTypesTypeString(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", typesStringTestSrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := (&types.Config{Importer: importer.Default()}).Check("example.com/p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGenerator("p_easyjson.go")
+	g.AddType(pkg.Scope().Lookup("Types").Type())
+	typ := g.typesUniverse().typ(pkg.Scope().Lookup("Types").Type())
+
+	for i, want := range []string{
+		`struct { V string "json:\"v\""; p.Text }`,
+		`struct {}`,
+		`interface {}`,
+		`interface { M(int, ...string) (bool, error) }`,
+		`func(json.Marshaler) error`,
+		`<-chan uint8`,
+		`map[string][]p.Pair[string,*example.com/p.Text]`,
+	} {
+		if got := typ.Field(i).Type.String(); got != want {
+			t.Errorf("[%d] String() = %q; want %q", i, got, want)
+		}
+	}
+
+	marshaler := reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	for _, test := range []struct {
+		name string
+		want bool
+	}{
+		{name: "Text", want: true},
+		{name: "BadText", want: false},
+		{name: "Types", want: true}, // MarshalJSON is generated
+	} {
+		gt := g.typesUniverse().typ(pkg.Scope().Lookup(test.name).Type())
+		if got := gt.Implements(marshaler); got != test.want {
+			t.Errorf("%v.Implements(json.Marshaler) = %v; want %v", test.name, got, test.want)
+		}
+	}
+}
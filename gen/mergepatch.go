@@ -0,0 +1,316 @@
+package gen
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mailru/easyjson"
+)
+
+func (g *Generator) getMergerName(t goType) string {
+	return g.functionName("merge", t)
+}
+
+// mergesFields reports whether a merge patch object is applied to a value of
+// type t field by field: t is a struct type that is decoded by generated code.
+func (g *Generator) mergesFields(t goType) bool {
+	return t.Kind() == reflect.Struct && (g.marshalers[t] || !hasCustomUnmarshaler(t))
+}
+
+// hasMergePatcher reports whether type t has an ApplyMergePatch method that is
+// not generated along with the merge funcs, e.g. a type of another package.
+func (g *Generator) hasMergePatcher(t goType) bool {
+	return !g.marshalers[t] && t.PtrTo().Implements(reflect.TypeOf((*easyjson.MergePatcher)(nil)).Elem())
+}
+
+// mergesMembers reports whether a merge patch object is applied to a map of
+// type t member by member.
+func mergesMembers(t goType) bool {
+	key := t.Key()
+	return key.Kind() == reflect.String &&
+		!key.PtrTo().Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// mergesInterface reports whether a merge patch object is applied to a value of
+// interface type t at runtime: t is an empty interface that is not a union.
+func (g *Generator) mergesInterface(t goType) bool {
+	return t.Kind() == reflect.Interface && t.NumMethod() == 0 && g.unions[t] == nil
+}
+
+// mergesValue reports whether a merge patch object is applied to a value of
+// type t rather than replacing it.
+func (g *Generator) mergesValue(t goType) bool {
+	switch {
+	case g.mergesFields(t), g.hasMergePatcher(t), g.mergesInterface(t):
+		return true
+	case t.Kind() == reflect.Ptr:
+		return g.mergesFields(t.Elem()) || g.hasMergePatcher(t.Elem())
+	case t.Kind() == reflect.Map:
+		return mergesMembers(t)
+	}
+	return false
+}
+
+// genElemMerge generates code declaring tmpVar set to the value of the map
+// element out[key] of type t with the merge patch value applied.
+func (g *Generator) genElemMerge(t goType, out, key, tmpVar string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+	if !g.mergesValue(t) {
+		fmt.Fprintln(g.out, ws+"var "+tmpVar+" "+g.getType(t))
+		return g.genTypeDecoder(t, tmpVar, tags, indent)
+	}
+	fmt.Fprintln(g.out, ws+tmpVar+" := ("+out+")["+key+"]")
+	return g.genValueMerge(t, tmpVar, tags, indent)
+}
+
+// genTypeMerge generates code applying the merge patch value read from the
+// lexer to out of type t: null clears out, see genValueMerge for other values.
+func (g *Generator) genTypeMerge(t goType, out string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+	tmpVar := g.uniqueVarName()
+
+	fmt.Fprintln(g.out, ws+"if in.IsNull() {")
+	fmt.Fprintln(g.out, ws+"  in.Skip()")
+	fmt.Fprintln(g.out, ws+"  var "+tmpVar+" "+g.getType(t))
+	fmt.Fprintln(g.out, ws+"  "+out+" = "+tmpVar)
+	fmt.Fprintln(g.out, ws+"} else {")
+	if err := g.genValueMerge(t, out, tags, indent+1); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, ws+"}")
+	return nil
+}
+
+// genValueMerge generates code applying the non-null merge patch value read
+// from the lexer to out of type t. Objects are merged into structs and maps
+// with string keys, pointers to them are allocated if nil, and into the maps
+// held by empty interfaces. Other values replace out.
+func (g *Generator) genValueMerge(t goType, out string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+
+	switch {
+	case g.mergesFields(t):
+		fn := g.getMergerName(t)
+		g.addType(t)
+
+		fmt.Fprintln(g.out, ws+fn+"(in, "+addressOf(out)+")")
+
+	case g.hasMergePatcher(t):
+		fmt.Fprintln(g.out, ws+"("+out+").ApplyMergePatch(in)")
+
+	case g.mergesInterface(t):
+		fmt.Fprintln(g.out, ws+out+" = easyjson.MergePatchInterface(in, "+out+")")
+
+	case t.Kind() == reflect.Ptr && g.mergesValue(t):
+		fmt.Fprintln(g.out, ws+"if "+out+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = new("+g.getType(t.Elem())+")")
+		fmt.Fprintln(g.out, ws+"}")
+		return g.genValueMerge(t.Elem(), "*"+out, tags, indent)
+
+	case t.Kind() == reflect.Map && mergesMembers(t):
+		tmpVar := g.uniqueVarName()
+
+		fmt.Fprintln(g.out, ws+"if "+out+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = make("+g.getType(t)+")")
+		fmt.Fprintln(g.out, ws+"}")
+		fmt.Fprintln(g.out, ws+"in.Delim('{')")
+		fmt.Fprintln(g.out, ws+"for !in.IsDelim('}') {")
+		fmt.Fprintln(g.out, ws+"  key := "+g.getType(t.Key())+"(in.String())")
+		fmt.Fprintln(g.out, ws+"  in.WantColon()")
+		fmt.Fprintln(g.out, ws+"  in.PushField(string(key), \"\", \"\")")
+		fmt.Fprintln(g.out, ws+"  if in.IsNull() {")
+		fmt.Fprintln(g.out, ws+"    in.Skip()")
+		fmt.Fprintln(g.out, ws+"    delete("+out+", key)")
+		fmt.Fprintln(g.out, ws+"  } else {")
+		if err := g.genElemMerge(t.Elem(), out, "key", tmpVar, tags, indent+2); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"    ("+out+")[key] = "+tmpVar)
+		fmt.Fprintln(g.out, ws+"  }")
+		fmt.Fprintln(g.out, ws+"  in.PopPath()")
+		fmt.Fprintln(g.out, ws+"  in.WantComma()")
+		fmt.Fprintln(g.out, ws+"}")
+		fmt.Fprintln(g.out, ws+"in.Delim('}')")
+
+	default:
+		return g.genTypeDecoder(t, out, tags, indent)
+	}
+	return nil
+}
+
+// genStructFieldMerger generates the case of the key switch applying the merge
+// patch value of field f. If fold is set, the case matches the key
+// case-insensitively.
+func (g *Generator) genStructFieldMerger(t goType, f structField, fold bool) error {
+	jsonName := g.jsonFieldName(t, f)
+	tags := parseFieldTags(f.StructField)
+
+	if tags.omit {
+		return nil
+	}
+
+	if fold {
+		fmt.Fprintf(g.out, "    case strings.EqualFold(key, %q):\n", jsonName)
+	} else {
+		fmt.Fprintf(g.out, "    case %q:\n", jsonName)
+	}
+	fmt.Fprintf(g.out, "      in.PushField(%q, %q, %q)\n", jsonName, goFieldName(t, f), f.Type.String())
+	if err := g.genTypeMerge(f.Type, "out."+f.selector(), tags, 3); err != nil {
+		return err
+	}
+	if err := g.genFieldValidation(t, f, 3); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, "      in.PopPath()")
+	return nil
+}
+
+// genInlineMapMerger generates code applying the merge patch value of the
+// member with the name in key to the inline map field f.
+func (g *Generator) genInlineMapMerger(t goType, f structField) error {
+	out := "out." + f.selector()
+	tmpVar := g.uniqueVarName()
+	// The key refers to the input buffer, so it is copied before being stored.
+	key, storedKey := "key", "string([]byte(key))"
+	if keyType := g.getType(f.Type.Key()); keyType != "string" {
+		key, storedKey = keyType+"("+key+")", keyType+"("+storedKey+")"
+	}
+
+	fmt.Fprintf(g.out, "      in.PushField(key, %q, %q)\n", goFieldName(t, f), f.Type.String())
+	fmt.Fprintln(g.out, "      if in.IsNull() {")
+	fmt.Fprintln(g.out, "        in.Skip()")
+	fmt.Fprintln(g.out, "        delete("+out+", "+key+")")
+	fmt.Fprintln(g.out, "      } else {")
+	fmt.Fprintln(g.out, "        if "+out+" == nil {")
+	fmt.Fprintln(g.out, "          "+out+" = make("+g.getType(f.Type)+")")
+	fmt.Fprintln(g.out, "        }")
+	if err := g.genElemMerge(f.Type.Elem(), out, key, tmpVar, parseFieldTags(f.StructField), 4); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, "        "+out+"["+storedKey+"] = "+tmpVar)
+	fmt.Fprintln(g.out, "      }")
+	fmt.Fprintln(g.out, "      in.PopPath()")
+	return nil
+}
+
+// genMerger generates the func applying JSON merge patches (RFC 7396) to
+// values of struct type t. Unlike the decoder, it keeps the fields missing in
+// the patch and clears the fields that are null in the patch.
+func (g *Generator) genMerger(t goType) error {
+	fname := g.getMergerName(t)
+	typ := g.getType(t)
+
+	fs, err := getStructFields(t)
+	if err != nil {
+		return fmt.Errorf("cannot generate merge patch func for %v: %v", t, err)
+	}
+	inlineMap, hasInlineMap, err := getInlineMapField(t)
+	if err != nil {
+		return fmt.Errorf("cannot generate merge patch func for %v: %v", t, err)
+	}
+
+	fmt.Fprintln(g.out, "func "+fname+"(in *jlexer.Lexer, out *"+typ+") {")
+	fmt.Fprintln(g.out, "  isTopLevel := in.IsStart()")
+	fmt.Fprintln(g.out, "  if in.IsNull() {")
+	fmt.Fprintln(g.out, "    if isTopLevel {")
+	fmt.Fprintln(g.out, "      in.Consumed()")
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "    in.Skip()")
+	fmt.Fprintln(g.out, "    *out = "+typ+"{}")
+	fmt.Fprintln(g.out, "    return")
+	fmt.Fprintln(g.out, "  }")
+
+	g.genInlinePtrsInit(t, "out", true)
+
+	fmt.Fprintln(g.out, "  in.Delim('{')")
+	fmt.Fprintln(g.out, "  for !in.IsDelim('}') {")
+	fmt.Fprintf(g.out, "    key := in.UnsafeFieldName(%v)\n", g.skipMemberNameUnescaping)
+	fmt.Fprintln(g.out, "    in.WantColon()")
+
+	fmt.Fprintln(g.out, "    switch key {")
+	for _, f := range fs {
+		if err := g.genStructFieldMerger(t, f, false); err != nil {
+			return err
+		}
+	}
+	for _, key := range g.discriminatorKeys(t, fs) {
+		fmt.Fprintf(g.out, "    case %q:\n", key)
+		fmt.Fprintln(g.out, "      in.SkipRecursive()")
+	}
+
+	fmt.Fprintln(g.out, "    default:")
+
+	fold := g.caseInsensitive || g.caseInsensitiveTypes[t]
+	if fold {
+		g.imports["strings"] = "strings"
+
+		fmt.Fprintln(g.out, "    switch {")
+		for _, f := range fs {
+			if err := g.genStructFieldMerger(t, f, true); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(g.out, "    default:")
+	}
+
+	if hasInlineMap {
+		if err := g.genInlineMapMerger(t, inlineMap); err != nil {
+			return err
+		}
+	} else if g.disallowUnknownFields {
+		fmt.Fprintln(g.out, `      in.AddError(&jlexer.LexerError{
+          Offset: in.GetPos(),
+          Reason: "unknown field",
+          Data: key,
+      })`)
+	} else if hasUnknownsUnmarshaler(t) {
+		fmt.Fprintln(g.out, "      out.UnmarshalUnknown(in, key)")
+	} else {
+		fmt.Fprintln(g.out, "      in.SkipRecursive()")
+	}
+	if fold {
+		fmt.Fprintln(g.out, "    }")
+	}
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "    in.WantComma()")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "  in.Delim('}')")
+	fmt.Fprintln(g.out, "  if isTopLevel {")
+	fmt.Fprintln(g.out, "    in.Consumed()")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+// genMergePatcher generates the ApplyMergePatch method of struct type t.
+func (g *Generator) genMergePatcher(t goType) error {
+	typ := g.getType(t)
+
+	fmt.Fprintln(g.out, "// ApplyMergePatch supports easyjson.MergePatcher interface")
+	fmt.Fprintln(g.out, "func (v *"+typ+") ApplyMergePatch(l *jlexer.Lexer) {")
+	fmt.Fprintln(g.out, "  "+g.getMergerName(t)+"(l, v)")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+// genGenericMergePatcher generates the ApplyMergePatch method shared by the
+// instantiations of a generic type, see genGenericMarshaler.
+func (g *Generator) genGenericMergePatcher(types []goType) error {
+	g.imports["fmt"] = "fmt"
+	typ := genericReceiver(types[0])
+
+	fmt.Fprintln(g.out, "// ApplyMergePatch supports easyjson.MergePatcher interface")
+	fmt.Fprintln(g.out, "func (v *"+typ+") ApplyMergePatch(l *jlexer.Lexer) {")
+	fmt.Fprintln(g.out, "  switch v := interface{}(v).(type) {")
+	for _, t := range types {
+		fmt.Fprintln(g.out, "  case *"+g.getType(t)+":")
+		fmt.Fprintln(g.out, "    "+g.getMergerName(t)+"(l, v)")
+	}
+	fmt.Fprintln(g.out, "  default:")
+	fmt.Fprintf(g.out, "    l.AddError(fmt.Errorf(%q, v))\n", "easyjson: no ApplyMergePatch generated for %T")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
@@ -0,0 +1,341 @@
+package gen
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mailru/easyjson"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+const pkgOpt = "github.com/mailru/easyjson/opt"
+
+// schemaBuilder collects the JSON Schema definitions of the named struct types
+// referenced from the schema of a single type.
+type schemaBuilder struct {
+	g    *Generator
+	root goType
+	defs map[string]interface{}
+}
+
+// schemaName returns the name of the definition for a named type.
+func (b *schemaBuilder) schemaName(t goType) string {
+	name := pkgPathPrefixRegexp.ReplaceAllString(t.Name(), "")
+	if t.PkgPath() == b.g.pkgPath {
+		return name
+	}
+	return path.Base(t.PkgPath()) + "." + name
+}
+
+// nullable allows null in addition to the types permitted by schema s.
+func nullable(s map[string]interface{}) map[string]interface{} {
+	if typ, ok := s["type"].(string); ok {
+		s["type"] = []string{typ, "null"}
+	}
+	return s
+}
+
+// typeSchema returns the schema of the JSON representation of type t.
+func (b *schemaBuilder) typeSchema(t goType, tags fieldTags) (map[string]interface{}, error) {
+	optionalIface := reflect.TypeOf((*easyjson.Optional)(nil)).Elem()
+	if t.PkgPath() == pkgOpt && t.PtrTo().Implements(optionalIface) {
+		f, ok := t.FieldByName("V")
+		if !ok {
+			return map[string]interface{}{}, nil
+		}
+		s, err := b.typeSchema(f.Type, tags)
+		if err != nil {
+			return nil, err
+		}
+		return nullable(s), nil
+	}
+
+	switch {
+	case isType(t, reflect.TypeOf(time.Time{})):
+		if _, ok := unixTimeFormats[tags.format]; ok {
+			return map[string]interface{}{"type": "integer"}, nil
+		}
+		if tags.format != "" && timeLayout(tags.format) != "time.RFC3339" && timeLayout(tags.format) != "time.RFC3339Nano" {
+			return map[string]interface{}{"type": "string"}, nil
+		}
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case isType(t, reflect.TypeOf(time.Duration(0))) && tags.format == "units":
+		return map[string]interface{}{"type": "string"}, nil
+	case isBigNumber(t):
+		asString, err := bigNumberAsString(t, tags)
+		if err != nil {
+			return nil, err
+		}
+		typ := "number"
+		if isType(t, reflect.TypeOf(big.Int{})) {
+			typ = "integer"
+		}
+		if asString {
+			return map[string]interface{}{"type": "string"}, nil
+		}
+		return map[string]interface{}{"type": typ}, nil
+	case isType(t, reflect.TypeOf(json.RawMessage{})):
+		return map[string]interface{}{}, nil
+	}
+
+	if b.g.enums[t] != nil {
+		e, err := b.g.resolveEnum(t)
+		if err != nil {
+			return nil, err
+		}
+		var values []string
+		for _, c := range e.consts {
+			values = append(values, c.JSON)
+		}
+		return map[string]interface{}{"type": "string", "enum": values}, nil
+	}
+
+	// Easyjson marshalers are assumed to be generated ones, so the schema is
+	// derived from the type itself. Other custom marshalers may produce any
+	// JSON value.
+	if !t.PtrTo().Implements(reflect.TypeOf((*easyjson.Marshaler)(nil)).Elem()) {
+		if t.PtrTo().Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) {
+			return map[string]interface{}{}, nil
+		}
+		if t.PtrTo().Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()) {
+			return map[string]interface{}{"type": "string"}, nil
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if tags.asString {
+			return map[string]interface{}{"type": "string", "enum": []string{"true", "false"}}, nil
+		}
+		return map[string]interface{}{"type": "boolean"}, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if tags.asString {
+			return map[string]interface{}{"type": "string", "pattern": "^-?[0-9]+$"}, nil
+		}
+		return map[string]interface{}{"type": "integer"}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if tags.asString {
+			return map[string]interface{}{"type": "string", "pattern": "^[0-9]+$"}, nil
+		}
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil
+
+	case reflect.Float32, reflect.Float64:
+		if tags.asString {
+			return map[string]interface{}{"type": "string"}, nil
+		}
+		return map[string]interface{}{"type": "number"}, nil
+
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Elem().Name() == "uint8" {
+			s := map[string]interface{}{"type": "string"}
+			if t.Kind() == reflect.Slice && !b.g.simpleBytes {
+				s["contentEncoding"] = "base64"
+			}
+			return s, nil
+		}
+
+		items, err := b.typeSchema(t.Elem(), tags)
+		if err != nil {
+			return nil, err
+		}
+		s := map[string]interface{}{"type": "array", "items": items}
+		if t.Kind() == reflect.Array {
+			s["minItems"] = t.Len()
+			s["maxItems"] = t.Len()
+		}
+		return s, nil
+
+	case reflect.Map:
+		elem, err := b.typeSchema(t.Elem(), tags)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": elem}, nil
+
+	case reflect.Ptr:
+		s, err := b.typeSchema(t.Elem(), tags)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s["$ref"]; ok {
+			return map[string]interface{}{"anyOf": []interface{}{s, map[string]interface{}{"type": "null"}}}, nil
+		}
+		return nullable(s), nil
+
+	case reflect.Interface:
+		if u := b.g.unions[t]; u != nil {
+			return b.unionSchema(t, u)
+		}
+		return map[string]interface{}{}, nil
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		if t == b.root {
+			return map[string]interface{}{"$ref": "#"}, nil
+		}
+
+		name := b.schemaName(t)
+		if _, ok := b.defs[name]; !ok {
+			// Register the definition before building it to support recursive types.
+			b.defs[name] = nil
+			s, err := b.structSchema(t)
+			if err != nil {
+				return nil, err
+			}
+			b.defs[name] = s
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + name}, nil
+	}
+
+	return nil, fmt.Errorf("cannot generate JSON schema for %v", t)
+}
+
+// structSchema returns the schema of a struct type t, describing the fields
+// that the generated encoder outputs.
+func (b *schemaBuilder) structSchema(t goType) (map[string]interface{}, error) {
+	fs, err := getStructFields(t)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate JSON schema for %v: %v", t, err)
+	}
+
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, f := range fs {
+		tags := parseFieldTags(f.StructField)
+		if tags.omit {
+			continue
+		}
+		jsonName := b.g.jsonFieldName(t, f)
+
+		s, err := b.typeSchema(f.Type, tags)
+		if err != nil {
+			return nil, err
+		}
+		c, ok, err := parseConstraints(f.StructField)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate JSON schema for %v: field %v: %v", t, f.Name, err)
+		}
+		if ok {
+			c.schema(s, f.Type)
+		}
+		omitEmpty := (tags.omitEmpty || b.g.omitEmpty) && !tags.noOmitEmpty
+		if (f.Type.Kind() == reflect.Slice || f.Type.Kind() == reflect.Map) && !omitEmpty {
+			// nil slices and maps are encoded as null unless omitted
+			s = nullable(s)
+		}
+		properties[jsonName] = s
+
+		if tags.required {
+			required = append(required, jsonName)
+		}
+	}
+
+	for _, key := range b.g.discriminatorKeys(t, fs) {
+		properties[key] = map[string]interface{}{"type": "string"}
+	}
+
+	s := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	inlineMap, hasInlineMap, err := getInlineMapField(t)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate JSON schema for %v: %v", t, err)
+	}
+	if hasInlineMap {
+		elem, err := b.typeSchema(inlineMap.Type.Elem(), fieldTags{})
+		if err != nil {
+			return nil, err
+		}
+		s["additionalProperties"] = elem
+	} else if b.g.disallowUnknownFields && !hasUnknownsUnmarshaler(t) {
+		s["additionalProperties"] = false
+	}
+	return s, nil
+}
+
+// buildJSONSchema returns the JSON Schema document of type t.
+func (g *Generator) buildJSONSchema(t goType) ([]byte, error) {
+	b := &schemaBuilder{g: g, root: t, defs: map[string]interface{}{}}
+
+	var (
+		s   map[string]interface{}
+		err error
+	)
+	if t.Kind() == reflect.Struct {
+		s, err = b.structSchema(t)
+	} else {
+		s, err = b.typeSchema(t, fieldTags{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s["$schema"] = jsonSchemaDialect
+	s["title"] = b.schemaName(t)
+	if len(b.defs) > 0 {
+		s["$defs"] = b.defs
+	}
+	return json.Marshal(s)
+}
+
+// schemaLiteral returns a Go string literal holding the schema.
+func schemaLiteral(schema []byte) string {
+	if strings.ContainsRune(string(schema), '`') {
+		return strconv.Quote(string(schema))
+	}
+	return "`" + string(schema) + "`"
+}
+
+// genJSONSchema generates the JSONSchema method returning the JSON Schema
+// document of type t.
+func (g *Generator) genJSONSchema(t goType) error {
+	schema, err := g.buildJSONSchema(t)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(g.out, "// JSONSchema returns the JSON Schema document describing the JSON representation of the type")
+	fmt.Fprintln(g.out, "func (v "+g.getType(t)+") JSONSchema() []byte {")
+	fmt.Fprintln(g.out, "  return []byte("+schemaLiteral(schema)+")")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+// genGenericJSONSchema generates the JSONSchema method shared by the
+// instantiations of a generic type, see genGenericMarshaler.
+func (g *Generator) genGenericJSONSchema(types []goType) error {
+	fmt.Fprintln(g.out, "// JSONSchema returns the JSON Schema document describing the JSON representation of the type")
+	fmt.Fprintln(g.out, "func (v "+genericReceiver(types[0])+") JSONSchema() []byte {")
+	fmt.Fprintln(g.out, "  switch interface{}(v).(type) {")
+	for _, t := range types {
+		schema, err := g.buildJSONSchema(t)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, "  case "+g.getType(t)+":")
+		fmt.Fprintln(g.out, "    return []byte("+schemaLiteral(schema)+")")
+	}
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "  return nil")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
@@ -0,0 +1,117 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts maps the names accepted by the format tag to the layout constants of the time
+// package. Any other format of a time.Time field is used as a layout itself.
+var timeLayouts = map[string]string{
+	"rfc3339":     "time.RFC3339",
+	"rfc3339nano": "time.RFC3339Nano",
+	"rfc1123":     "time.RFC1123",
+	"rfc1123z":    "time.RFC1123Z",
+	"rfc822":      "time.RFC822",
+	"rfc822z":     "time.RFC822Z",
+	"rfc850":      "time.RFC850",
+	"ansic":       "time.ANSIC",
+	"unixdate":    "time.UnixDate",
+	"rubydate":    "time.RubyDate",
+	"kitchen":     "time.Kitchen",
+	"datetime":    strconv.Quote("2006-01-02 15:04:05"),
+	"dateonly":    strconv.Quote("2006-01-02"),
+	"timeonly":    strconv.Quote("15:04:05"),
+}
+
+// unixTimeFormats maps the formats encoding a time.Time as a number to the time.Time method
+// returning it and the function converting it back.
+var unixTimeFormats = map[string][2]string{
+	"unix":      {"Unix()", "time.Unix(%v, 0)"},
+	"unixmilli": {"UnixMilli()", "time.UnixMilli(%v)"},
+	"unixmicro": {"UnixMicro()", "time.UnixMicro(%v)"},
+	"unixnano":  {"UnixNano()", "time.Unix(0, %v)"},
+}
+
+// timeLayout returns the Go expression of the layout used for a time.Time with the given format.
+func timeLayout(format string) string {
+	if layout, ok := timeLayouts[strings.ToLower(format)]; ok {
+		return layout
+	}
+	return strconv.Quote(format)
+}
+
+// isTimeType reports whether t is time.Time or time.Duration that is encoded by the generated
+// code itself instead of its marshaler methods. A time.Duration without a format tag but with the
+// string tag is left to the primitive string codecs.
+func isTimeType(t goType, tags fieldTags) bool {
+	if isType(t, reflect.TypeOf(time.Duration(0))) {
+		return tags.format != "" || !tags.asString
+	}
+	return isType(t, reflect.TypeOf(time.Time{}))
+}
+
+// genTimeEncoder generates code that encodes in of type time.Time or time.Duration according to
+// the format tag.
+func (g *Generator) genTimeEncoder(t goType, in string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+
+	if isType(t, reflect.TypeOf(time.Duration(0))) {
+		switch tags.format {
+		case "":
+			fmt.Fprintln(g.out, ws+"out.Int64(int64("+in+"))")
+		case "units":
+			fmt.Fprintln(g.out, ws+"out.Duration("+in+")")
+		default:
+			return fmt.Errorf("unsupported format %q for time.Duration", tags.format)
+		}
+		return nil
+	}
+
+	g.imports["time"] = "time"
+	if unix, ok := unixTimeFormats[tags.format]; ok {
+		fmt.Fprintln(g.out, ws+"out.Int64(("+in+")."+unix[0]+")")
+		return nil
+	}
+	layout := "time.RFC3339Nano"
+	if tags.format != "" {
+		layout = timeLayout(tags.format)
+	}
+	fmt.Fprintln(g.out, ws+"out.Time("+in+", "+layout+")")
+	return nil
+}
+
+// genTimeDecoder generates code that decodes out of type time.Time or time.Duration according to
+// the format tag.
+func (g *Generator) genTimeDecoder(t goType, out string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+
+	if isType(t, reflect.TypeOf(time.Duration(0))) {
+		switch tags.format {
+		case "", "units":
+			fmt.Fprintln(g.out, ws+out+" = in.Duration()")
+		default:
+			return fmt.Errorf("unsupported format %q for time.Duration", tags.format)
+		}
+		return nil
+	}
+
+	g.imports["time"] = "time"
+	if unix, ok := unixTimeFormats[tags.format]; ok {
+		fmt.Fprintln(g.out, ws+"if in.IsNull() {")
+		fmt.Fprintln(g.out, ws+"  in.Skip()")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprintf(g.out, ws+"  "+out+" = "+unix[1]+"\n", "in.Int64()")
+		fmt.Fprintln(g.out, ws+"}")
+		return nil
+	}
+	layout := "time.RFC3339"
+	if tags.format != "" {
+		layout = timeLayout(tags.format)
+	}
+	fmt.Fprintln(g.out, ws+out+" = in.Time("+layout+")")
+	return nil
+}
@@ -0,0 +1,92 @@
+package gen
+
+import (
+	"reflect"
+)
+
+// goType is the subset of reflect.Type used by the generator. It is
+// implemented by reflectType for run-time type information of the types added
+// with Add and by typesType for type-checked sources added with AddType.
+//
+// Implementations must be comparable, so that equal types are equal values.
+type goType interface {
+	Name() string
+	PkgPath() string
+	String() string
+	Kind() reflect.Kind
+	Size() uintptr
+
+	Elem() goType
+	Key() goType
+	Len() int
+
+	NumField() int
+	Field(i int) structField
+	FieldByName(name string) (structField, bool)
+
+	NumMethod() int
+	Implements(u reflect.Type) bool
+
+	PtrTo() goType
+}
+
+// structField describes a struct field. The embedded reflect.StructField has a
+// nil Type for fields of types added with AddType.
+type structField struct {
+	reflect.StructField
+
+	Type goType
+
+	// prefix is the selector of the inline struct field containing the field,
+	// e.g. "Meta." for the fields of `Meta ObjectMeta `json:",inline"``.
+	prefix string
+
+	// inlinePtrs are the selectors of the inline pointer fields containing the
+	// field, e.g. "Meta" for the fields of `Meta *ObjectMeta `json:",inline"``.
+	// The field is absent if any of them is nil.
+	inlinePtrs []string
+
+	// index is the index sequence of the field in the struct, which also goes
+	// through the embedded and inline struct fields containing the field.
+	index []int
+}
+
+// selector returns the selector of the field in the struct, e.g. "Meta.Name".
+func (f structField) selector() string {
+	return f.prefix + f.Name
+}
+
+// reflectType implements goType for run-time type information.
+type reflectType struct {
+	reflect.Type
+}
+
+func (t reflectType) Elem() goType {
+	return reflectType{t.Type.Elem()}
+}
+
+func (t reflectType) Key() goType {
+	return reflectType{t.Type.Key()}
+}
+
+func (t reflectType) Field(i int) structField {
+	f := t.Type.Field(i)
+	return structField{StructField: f, Type: reflectType{f.Type}}
+}
+
+func (t reflectType) FieldByName(name string) (structField, bool) {
+	f, ok := t.Type.FieldByName(name)
+	if !ok {
+		return structField{}, false
+	}
+	return structField{StructField: f, Type: reflectType{f.Type}}, true
+}
+
+func (t reflectType) PtrTo() goType {
+	return reflectType{reflect.PtrTo(t.Type)}
+}
+
+// isType reports whether t is the named type given by rt.
+func isType(t goType, rt reflect.Type) bool {
+	return t.Name() == rt.Name() && t.PkgPath() == rt.PkgPath()
+}
@@ -0,0 +1,12 @@
+//go:build go1.22
+// +build go1.22
+
+package gen
+
+import "go/types"
+
+// unalias returns the type that alias type t refers to, or t itself if it is
+// not an alias.
+func unalias(t types.Type) types.Type {
+	return types.Unalias(t)
+}
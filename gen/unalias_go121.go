@@ -0,0 +1,11 @@
+//go:build !go1.22
+// +build !go1.22
+
+package gen
+
+import "go/types"
+
+// unalias returns t: go/types does not represent aliases before Go 1.22.
+func unalias(t types.Type) types.Type {
+	return t
+}
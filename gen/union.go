@@ -0,0 +1,202 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// union describes an interface type encoded as a discriminated union: an
+// object with the members of the variant type held by the interface and a
+// discriminator member telling the variant, e.g. {"type":"circle","r":1}.
+type union struct {
+	key      string
+	variants []unionVariant // Sorted by value.
+}
+
+// unionVariant is a variant type of a union and its discriminator value.
+type unionVariant struct {
+	value string
+	t     goType
+}
+
+// AddUnion requests to encode and decode the values of the interface type of
+// obj, a pointer to the interface, as a discriminated union. The discriminator
+// member with the given key holds one of the keys of variants, which map the
+// discriminator values to pointers to the variant types. The variant types
+// must be structs or pointers to structs.
+func (g *Generator) AddUnion(obj interface{}, key string, variants map[string]interface{}) {
+	rt := reflect.TypeOf(obj)
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	vs := make(map[string]goType, len(variants))
+	for value, v := range variants {
+		vs[value] = reflectType{reflect.TypeOf(v).Elem()}
+	}
+	g.addUnion(reflectType{rt}, key, vs)
+}
+
+func (g *Generator) addUnion(t goType, key string, variants map[string]goType) {
+	u := &union{key: key}
+	for value, v := range variants {
+		u.variants = append(u.variants, unionVariant{value: value, t: v})
+	}
+	sort.Slice(u.variants, func(i, j int) bool { return u.variants[i].value < u.variants[j].value })
+	g.unions[t] = u
+}
+
+// variantStruct returns the struct type of a union variant type.
+func variantStruct(t goType) goType {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// discriminatorKeys returns the discriminator keys of the unions that struct
+// t is a variant of, except the keys matching the JSON names of fields fs.
+func (g *Generator) discriminatorKeys(t goType, fs []structField) []string {
+	fields := map[string]bool{}
+	for _, f := range fs {
+		if !parseFieldTags(f.StructField).omit {
+			fields[g.jsonFieldName(t, f)] = true
+		}
+	}
+
+	var keys []string
+	for _, u := range g.unions {
+		for _, v := range u.variants {
+			if variantStruct(v.t) == t && !fields[u.key] {
+				fields[u.key] = true
+				keys = append(keys, u.key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkUnion checks that union type t can be encoded and decoded.
+func checkUnion(t goType, u *union) error {
+	if t.Kind() != reflect.Interface {
+		return fmt.Errorf("cannot generate encoder/decoder for union %v, not an interface type", t)
+	}
+	for _, v := range u.variants {
+		if variantStruct(v.t).Kind() != reflect.Struct {
+			return fmt.Errorf("cannot generate encoder/decoder for union %v: variant %v is not a struct or a pointer to a struct", t, v.t)
+		}
+	}
+	return nil
+}
+
+func (g *Generator) genUnionDecoder(t goType) error {
+	u := g.unions[t]
+	if err := checkUnion(t, u); err != nil {
+		return err
+	}
+
+	fname := g.getDecoderName(t)
+	typ := g.getType(t)
+
+	fmt.Fprintln(g.out, "func "+fname+"(in *jlexer.Lexer, out *"+typ+") {")
+	fmt.Fprintln(g.out, "  isTopLevel := in.IsStart()")
+	fmt.Fprintln(g.out, "  if in.IsNull() {")
+	fmt.Fprintln(g.out, "    in.Skip()")
+	fmt.Fprintln(g.out, "    *out = nil")
+	fmt.Fprintln(g.out, "  } else {")
+	fmt.Fprintf(g.out, "    switch kind, found := in.LookupString(%q); {\n", u.key)
+	fmt.Fprintln(g.out, "    case !found:")
+	fmt.Fprintf(g.out, `      in.AddError(&jlexer.LexerError{
+          Offset: in.GetPos(),
+          Reason: "missing union discriminator",
+          Data: %q,
+      })
+`, u.key)
+	fmt.Fprintln(g.out, "      in.SkipRecursive()")
+	for _, v := range u.variants {
+		tmpVar := g.uniqueVarName()
+
+		fmt.Fprintf(g.out, "    case kind == %q:\n", v.value)
+		fmt.Fprintln(g.out, "      var "+tmpVar+" "+g.getType(v.t))
+		if err := g.genTypeDecoder(v.t, tmpVar, fieldTags{}, 3); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, "      *out = "+tmpVar)
+	}
+	fmt.Fprintln(g.out, "    default:")
+	fmt.Fprintln(g.out, `      in.AddError(&jlexer.LexerError{
+          Offset: in.GetPos(),
+          Reason: "unknown union discriminator value",
+          Data: kind,
+      })`)
+	fmt.Fprintln(g.out, "      in.SkipRecursive()")
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "  if isTopLevel {")
+	fmt.Fprintln(g.out, "    in.Consumed()")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+func (g *Generator) genUnionEncoder(t goType) error {
+	u := g.unions[t]
+	if err := checkUnion(t, u); err != nil {
+		return err
+	}
+	g.imports["fmt"] = "fmt"
+
+	fname := g.getEncoderName(t)
+	typ := g.getType(t)
+
+	fmt.Fprintln(g.out, "func "+fname+"(out *jwriter.Writer, in "+typ+") {")
+	fmt.Fprintln(g.out, "  switch in := in.(type) {")
+	fmt.Fprintln(g.out, "  case nil:")
+	fmt.Fprintln(g.out, `    out.RawString("null")`)
+	for _, v := range u.variants {
+		fmt.Fprintln(g.out, "  case "+g.getType(v.t)+":")
+		if v.t.Kind() == reflect.Ptr {
+			fmt.Fprintln(g.out, "    if in == nil {")
+			fmt.Fprintln(g.out, `      out.RawString("null")`)
+			fmt.Fprintln(g.out, "      break")
+			fmt.Fprintln(g.out, "    }")
+		}
+		if err := g.genStructObjectEncoder(variantStruct(v.t), u.key, v.value); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(g.out, "  default:")
+	fmt.Fprintln(g.out, "    if out.Error == nil {")
+	fmt.Fprintf(g.out, "      out.Error = fmt.Errorf(%q, in)\n", "easyjson: %T is not a variant of union "+typ)
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "}")
+	return nil
+}
+
+// unionSchema returns the JSON Schema of union type t: one of the schemas of
+// the variants with the discriminator member, or null for a nil interface.
+func (b *schemaBuilder) unionSchema(t goType, u *union) (map[string]interface{}, error) {
+	if err := checkUnion(t, u); err != nil {
+		return nil, err
+	}
+
+	variants := make([]interface{}, 0, len(u.variants)+1)
+	for _, v := range u.variants {
+		s, err := b.typeSchema(variantStruct(v.t), fieldTags{})
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, map[string]interface{}{
+			"allOf": []interface{}{s},
+			"properties": map[string]interface{}{
+				u.key: map[string]interface{}{"const": v.value},
+			},
+			"required": []string{u.key},
+		})
+	}
+	variants = append(variants, map[string]interface{}{"type": "null"})
+	return map[string]interface{}{"oneOf": variants}, nil
+}
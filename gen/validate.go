@@ -0,0 +1,318 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fieldConstraints are the constraints of a field value declared in the
+// jsonschema tag, e.g. `jsonschema:"minLength=1,maxLength=10,pattern=^[a-z]+$"`.
+type fieldConstraints struct {
+	minimum, maximum     string // Number literals.
+	minLength, maxLength int    // -1 if not set.
+	minItems, maxItems   int    // -1 if not set.
+	pattern              string
+	enum                 []string
+}
+
+// splitConstraints splits the jsonschema tag on commas not escaped with a backslash.
+func splitConstraints(tag string) []string {
+	var (
+		items []string
+		item  []byte
+	)
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			item = append(item, ',')
+			i++
+		case tag[i] == ',':
+			items = append(items, string(item))
+			item = item[:0]
+		default:
+			item = append(item, tag[i])
+		}
+	}
+	return append(items, string(item))
+}
+
+// parseConstraints parses the jsonschema tag of field f.
+func parseConstraints(f reflect.StructField) (fieldConstraints, bool, error) {
+	c := fieldConstraints{minLength: -1, maxLength: -1, minItems: -1, maxItems: -1}
+
+	tag, ok := f.Tag.Lookup("jsonschema")
+	if !ok || tag == "" {
+		return c, false, nil
+	}
+
+	for _, item := range splitConstraints(tag) {
+		i := strings.IndexByte(item, '=')
+		if i < 0 {
+			return c, false, fmt.Errorf("invalid constraint %q, expected key=value", item)
+		}
+		key, value := item[:i], item[i+1:]
+
+		var err error
+		switch key {
+		case "minimum", "min":
+			c.minimum, err = value, checkNumber(value)
+		case "maximum", "max":
+			c.maximum, err = value, checkNumber(value)
+		case "minLength":
+			c.minLength, err = strconv.Atoi(value)
+		case "maxLength":
+			c.maxLength, err = strconv.Atoi(value)
+		case "minItems":
+			c.minItems, err = strconv.Atoi(value)
+		case "maxItems":
+			c.maxItems, err = strconv.Atoi(value)
+		case "pattern":
+			c.pattern = value
+			_, err = regexp.Compile(value)
+		case "enum":
+			c.enum = append(c.enum, value)
+		default:
+			err = fmt.Errorf("unknown constraint")
+		}
+		if err != nil {
+			return c, false, fmt.Errorf("invalid constraint %q: %v", item, err)
+		}
+	}
+	return c, true, nil
+}
+
+func checkNumber(s string) error {
+	_, err := strconv.ParseFloat(s, 64)
+	return err
+}
+
+// numberLiteral returns the literal of number s for comparisons with values of
+// type t.
+func numberLiteral(t goType, s string) (string, error) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(s, 10, int(t.Size())*8)
+		return strconv.FormatInt(v, 10), err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v, err := strconv.ParseUint(s, 10, int(t.Size())*8)
+		return strconv.FormatUint(v, 10), err
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(s, int(t.Size())*8)
+		return strconv.FormatFloat(v, 'g', -1, int(t.Size())*8), err
+	}
+	return "", fmt.Errorf("%v is not a number type", t)
+}
+
+// patternVar returns the name of the package variable holding the compiled
+// pattern, declared by genPatternVars.
+func (g *Generator) patternVar(pattern string) string {
+	for i, p := range g.patterns {
+		if p == pattern {
+			return g.patternVarName(i)
+		}
+	}
+	g.patterns = append(g.patterns, pattern)
+	return g.patternVarName(len(g.patterns) - 1)
+}
+
+func (g *Generator) patternVarName(i int) string {
+	return fmt.Sprintf("easyjson%sPattern%d", g.hashString, i)
+}
+
+// genPatternVars generates the variables holding the compiled patterns used by
+// the decoders.
+func (g *Generator) genPatternVars() {
+	if len(g.patterns) == 0 {
+		return
+	}
+	g.imports["regexp"] = "regexp"
+
+	fmt.Fprintln(g.out, "var (")
+	for i, p := range g.patterns {
+		fmt.Fprintf(g.out, "  %s = regexp.MustCompile(%q)\n", g.patternVarName(i), p)
+	}
+	fmt.Fprintln(g.out, ")")
+}
+
+// genFieldValidation generates code checking the decoded value of field f
+// against the constraints declared in its jsonschema tag. Violations are
+// reported with Lexer.AddValidationError.
+func (g *Generator) genFieldValidation(t goType, f structField, indent int) error {
+	c, ok, err := parseConstraints(f.StructField)
+	if err != nil {
+		return fmt.Errorf("field %v: %v", goFieldName(t, f), err)
+	}
+	if !ok {
+		return nil
+	}
+
+	ws := strings.Repeat("  ", indent)
+	g.imports["errors"] = "errors"
+
+	v, typ := "out."+f.selector(), f.Type
+	closing := 0
+	if typ.Kind() == reflect.Ptr {
+		fmt.Fprintln(g.out, ws+"if "+v+" != nil {")
+		v, typ = "(*"+v+")", typ.Elem()
+		closing++
+	}
+	if typ.PkgPath() == pkgOpt {
+		if vf, ok := typ.FieldByName("V"); ok {
+			fmt.Fprintln(g.out, ws+"if "+v+".Defined {")
+			v, typ = v+".V", vf.Type
+			closing++
+		}
+	}
+
+	check := func(cond, msg string) {
+		fmt.Fprintln(g.out, ws+"if "+cond+" {")
+		fmt.Fprintf(g.out, ws+"  in.AddValidationError(errors.New(%q))\n", msg)
+		fmt.Fprintln(g.out, ws+"}")
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if c.minLength >= 0 || c.maxLength >= 0 || c.pattern != "" || c.minItems >= 0 || c.maxItems >= 0 {
+			return fmt.Errorf("field %v: only minimum, maximum and enum constraints apply to numbers", goFieldName(t, f))
+		}
+		if c.minimum != "" {
+			lit, err := numberLiteral(typ, c.minimum)
+			if err != nil {
+				return fmt.Errorf("field %v: invalid minimum: %v", goFieldName(t, f), err)
+			}
+			check(v+" < "+lit, "value is less than minimum "+c.minimum)
+		}
+		if c.maximum != "" {
+			lit, err := numberLiteral(typ, c.maximum)
+			if err != nil {
+				return fmt.Errorf("field %v: invalid maximum: %v", goFieldName(t, f), err)
+			}
+			check(v+" > "+lit, "value is greater than maximum "+c.maximum)
+		}
+		if len(c.enum) > 0 {
+			lits := make([]string, 0, len(c.enum))
+			for _, e := range c.enum {
+				lit, err := numberLiteral(typ, e)
+				if err != nil {
+					return fmt.Errorf("field %v: invalid enum value: %v", goFieldName(t, f), err)
+				}
+				lits = append(lits, lit)
+			}
+			g.genEnumCheck(v, lits, c.enum, ws)
+		}
+
+	case reflect.String:
+		if c.minimum != "" || c.maximum != "" || c.minItems >= 0 || c.maxItems >= 0 {
+			return fmt.Errorf("field %v: only minLength, maxLength, pattern and enum constraints apply to strings", goFieldName(t, f))
+		}
+		if c.minLength >= 0 || c.maxLength >= 0 {
+			g.imports["unicode/utf8"] = "utf8"
+		}
+		if c.minLength >= 0 {
+			check(fmt.Sprintf("utf8.RuneCountInString(string(%s)) < %d", v, c.minLength),
+				fmt.Sprintf("length is less than minLength %d", c.minLength))
+		}
+		if c.maxLength >= 0 {
+			check(fmt.Sprintf("utf8.RuneCountInString(string(%s)) > %d", v, c.maxLength),
+				fmt.Sprintf("length is greater than maxLength %d", c.maxLength))
+		}
+		if c.pattern != "" {
+			check("!"+g.patternVar(c.pattern)+".MatchString(string("+v+"))", "value does not match pattern "+c.pattern)
+		}
+		if len(c.enum) > 0 {
+			lits := make([]string, 0, len(c.enum))
+			for _, e := range c.enum {
+				lits = append(lits, strconv.Quote(e))
+			}
+			g.genEnumCheck(v, lits, c.enum, ws)
+		}
+
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if c.minimum != "" || c.maximum != "" || c.minLength >= 0 || c.maxLength >= 0 || c.pattern != "" || len(c.enum) > 0 {
+			return fmt.Errorf("field %v: only minItems and maxItems constraints apply to arrays", goFieldName(t, f))
+		}
+		if c.minItems >= 0 {
+			check(fmt.Sprintf("len(%s) < %d", v, c.minItems), fmt.Sprintf("number of items is less than minItems %d", c.minItems))
+		}
+		if c.maxItems >= 0 {
+			check(fmt.Sprintf("len(%s) > %d", v, c.maxItems), fmt.Sprintf("number of items is greater than maxItems %d", c.maxItems))
+		}
+
+	default:
+		return fmt.Errorf("field %v: constraints are not supported for type %v", goFieldName(t, f), f.Type)
+	}
+
+	for ; closing > 0; closing-- {
+		fmt.Fprintln(g.out, ws+"}")
+	}
+	return nil
+}
+
+// genEnumCheck generates code checking that v is one of the literals.
+func (g *Generator) genEnumCheck(v string, lits, enum []string, ws string) {
+	// Remove duplicates, e.g. "1" for "1" and "1.0", not allowed in a switch.
+	seen := map[string]bool{}
+	unique := lits[:0:0]
+	for _, lit := range lits {
+		if !seen[lit] {
+			seen[lit] = true
+			unique = append(unique, lit)
+		}
+	}
+	lits = unique
+
+	fmt.Fprintln(g.out, ws+"switch "+v+" {")
+	fmt.Fprintln(g.out, ws+"case "+strings.Join(lits, ", ")+":")
+	fmt.Fprintln(g.out, ws+"default:")
+	fmt.Fprintf(g.out, ws+"  in.AddValidationError(errors.New(%q))\n", "value is not one of "+strings.Join(enum, ", "))
+	fmt.Fprintln(g.out, ws+"}")
+}
+
+// schema adds the constraints to the JSON Schema s of a field value of type t.
+func (c fieldConstraints) schema(s map[string]interface{}, t goType) {
+	if c.minimum != "" {
+		s["minimum"] = json.Number(c.minimum)
+	}
+	if c.maximum != "" {
+		s["maximum"] = json.Number(c.maximum)
+	}
+	if c.minLength >= 0 {
+		s["minLength"] = c.minLength
+	}
+	if c.maxLength >= 0 {
+		s["maxLength"] = c.maxLength
+	}
+	if c.minItems >= 0 {
+		s["minItems"] = c.minItems
+	}
+	if c.maxItems >= 0 {
+		s["maxItems"] = c.maxItems
+	}
+	if c.pattern != "" {
+		s["pattern"] = c.pattern
+	}
+	if len(c.enum) > 0 {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if f, ok := t.FieldByName("V"); ok && t.PkgPath() == pkgOpt {
+			t = f.Type
+		}
+
+		enum := make([]interface{}, 0, len(c.enum))
+		for _, e := range c.enum {
+			if t.Kind() == reflect.String {
+				enum = append(enum, e)
+			} else {
+				enum = append(enum, json.Number(e))
+			}
+		}
+		s["enum"] = enum
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/mailru/easyjson
 
-go 1.12
+go 1.18
 
 require github.com/josharian/intern v1.0.0
@@ -3,7 +3,6 @@ package easyjson
 
 import (
 	"io"
-	"io/ioutil"
 	"net/http"
 	"strconv"
 	"unsafe"
@@ -43,6 +42,11 @@ type UnknownsMarshaler interface {
 	MarshalUnknowns(w *jwriter.Writer, first bool)
 }
 
+// MergePatcher provides a method to apply a JSON merge patch (RFC 7396) to the object
+type MergePatcher interface {
+	ApplyMergePatch(in *jlexer.Lexer)
+}
+
 func isNilInterface(i interface{}) bool {
 	return (*[2]uintptr)(unsafe.Pointer(&i))[1] == 0
 }
@@ -59,6 +63,19 @@ func Marshal(v Marshaler) ([]byte, error) {
 	return w.BuildBytes()
 }
 
+// MarshalIndent is like Marshal but applies indentation to format the output. Each JSON
+// element in the output begins on a new line beginning with prefix followed by one or more
+// copies of indent according to the nesting level.
+func MarshalIndent(v Marshaler, prefix, indent string) ([]byte, error) {
+	if isNilInterface(v) {
+		return nullBytes, nil
+	}
+
+	w := jwriter.Writer{Prefix: prefix, Indent: indent}
+	v.MarshalEasyJSON(&w)
+	return w.BuildBytes()
+}
+
 // MarshalToWriter marshals the data to an io.Writer.
 func MarshalToWriter(v Marshaler, w io.Writer) (written int, err error) {
 	if isNilInterface(v) {
@@ -102,13 +119,39 @@ func Unmarshal(data []byte, v Unmarshaler) error {
 	return l.Error()
 }
 
-// UnmarshalFromReader reads all the data in the reader and decodes as JSON into the object.
+// UnmarshalFromReader decodes JSON from the reader into the object. The data is read
+// incrementally as it is being decoded, so the whole input is not kept in memory.
 func UnmarshalFromReader(r io.Reader, v Unmarshaler) error {
-	data, err := ioutil.ReadAll(r)
-	if err != nil {
-		return err
-	}
-	l := jlexer.Lexer{Data: data}
+	l := jlexer.Lexer{Reader: r}
+	v.UnmarshalEasyJSON(&l)
+	return l.Error()
+}
+
+// UnmarshalProjected decodes the values of the JSON in data at the paths of the projection
+// into the object, skipping the rest of the input.
+func UnmarshalProjected(data []byte, v Unmarshaler, p *jlexer.Projection) error {
+	l := jlexer.Lexer{Data: data, Projection: p}
 	v.UnmarshalEasyJSON(&l)
 	return l.Error()
 }
+
+// MergePatch applies the JSON merge patch (RFC 7396) in data to the object: the members of
+// the patch replace the fields of the object, null members clear them and objects are merged
+// into struct and map fields recursively.
+func MergePatch(data []byte, v MergePatcher) error {
+	l := jlexer.Lexer{Data: data}
+	v.ApplyMergePatch(&l)
+	return l.Error()
+}
+
+// MarshalMasked returns the fields of the object at the paths of the mask as a single byte slice.
+// Only the encoders generated with the -field_mask option leave out the other fields.
+func MarshalMasked(v Marshaler, m *jwriter.FieldMask) ([]byte, error) {
+	if isNilInterface(v) {
+		return nullBytes, nil
+	}
+
+	w := jwriter.Writer{Mask: m}
+	v.MarshalEasyJSON(&w)
+	return w.BuildBytes()
+}
@@ -0,0 +1,93 @@
+package easyjson
+
+import "github.com/mailru/easyjson/jlexer"
+
+// DeepCopyInterface returns a deep copy of v, a value decoded from JSON into an interface{}: the
+// map[string]interface{}, []interface{} and *OrderedMap values it holds are copied recursively.
+// Other values, e.g. numbers and strings, are copied by assignment.
+func DeepCopyInterface(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			out[key] = DeepCopyInterface(value)
+		}
+		return out
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = DeepCopyInterface(value)
+		}
+		return out
+	case *OrderedMap:
+		if v == nil {
+			return v
+		}
+		out := &OrderedMap{}
+		for _, key := range v.keys {
+			out.Set(key, DeepCopyInterface(v.values[key]))
+		}
+		return out
+	}
+	return v
+}
+
+// MergePatchInterface applies the JSON merge patch (RFC 7396) value read from the lexer to v, a
+// value decoded from JSON into an interface{}, and returns the result. Objects are merged into
+// map[string]interface{} and *OrderedMap values recursively and the members that are null in
+// the patch are removed. Other values replace v.
+func MergePatchInterface(l *jlexer.Lexer, v interface{}) interface{} {
+	if !l.Ok() {
+		return v
+	}
+	if !l.IsDelim('{') {
+		return l.Interface()
+	}
+
+	if m, ok := v.(*OrderedMap); ok && m != nil {
+		l.Delim('{')
+		for !l.IsDelim('}') {
+			key := l.String()
+			l.WantColon()
+			l.PushField(key, "", "")
+			if l.IsNull() {
+				l.Skip()
+				m.Delete(key)
+			} else {
+				value, _ := m.Get(key)
+				m.Set(key, MergePatchInterface(l, value))
+			}
+			l.PopPath()
+			l.WantComma()
+		}
+		l.Delim('}')
+		return m
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok || m == nil {
+		m = map[string]interface{}{}
+	}
+	l.Delim('{')
+	for !l.IsDelim('}') {
+		key := l.String()
+		l.WantColon()
+		l.PushField(key, "", "")
+		if l.IsNull() {
+			l.Skip()
+			delete(m, key)
+		} else {
+			m[key] = MergePatchInterface(l, m[key])
+		}
+		l.PopPath()
+		l.WantComma()
+	}
+	l.Delim('}')
+	return m
+}
@@ -0,0 +1,75 @@
+package easyjson
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mailru/easyjson/jlexer"
+)
+
+func TestDeepCopyInterface(t *testing.T) {
+	om := &OrderedMap{}
+	om.Set("b", []interface{}{1.0})
+	om.Set("a", map[string]interface{}{"c": "d"})
+	orig := map[string]interface{}{
+		"list":    []interface{}{1.0, map[string]interface{}{"x": true}, nil},
+		"ordered": om,
+		"n":       "s",
+		"empty":   map[string]interface{}(nil),
+	}
+
+	cp := DeepCopyInterface(orig).(map[string]interface{})
+	if !reflect.DeepEqual(cp, orig) {
+		t.Fatalf("DeepCopyInterface() = %v; want %v", cp, orig)
+	}
+
+	cp["n"] = "t"
+	cp["list"].([]interface{})[1].(map[string]interface{})["x"] = false
+	cpOm := cp["ordered"].(*OrderedMap)
+	b, _ := cpOm.Get("b")
+	b.([]interface{})[0] = 2.0
+	cpOm.Set("e", "f")
+
+	if orig["n"] != "s" || orig["list"].([]interface{})[1].(map[string]interface{})["x"] != true {
+		t.Errorf("original after modifying the copy = %v", orig)
+	}
+	if b, _ := om.Get("b"); b.([]interface{})[0] != 1.0 || om.Len() != 2 {
+		t.Errorf("ordered map after modifying the copy = %v", om.Keys())
+	}
+	if got := DeepCopyInterface(nil); got != nil {
+		t.Errorf("DeepCopyInterface(nil) = %v; want nil", got)
+	}
+}
+
+func TestMergePatchInterface(t *testing.T) {
+	om := &OrderedMap{}
+	om.Set("a", 1.0)
+	om.Set("b", map[string]interface{}{"c": 1.0, "d": 2.0})
+
+	for _, test := range []struct {
+		Value interface{}
+		Patch string
+		Want  interface{}
+	}{
+		{Value: map[string]interface{}{"a": 1.0}, Patch: `{"a":null,"b":{"c":null}}`, Want: map[string]interface{}{"b": map[string]interface{}{}}},
+		{Value: "s", Patch: `{"a":1}`, Want: map[string]interface{}{"a": 1.0}},
+		{Value: map[string]interface{}{"a": 1.0}, Patch: `[{"a":null}]`, Want: []interface{}{map[string]interface{}{"a": nil}}},
+		{Value: nil, Patch: `"s"`, Want: "s"},
+		{Value: om, Patch: `{"a":null,"b":{"c":null},"e":true}`, Want: om},
+	} {
+		l := jlexer.Lexer{Data: []byte(test.Patch)}
+		got := MergePatchInterface(&l, test.Value)
+		if err := l.Error(); err != nil {
+			t.Errorf("MergePatchInterface(%v, %s) error: %v", test.Value, test.Patch, err)
+		} else if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("MergePatchInterface(%v, %s) = %v; want %v", test.Value, test.Patch, got, test.Want)
+		}
+	}
+
+	if keys := om.Keys(); !reflect.DeepEqual(keys, []string{"b", "e"}) {
+		t.Errorf("ordered map keys = %q; want [b e]", keys)
+	}
+	if b, _ := om.Get("b"); !reflect.DeepEqual(b, map[string]interface{}{"d": 2.0}) {
+		t.Errorf("ordered map b = %v; want map[d:2]", b)
+	}
+}
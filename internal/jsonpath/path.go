@@ -0,0 +1,68 @@
+// Package jsonpath parses the JSON paths of the projections of jlexer and the field masks of
+// jwriter.
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse returns the object member names of the path, e.g. "$.id", "author.name" or
+// "$.tags[*].name". Member names are in dot notation or quoted in brackets (e.g.
+// `$["first name"]`), "[*]" elements are skipped and the path may start with "$".
+func Parse(path string) ([]string, error) {
+	s := strings.TrimPrefix(path, "$")
+	var names []string
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, "[*]"):
+			s = s[len("[*]"):]
+
+		case strings.HasPrefix(s, `["`):
+			end := closingQuote(s[1:]) + 1
+			if end == 0 || !strings.HasPrefix(s[end+1:], "]") {
+				return nil, fmt.Errorf("invalid path %q: unterminated member name", path)
+			}
+			name, err := strconv.Unquote(s[1 : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %v", path, err)
+			}
+			names = append(names, name)
+			s = s[end+2:]
+
+		case s[0] == '[':
+			return nil, fmt.Errorf("invalid path %q: only [*] and quoted member names are allowed in brackets", path)
+
+		default:
+			if s[0] == '.' {
+				s = s[1:]
+			} else if len(names) > 0 || len(s) < len(path) {
+				return nil, fmt.Errorf("invalid path %q: expected '.' or '['", path)
+			}
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid path %q: empty member name", path)
+			}
+			names = append(names, s[:end])
+			s = s[end:]
+		}
+	}
+	return names, nil
+}
+
+// closingQuote returns the index of the quote closing the quoted string s, -1 if there is none.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,77 @@
+package jsonpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		Path  string
+		Names []string
+		Err   bool
+	}{
+		{Path: "", Names: nil},
+		{Path: "$", Names: nil},
+		{Path: "id", Names: []string{"id"}},
+		{Path: "$.author.name", Names: []string{"author", "name"}},
+		{Path: "$.tags[*].name", Names: []string{"tags", "name"}},
+		{Path: `$["first name"].x`, Names: []string{"first name", "x"}},
+		{Path: `["a\"]b"]`, Names: []string{`a"]b`}},
+		{Path: "$id", Err: true},
+		{Path: "a..b", Err: true},
+		{Path: "a[0]", Err: true},
+		{Path: `a["b`, Err: true},
+		{Path: "a[*]b", Err: true},
+	} {
+		names, err := Parse(test.Path)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Parse(%q) = %q; want error", test.Path, names)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", test.Path, err)
+		} else if !reflect.DeepEqual(names, test.Names) {
+			t.Errorf("Parse(%q) = %q; want %q", test.Path, names, test.Names)
+		}
+	}
+}
+
+func TestNewTree(t *testing.T) {
+	for _, test := range []struct {
+		Paths []string
+		Want  *Node
+	}{
+		{Paths: nil, Want: &Node{Members: map[string]*Node{}}},
+		{Paths: []string{"id", "$"}, Want: nil},
+		{
+			Paths: []string{"id", "author.name", "tags[*].name", "author.email"},
+			Want: &Node{Members: map[string]*Node{
+				"id":     nil,
+				"author": {Members: map[string]*Node{"name": nil, "email": nil}},
+				"tags":   {Members: map[string]*Node{"name": nil}},
+			}},
+		},
+		{
+			Paths: []string{"author.name", "author"},
+			Want:  &Node{Members: map[string]*Node{"author": nil}},
+		},
+		{
+			Paths: []string{"author", "author.name"},
+			Want:  &Node{Members: map[string]*Node{"author": nil}},
+		},
+	} {
+		got, err := NewTree(test.Paths...)
+		if err != nil {
+			t.Errorf("NewTree(%q) error: %v", test.Paths, err)
+		} else if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("NewTree(%q) = %+v; want %+v", test.Paths, got, test.Want)
+		}
+	}
+
+	if _, err := NewTree("a", "b["); err == nil {
+		t.Errorf("NewTree() error = nil; want an error")
+	}
+}
@@ -0,0 +1,47 @@
+package jsonpath
+
+// Node is a node of the tree of a set of paths. It holds the members of an object that are on
+// the paths, the whole value of a member with a nil node is on a path.
+type Node struct {
+	Members map[string]*Node
+}
+
+// Excluded is the node of the values off the paths, none of their members is on a path.
+var Excluded = &Node{}
+
+// NewTree returns the root of the tree of the paths, see Parse for their syntax. It returns nil
+// if a path refers to the whole value, e.g. "$".
+func NewTree(paths ...string) (*Node, error) {
+	root := &Node{Members: map[string]*Node{}}
+	for _, path := range paths {
+		names, err := Parse(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(names) == 0 {
+			return nil, nil
+		}
+		root.add(names)
+	}
+	return root, nil
+}
+
+// add adds the path consisting of the member names to the tree.
+func (n *Node) add(names []string) {
+	node := n
+	for i, name := range names {
+		child, ok := node.Members[name]
+		switch {
+		case i == len(names)-1:
+			node.Members[name] = nil
+			return
+		case ok && child == nil:
+			// The whole value is on a path already.
+			return
+		case !ok:
+			child = &Node{Members: map[string]*Node{}}
+			node.Members[name] = child
+		}
+		node = child
+	}
+}
@@ -0,0 +1,95 @@
+package jlexer
+
+import (
+	"errors"
+	"math/big"
+)
+
+var errInfinity = errors.New("infinite big.Float")
+
+// NumberText returns the text of a number literal as is, without converting it and losing
+// precision.
+func (r *Lexer) NumberText() string {
+	if r.token.kind == TokenUndef && r.Ok() {
+		r.FetchToken()
+	}
+	if !r.Ok() || r.token.kind != TokenNumber {
+		r.errInvalidToken("number")
+		return ""
+	}
+	ret := string(r.token.byteValue)
+	r.consume()
+	return ret
+}
+
+// bigNumberText returns the text of a number literal or a string literal holding a number. A
+// null is skipped and reported as not ok.
+func (r *Lexer) bigNumberText(kind string) (text string, ok bool) {
+	if r.token.kind == TokenUndef && r.Ok() {
+		r.FetchToken()
+	}
+	if !r.Ok() {
+		return "", false
+	}
+
+	switch r.token.kind {
+	case TokenNumber:
+		return r.number(), r.Ok()
+	case TokenString:
+		return r.UnsafeString(), r.Ok()
+	case TokenNull:
+		r.Null()
+		return "", false
+	default:
+		r.errInvalidToken(kind)
+		return "", false
+	}
+}
+
+// BigInt reads an integer of arbitrary size from a number literal or a string literal holding
+// it. A null results in nil.
+func (r *Lexer) BigInt() *big.Int {
+	s, ok := r.bigNumberText("big.Int")
+	if !ok {
+		return nil
+	}
+
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		r.addNonfatalError(&LexerError{
+			Offset: r.offset + r.start,
+			Reason: "invalid big.Int",
+			Data:   s,
+		})
+		return nil
+	}
+	return n
+}
+
+// BigFloat reads a floating-point number from a number literal or a string literal holding it.
+// A null results in nil. The precision of the result is enough to keep all the digits of the
+// text and at least 64 bits.
+func (r *Lexer) BigFloat() *big.Float {
+	s, ok := r.bigNumberText("big.Float")
+	if !ok {
+		return nil
+	}
+
+	prec := uint(4 * len(s))
+	if prec < 64 {
+		prec = 64
+	}
+	f, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+	if err == nil && f.IsInf() {
+		err = errInfinity
+	}
+	if err != nil {
+		r.addNonfatalError(&LexerError{
+			Offset: r.offset + r.start,
+			Reason: err.Error(),
+			Data:   s,
+		})
+		return nil
+	}
+	return f
+}
@@ -8,8 +8,30 @@ type LexerError struct {
 	Reason string
 	Offset int
 	Data   string
+
+	Line   int // 1-based line of Offset in the input, 0 if unknown.
+	Column int // 1-based column of Offset in the line, counted in bytes, 0 if unknown.
+
+	Path  string // JSON path to the value being decoded, e.g. "$.items[3].price".
+	Field string // Go struct field being decoded, e.g. "Item.Price".
+	Type  string // Go type of the struct field being decoded, e.g. "float64".
 }
 
 func (l *LexerError) Error() string {
-	return fmt.Sprintf("parse error: %s near offset %d of '%s'", l.Reason, l.Offset, l.Data)
+	var msg string
+	if l.Line > 0 {
+		msg = fmt.Sprintf("parse error: %s near offset %d (line %d, column %d) of '%s'", l.Reason, l.Offset, l.Line, l.Column, l.Data)
+	} else {
+		msg = fmt.Sprintf("parse error: %s near offset %d of '%s'", l.Reason, l.Offset, l.Data)
+	}
+	if l.Path != "" {
+		msg += " at " + l.Path
+	}
+	switch {
+	case l.Field != "" && l.Type != "":
+		msg += fmt.Sprintf(" (decoding %s of type %s)", l.Field, l.Type)
+	case l.Field != "":
+		msg += fmt.Sprintf(" (decoding %s)", l.Field)
+	}
+	return msg
 }
@@ -41,20 +41,60 @@ type token struct {
 	delimValue      byte
 }
 
+// readChunkSize is the minimal number of bytes requested from Lexer.Reader at once.
+const readChunkSize = 4096
+
+// maxEmptyReads limits the number of consecutive empty reads from Lexer.Reader.
+const maxEmptyReads = 100
+
 // Lexer is a JSON lexer: it iterates over JSON tokens in a byte slice.
+//
+// If Reader is set, the lexer works in streaming mode: Data holds a window of the input that
+// is refilled from Reader as the lexer advances, and the bytes preceding the current token are
+// dropped from the window. Buffers are never overwritten, so strings and slices returned by the
+// lexer stay valid after the window moves.
 type Lexer struct {
-	Data []byte // Input data given to the lexer.
+	Data   []byte    // Input data given to the lexer.
+	Reader io.Reader // Input stream given to the lexer, Data is read from it if set.
+
+	start  int   // Start of the current token.
+	pos    int   // Current unscanned position in the input stream.
+	offset int   // Offset of Data[0] in the input stream.
+	token  token // Last scanned token, if token.kind != TokenUndef.
+
+	readErr error // Error returned by the last Reader.Read call.
+
+	lines     int // Number of newlines preceding Data[0] in the input stream.
+	lineStart int // Offset of the line that Data[0] belongs to in the input stream.
+
+	// Position of the last error, the positions of the following errors are counted from it.
+	errOffset    int // Offset of the last error in the input stream.
+	errLine      int // 1-based line of errOffset, 0 if no position was computed.
+	errLineStart int // Offset of the line that errOffset belongs to.
 
-	start int   // Start of the current token.
-	pos   int   // Current unscanned position in the input stream.
-	token token // Last scanned token, if token.kind != TokenUndef.
+	keep     bool // Whether the data starting at keepFrom is kept in the window during LookupString.
+	keepFrom int  // Offset in the input stream of the data to keep.
+
+	// ErrorContextLen limits the length of the input quoted in the Data field of errors,
+	// 13 bytes if not set.
+	ErrorContextLen int
 
 	firstElement bool // Whether current element is the first in array or an object.
 	wantSep      byte // A comma or a colon character, which need to occur before a token.
 
+	UseNumber         bool // Interface decodes numbers as json.Number rather than float64.
+	UseInt64          bool // Interface decodes integer numbers as int64 rather than float64.
+	UseOrderedObjects bool // Interface decodes objects as OrderedObject rather than map[string]interface{}.
+
 	UseMultipleErrors bool          // If we want to use multiple errors.
 	fatalError        error         // Fatal error occurred during lexing. It is usually a syntax error.
 	multipleErrors    []*LexerError // Semantic errors occurred during lexing. Marshalling will be continued after finding this errors.
+
+	path pathStack // Path to the value being decoded, maintained by the decoders.
+
+	// Projection limits decoding to the values at its paths, all values are decoded if nil.
+	// Generated decoders skip the object members off the paths.
+	Projection *Projection
 }
 
 // FetchToken scans the input for the next token.
@@ -70,94 +110,161 @@ func (r *Lexer) FetchToken() {
 	}
 	// Determine the type of a token by skipping whitespace and reading the
 	// first character.
-	for _, c := range r.Data[r.pos:] {
-		switch c {
-		case ':', ',':
-			if r.wantSep == c {
+	for {
+		for _, c := range r.Data[r.pos:] {
+			switch c {
+			case ':', ',':
+				if r.wantSep == c {
+					r.pos++
+					r.start++
+					r.wantSep = 0
+				} else {
+					r.errSyntax()
+				}
+
+			case ' ', '\t', '\r', '\n':
 				r.pos++
 				r.start++
+
+			case '"':
+				if r.wantSep != 0 {
+					r.errSyntax()
+				}
+
+				r.token.kind = TokenString
+				r.fetchString()
+				return
+
+			case '{', '[':
+				if r.wantSep != 0 {
+					r.errSyntax()
+				}
+				r.firstElement = true
+				r.token.kind = TokenDelim
+				r.token.delimValue = r.Data[r.pos]
+				r.pos++
+				return
+
+			case '}', ']':
+				if !r.firstElement && (r.wantSep != ',') {
+					r.errSyntax()
+				}
 				r.wantSep = 0
-			} else {
-				r.errSyntax()
-			}
+				r.token.kind = TokenDelim
+				r.token.delimValue = r.Data[r.pos]
+				r.pos++
+				return
 
-		case ' ', '\t', '\r', '\n':
-			r.pos++
-			r.start++
+			case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '-':
+				if r.wantSep != 0 {
+					r.errSyntax()
+				}
+				r.token.kind = TokenNumber
+				r.fetchNumber()
+				return
 
-		case '"':
-			if r.wantSep != 0 {
-				r.errSyntax()
-			}
+			case 'n':
+				if r.wantSep != 0 {
+					r.errSyntax()
+				}
 
-			r.token.kind = TokenString
-			r.fetchString()
-			return
+				r.token.kind = TokenNull
+				r.fetchNull()
+				return
 
-		case '{', '[':
-			if r.wantSep != 0 {
-				r.errSyntax()
-			}
-			r.firstElement = true
-			r.token.kind = TokenDelim
-			r.token.delimValue = r.Data[r.pos]
-			r.pos++
-			return
+			case 't':
+				if r.wantSep != 0 {
+					r.errSyntax()
+				}
 
-		case '}', ']':
-			if !r.firstElement && (r.wantSep != ',') {
-				r.errSyntax()
-			}
-			r.wantSep = 0
-			r.token.kind = TokenDelim
-			r.token.delimValue = r.Data[r.pos]
-			r.pos++
-			return
+				r.token.kind = TokenBool
+				r.token.boolValue = true
+				r.fetchTrue()
+				return
 
-		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '-':
-			if r.wantSep != 0 {
-				r.errSyntax()
-			}
-			r.token.kind = TokenNumber
-			r.fetchNumber()
-			return
+			case 'f':
+				if r.wantSep != 0 {
+					r.errSyntax()
+				}
 
-		case 'n':
-			if r.wantSep != 0 {
+				r.token.kind = TokenBool
+				r.token.boolValue = false
+				r.fetchFalse()
+				return
+
+			default:
 				r.errSyntax()
+				return
 			}
+		}
+		if !r.fill() {
+			break
+		}
+	}
+	if r.fatalError == nil {
+		r.fatalError = io.EOF
+	}
+}
 
-			r.token.kind = TokenNull
-			r.fetchNull()
-			return
+// fill reads more data from r.Reader into the window, dropping the data that precedes the
+// current token if a new buffer has to be allocated. It returns false if no data was read.
+func (r *Lexer) fill() bool {
+	if r.Reader == nil || r.readErr != nil {
+		return false
+	}
 
-		case 't':
-			if r.wantSep != 0 {
-				r.errSyntax()
-			}
+	if cap(r.Data)-len(r.Data) < readChunkSize/4 {
+		from := r.start
+		if r.keep && r.keepFrom-r.offset < from {
+			from = r.keepFrom - r.offset
+		}
 
-			r.token.kind = TokenBool
-			r.token.boolValue = true
-			r.fetchTrue()
-			return
+		keep := r.Data[from:]
+		size := 2 * len(keep)
+		if size < readChunkSize {
+			size = readChunkSize
+		}
 
-		case 'f':
-			if r.wantSep != 0 {
-				r.errSyntax()
-			}
+		// A new buffer is allocated instead of reusing the old one, as strings returned by
+		// UnsafeString() and slices returned by Raw() may still refer to the old buffer.
+		data := make([]byte, len(keep), size)
+		copy(data, keep)
 
-			r.token.kind = TokenBool
-			r.token.boolValue = false
-			r.fetchFalse()
-			return
+		r.dropLines(r.Data[:from])
+		r.offset += from
+		r.pos -= from
+		r.start -= from
+		r.Data = data
+	}
 
-		default:
-			r.errSyntax()
-			return
+	for i := 0; i < maxEmptyReads; i++ {
+		n, err := r.Reader.Read(r.Data[len(r.Data):cap(r.Data)])
+		r.Data = r.Data[:len(r.Data)+n]
+		if err != nil {
+			r.readErr = err
+			if err != io.EOF && r.fatalError == nil {
+				r.fatalError = err
+			}
+		}
+		if n > 0 {
+			return true
+		}
+		if err != nil {
+			return false
 		}
 	}
-	r.fatalError = io.EOF
-	return
+
+	r.readErr = io.ErrNoProgress
+	if r.fatalError == nil {
+		r.fatalError = io.ErrNoProgress
+	}
+	return false
+}
+
+// ensure tries to make at least n bytes after the current position available in the window.
+func (r *Lexer) ensure(n int) {
+	for len(r.Data)-r.pos < n && r.fill() {
+	}
 }
 
 // isTokenEnd returns true if the char can follow a non-delimiter token
@@ -167,6 +274,7 @@ func isTokenEnd(c byte) bool {
 
 // fetchNull fetches and checks remaining bytes of null keyword.
 func (r *Lexer) fetchNull() {
+	r.ensure(5)
 	r.pos += 4
 	if r.pos > len(r.Data) ||
 		r.Data[r.pos-3] != 'u' ||
@@ -181,6 +289,7 @@ func (r *Lexer) fetchNull() {
 
 // fetchTrue fetches and checks remaining bytes of true keyword.
 func (r *Lexer) fetchTrue() {
+	r.ensure(5)
 	r.pos += 4
 	if r.pos > len(r.Data) ||
 		r.Data[r.pos-3] != 'r' ||
@@ -195,6 +304,7 @@ func (r *Lexer) fetchTrue() {
 
 // fetchFalse fetches and checks remaining bytes of false keyword.
 func (r *Lexer) fetchFalse() {
+	r.ensure(6)
 	r.pos += 5
 	if r.pos > len(r.Data) ||
 		r.Data[r.pos-4] != 'a' ||
@@ -215,30 +325,35 @@ func (r *Lexer) fetchNumber() {
 	hasDot := false
 
 	r.pos++
-	for i, c := range r.Data[r.pos:] {
-		switch {
-		case c >= '0' && c <= '9':
-			afterE = false
-		case c == '.' && !hasDot:
-			hasDot = true
-		case (c == 'e' || c == 'E') && !hasE:
-			hasE = true
-			hasDot = true
-			afterE = true
-		case (c == '+' || c == '-') && afterE:
-			afterE = false
-		default:
-			r.pos += i
-			if !isTokenEnd(c) {
-				r.errSyntax()
-			} else {
-				r.token.byteValue = r.Data[r.start:r.pos]
+	for {
+		for i, c := range r.Data[r.pos:] {
+			switch {
+			case c >= '0' && c <= '9':
+				afterE = false
+			case c == '.' && !hasDot:
+				hasDot = true
+			case (c == 'e' || c == 'E') && !hasE:
+				hasE = true
+				hasDot = true
+				afterE = true
+			case (c == '+' || c == '-') && afterE:
+				afterE = false
+			default:
+				r.pos += i
+				if !isTokenEnd(c) {
+					r.errSyntax()
+				} else {
+					r.token.byteValue = r.Data[r.start:r.pos]
+				}
+				return
 			}
-			return
 		}
-	}
 
-	r.pos = len(r.Data)
+		r.pos = len(r.Data)
+		if !r.fill() {
+			break
+		}
+	}
 	r.token.byteValue = r.Data[r.start:]
 }
 
@@ -380,6 +495,10 @@ func (r *Lexer) fetchString() {
 	data := r.Data[r.pos:]
 
 	isValid, length := findStringLen(data)
+	for !isValid && r.fill() {
+		data = r.Data[r.pos:]
+		isValid, length = findStringLen(data)
+	}
 	if !isValid {
 		r.pos += length
 		r.errParse("unterminated string literal")
@@ -410,21 +529,85 @@ func (r *Lexer) Ok() bool {
 	return r.fatalError == nil
 }
 
-const maxErrorContextLen = 13
+// defaultErrorContextLen is the default value of Lexer.ErrorContextLen.
+const defaultErrorContextLen = 13
+
+func (r *Lexer) errorContextLen() int {
+	if r.ErrorContextLen > 0 {
+		return r.ErrorContextLen
+	}
+	return defaultErrorContextLen
+}
+
+// errorContext returns the part of the input b quoted in errors.
+func (r *Lexer) errorContext(b []byte) string {
+	n := r.errorContextLen()
+	if len(b) <= n {
+		return string(b)
+	}
+	if n <= 3 {
+		return string(b[:n])
+	}
+	return string(b[:n-3]) + "..."
+}
+
+// dropLines accounts for the newlines in the data dropped from the window.
+func (r *Lexer) dropLines(b []byte) {
+	if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+		r.lines += bytes.Count(b, []byte{'\n'})
+		r.lineStart = r.offset + i + 1
+	}
+}
+
+// position returns the 1-based line and column (in bytes) of the given offset in the input
+// stream, or zeros if the offset is outside of the window. The newlines are counted from the
+// position of the previous error if it precedes the offset, so that the positions of many errors
+// are found in a single pass over the input.
+func (r *Lexer) position(offset int) (line, column int) {
+	if offset < r.offset || offset > r.offset+len(r.Data) {
+		return 0, 0
+	}
+
+	from, line, lineStart := r.offset, r.lines+1, r.lineStart
+	if r.errLine > 0 && r.errOffset >= r.offset && r.errOffset <= offset {
+		from, line, lineStart = r.errOffset, r.errLine, r.errLineStart
+	}
+	b := r.Data[from-r.offset : offset-r.offset]
+	if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+		line += bytes.Count(b, []byte{'\n'})
+		lineStart = from + i + 1
+	}
+
+	r.errOffset, r.errLine, r.errLineStart = offset, line, lineStart
+	return line, offset - lineStart + 1
+}
+
+// annotateError sets the position of err in the input and the path to the value being decoded.
+func (r *Lexer) annotateError(err *LexerError) *LexerError {
+	if err.Line == 0 {
+		err.Line, err.Column = r.position(err.Offset)
+	}
+	return r.pathError(err)
+}
 
 func (r *Lexer) errParse(what string) {
 	if r.fatalError == nil {
+		// Quote the data preceding the error too if it is at the end of the input.
 		var str string
-		if len(r.Data)-r.pos <= maxErrorContextLen {
-			str = string(r.Data)
+		if n := r.errorContextLen(); len(r.Data)-r.pos <= n {
+			from := len(r.Data) - n
+			if from < 0 {
+				from = 0
+			}
+			str = string(r.Data[from:])
 		} else {
-			str = string(r.Data[r.pos:r.pos+maxErrorContextLen-3]) + "..."
+			str = r.errorContext(r.Data[r.pos:])
 		}
-		r.fatalError = &LexerError{
+		r.fatalError = r.annotateError(&LexerError{
 			Reason: what,
-			Offset: r.pos,
+			Offset: r.offset + r.pos,
 			Data:   str,
-		}
+		})
 	}
 }
 
@@ -450,27 +633,22 @@ func (r *Lexer) errInvalidToken(expected string) {
 		}
 		r.addNonfatalError(&LexerError{
 			Reason: fmt.Sprintf("expected %s", expected),
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Data:   string(r.Data[r.start:r.pos]),
 		})
 		return
 	}
 
-	var str string
-	if len(r.token.byteValue) <= maxErrorContextLen {
-		str = string(r.token.byteValue)
-	} else {
-		str = string(r.token.byteValue[:maxErrorContextLen-3]) + "..."
-	}
-	r.fatalError = &LexerError{
+	r.fatalError = r.annotateError(&LexerError{
 		Reason: fmt.Sprintf("expected %s", expected),
-		Offset: r.pos,
-		Data:   str,
-	}
+		Offset: r.offset + r.pos,
+		Data:   r.errorContext(r.token.byteValue),
+	})
 }
 
+// GetPos returns the current position in the input stream.
 func (r *Lexer) GetPos() int {
-	return r.pos
+	return r.offset + r.pos
 }
 
 // Delim consumes a token and verifies that it is the given delimiter.
@@ -529,7 +707,6 @@ func (r *Lexer) Skip() {
 func (r *Lexer) SkipRecursive() {
 	r.scanToken()
 	var start, end byte
-	startPos := r.start
 
 	switch r.token.delimValue {
 	case '{':
@@ -547,40 +724,46 @@ func (r *Lexer) SkipRecursive() {
 	inQuotes := false
 	wasEscape := false
 
-	for i, c := range r.Data[r.pos:] {
-		switch {
-		case c == start && !inQuotes:
-			level++
-		case c == end && !inQuotes:
-			level--
-			if level == 0 {
-				r.pos += i + 1
-				if !json.Valid(r.Data[startPos:r.pos]) {
-					r.pos = len(r.Data)
-					r.fatalError = &LexerError{
-						Reason: "skipped array/object json value is invalid",
-						Offset: r.pos,
-						Data:   string(r.Data[r.pos:]),
+	for {
+		for i, c := range r.Data[r.pos:] {
+			switch {
+			case c == start && !inQuotes:
+				level++
+			case c == end && !inQuotes:
+				level--
+				if level == 0 {
+					r.pos += i + 1
+					if !json.Valid(r.Data[r.start:r.pos]) {
+						r.pos = len(r.Data)
+						r.fatalError = r.annotateError(&LexerError{
+							Reason: "skipped array/object json value is invalid",
+							Offset: r.offset + r.pos,
+							Data:   string(r.Data[r.pos:]),
+						})
 					}
+					return
 				}
-				return
+			case c == '\\' && inQuotes:
+				wasEscape = !wasEscape
+				continue
+			case c == '"' && inQuotes:
+				inQuotes = wasEscape
+			case c == '"':
+				inQuotes = true
 			}
-		case c == '\\' && inQuotes:
-			wasEscape = !wasEscape
-			continue
-		case c == '"' && inQuotes:
-			inQuotes = wasEscape
-		case c == '"':
-			inQuotes = true
+			wasEscape = false
+		}
+
+		r.pos = len(r.Data)
+		if !r.fill() {
+			break
 		}
-		wasEscape = false
 	}
-	r.pos = len(r.Data)
-	r.fatalError = &LexerError{
+	r.fatalError = r.annotateError(&LexerError{
 		Reason: "EOF reached while skipping array/object or token",
-		Offset: r.pos,
+		Offset: r.offset + r.pos,
 		Data:   string(r.Data[r.pos:]),
-	}
+	})
 }
 
 // Raw fetches the next item recursively as a data slice
@@ -592,10 +775,50 @@ func (r *Lexer) Raw() []byte {
 	return r.Data[r.start:r.pos]
 }
 
+// LookupString returns the value of the string member with the given name of the object that
+// is the next value in the input, without consuming the object. It allows to decode objects
+// based on the value of a member that may follow the other members, e.g. the discriminator of a
+// tagged union. The returned flag reports whether the member was found.
+func (r *Lexer) LookupString(name string) (value string, found bool) {
+	if r.token.kind == TokenUndef && r.Ok() {
+		r.FetchToken()
+	}
+	if !r.Ok() || r.token.kind != TokenDelim || r.token.delimValue != '{' {
+		return "", false
+	}
+
+	saved := *r
+	start, pos := r.offset+r.start, r.offset+r.pos
+	r.keep, r.keepFrom = true, start
+
+	r.Delim('{')
+	for !r.IsDelim('}') && r.Ok() {
+		key := r.UnsafeFieldName(false)
+		r.WantColon()
+		if key == name {
+			value = r.String()
+			found = r.Ok()
+			break
+		}
+		r.SkipRecursive()
+		r.WantComma()
+	}
+
+	// Restore the state, keeping the data read into the window.
+	data, offset, readErr, lines, lineStart := r.Data, r.offset, r.readErr, r.lines, r.lineStart
+	*r = saved
+	r.Data, r.offset, r.readErr, r.lines, r.lineStart = data, offset, readErr, lines, lineStart
+	r.start, r.pos = start-offset, pos-offset
+	if readErr != nil && readErr != io.EOF && r.fatalError == nil {
+		r.fatalError = readErr
+	}
+	return value, found
+}
+
 // IsStart returns whether the lexer is positioned at the start
 // of an input string.
 func (r *Lexer) IsStart() bool {
-	return r.pos == 0
+	return r.offset+r.pos == 0
 }
 
 // Consumed reads all remaining bytes from the input, publishing an error if
@@ -605,18 +828,24 @@ func (r *Lexer) Consumed() {
 		return
 	}
 
-	for _, c := range r.Data[r.pos:] {
-		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
-			r.AddError(&LexerError{
-				Reason: "invalid character '" + string(c) + "' after top-level value",
-				Offset: r.pos,
-				Data:   string(r.Data[r.pos:]),
-			})
-			return
+	for {
+		for _, c := range r.Data[r.pos:] {
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				r.AddError(&LexerError{
+					Reason: "invalid character '" + string(c) + "' after top-level value",
+					Offset: r.offset + r.pos,
+					Data:   string(r.Data[r.pos:]),
+				})
+				return
+			}
+
+			r.pos++
+			r.start++
 		}
 
-		r.pos++
-		r.start++
+		if !r.fill() {
+			return
+		}
 	}
 }
 
@@ -719,9 +948,10 @@ func (r *Lexer) Bytes() []byte {
 	ret := make([]byte, base64.StdEncoding.DecodedLen(len(r.token.byteValue)))
 	n, err := base64.StdEncoding.Decode(ret, r.token.byteValue)
 	if err != nil {
-		r.fatalError = &LexerError{
+		r.fatalError = r.annotateError(&LexerError{
 			Reason: err.Error(),
-		}
+			Offset: r.offset + r.start,
+		})
 		return nil
 	}
 
@@ -765,7 +995,7 @@ func (r *Lexer) Uint8() uint8 {
 	n, err := strconv.ParseUint(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -782,7 +1012,7 @@ func (r *Lexer) Uint16() uint16 {
 	n, err := strconv.ParseUint(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -799,7 +1029,7 @@ func (r *Lexer) Uint32() uint32 {
 	n, err := strconv.ParseUint(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -816,7 +1046,7 @@ func (r *Lexer) Uint64() uint64 {
 	n, err := strconv.ParseUint(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -837,7 +1067,7 @@ func (r *Lexer) Int8() int8 {
 	n, err := strconv.ParseInt(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -854,7 +1084,7 @@ func (r *Lexer) Int16() int16 {
 	n, err := strconv.ParseInt(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -871,7 +1101,7 @@ func (r *Lexer) Int32() int32 {
 	n, err := strconv.ParseInt(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -888,7 +1118,7 @@ func (r *Lexer) Int64() int64 {
 	n, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -909,7 +1139,7 @@ func (r *Lexer) Uint8Str() uint8 {
 	n, err := strconv.ParseUint(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -926,7 +1156,7 @@ func (r *Lexer) Uint16Str() uint16 {
 	n, err := strconv.ParseUint(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -943,7 +1173,7 @@ func (r *Lexer) Uint32Str() uint32 {
 	n, err := strconv.ParseUint(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -960,7 +1190,7 @@ func (r *Lexer) Uint64Str() uint64 {
 	n, err := strconv.ParseUint(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -985,7 +1215,7 @@ func (r *Lexer) Int8Str() int8 {
 	n, err := strconv.ParseInt(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1002,7 +1232,7 @@ func (r *Lexer) Int16Str() int16 {
 	n, err := strconv.ParseInt(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1019,7 +1249,7 @@ func (r *Lexer) Int32Str() int32 {
 	n, err := strconv.ParseInt(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1036,7 +1266,7 @@ func (r *Lexer) Int64Str() int64 {
 	n, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1057,7 +1287,7 @@ func (r *Lexer) Float32() float32 {
 	n, err := strconv.ParseFloat(s, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1073,7 +1303,7 @@ func (r *Lexer) Float32Str() float32 {
 	n, err := strconv.ParseFloat(s, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1090,7 +1320,7 @@ func (r *Lexer) Float64() float64 {
 	n, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1106,7 +1336,7 @@ func (r *Lexer) Float64Str() float64 {
 	n, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.offset + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1120,22 +1350,44 @@ func (r *Lexer) Error() error {
 
 func (r *Lexer) AddError(e error) {
 	if r.fatalError == nil {
+		if le, ok := e.(*LexerError); ok {
+			r.annotateError(le)
+		}
 		r.fatalError = e
 	}
 }
 
 func (r *Lexer) AddNonFatalError(e error) {
 	r.addNonfatalError(&LexerError{
-		Offset: r.start,
+		Offset: r.offset + r.start,
 		Data:   string(r.Data[r.start:r.pos]),
 		Reason: e.Error(),
 	})
 }
 
+// AddValidationError adds the violation of a constraint by the last token as a non-fatal error.
+// Unlike AddNonFatalError, which keeps one error per token, it keeps the violations of several
+// constraints of the same value unless they have the same reason.
+func (r *Lexer) AddValidationError(e error) {
+	r.addNonfatalErrorAt(&LexerError{
+		Offset: r.offset + r.start,
+		Data:   string(r.Data[r.start:r.pos]),
+		Reason: e.Error(),
+	}, true)
+}
+
 func (r *Lexer) addNonfatalError(err *LexerError) {
+	r.addNonfatalErrorAt(err, false)
+}
+
+// addNonfatalErrorAt adds a non-fatal error, which is dropped if the previous one has the same
+// offset and, if sameReason is set, the same reason.
+func (r *Lexer) addNonfatalErrorAt(err *LexerError, sameReason bool) {
+	r.annotateError(err)
 	if r.UseMultipleErrors {
 		// We don't want to add errors with the same offset.
-		if len(r.multipleErrors) != 0 && r.multipleErrors[len(r.multipleErrors)-1].Offset == err.Offset {
+		if n := len(r.multipleErrors); n != 0 && r.multipleErrors[n-1].Offset == err.Offset &&
+			(!sameReason || r.multipleErrors[n-1].Reason == err.Reason) {
 			return
 		}
 		r.multipleErrors = append(r.multipleErrors, err)
@@ -1173,7 +1425,32 @@ func (r *Lexer) JsonNumber() json.Number {
 	}
 }
 
+// interfaceInt64 fetches a number as int64 if it is an integer in the int64 range, as float64
+// otherwise.
+func (r *Lexer) interfaceInt64() interface{} {
+	s := r.number()
+	if !r.Ok() {
+		return nil
+	}
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		r.addNonfatalError(&LexerError{
+			Offset: r.offset + r.start,
+			Reason: err.Error(),
+			Data:   s,
+		})
+	}
+	return n
+}
+
 // Interface fetches an interface{} analogous to the 'encoding/json' package.
+//
+// Numbers are decoded as float64 unless UseNumber or UseInt64 is set, UseNumber taking
+// precedence, and objects as map[string]interface{} unless UseOrderedObjects is set.
 func (r *Lexer) Interface() interface{} {
 	if r.token.kind == TokenUndef && r.Ok() {
 		r.FetchToken()
@@ -1186,6 +1463,12 @@ func (r *Lexer) Interface() interface{} {
 	case TokenString:
 		return r.String()
 	case TokenNumber:
+		switch {
+		case r.UseNumber:
+			return r.JsonNumber()
+		case r.UseInt64:
+			return r.interfaceInt64()
+		}
 		return r.Float64()
 	case TokenBool:
 		return r.Bool()
@@ -1194,7 +1477,23 @@ func (r *Lexer) Interface() interface{} {
 		return nil
 	}
 
-	if r.token.delimValue == '{' {
+	if r.token.delimValue == '{' && r.UseOrderedObjects {
+		r.consume()
+
+		ret := OrderedObject{}
+		for !r.IsDelim('}') {
+			key := r.String()
+			r.WantColon()
+			ret = append(ret, Member{Key: key, Value: r.Interface()})
+			r.WantComma()
+		}
+		r.Delim('}')
+
+		if r.Ok() {
+			return ret
+		}
+		return nil
+	} else if r.token.delimValue == '{' {
 		r.consume()
 
 		ret := map[string]interface{}{}
@@ -3,8 +3,15 @@ package jlexer
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 )
 
 func TestString(t *testing.T) {
@@ -269,6 +276,71 @@ func TestInterface(t *testing.T) {
 	}
 }
 
+func TestInterfaceOptions(t *testing.T) {
+	for i, test := range []struct {
+		toParse string
+		lexer   Lexer
+		want    interface{}
+	}{
+		{
+			toParse: `[9007199254740993, 1.5, 1e3, -7]`,
+			lexer:   Lexer{UseNumber: true},
+			want:    []interface{}{json.Number("9007199254740993"), json.Number("1.5"), json.Number("1e3"), json.Number("-7")},
+		},
+		{
+			toParse: `[9007199254740993, 1.5, 1e3, -7, 18446744073709551616]`,
+			lexer:   Lexer{UseInt64: true},
+			want:    []interface{}{int64(9007199254740993), 1.5, float64(1000), int64(-7), float64(18446744073709551616)},
+		},
+		{
+			toParse: `[1, 2.5]`,
+			lexer:   Lexer{UseNumber: true, UseInt64: true},
+			want:    []interface{}{json.Number("1"), json.Number("2.5")},
+		},
+		{
+			toParse: `{"b": 1, "a": {"d": [], "c": null}, "b": 2}`,
+			lexer:   Lexer{UseOrderedObjects: true, UseInt64: true},
+			want: OrderedObject{
+				{Key: "b", Value: int64(1)},
+				{Key: "a", Value: OrderedObject{{Key: "d", Value: []interface{}{}}, {Key: "c", Value: nil}}},
+				{Key: "b", Value: int64(2)},
+			},
+		},
+		{
+			toParse: `{}`,
+			lexer:   Lexer{UseOrderedObjects: true},
+			want:    OrderedObject{},
+		},
+	} {
+		l := test.lexer
+		l.Data = []byte(test.toParse)
+
+		got := l.Interface()
+		if err := l.Error(); err != nil {
+			t.Errorf("[%d, %q] Interface() error: %v", i, test.toParse, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("[%d, %q] Interface() = %#v; want %#v", i, test.toParse, got, test.want)
+		}
+	}
+
+	l := Lexer{Data: []byte(`{"z": 1, "a": {"y": "x", "b": true}, "z": [2]}`), UseOrderedObjects: true, UseNumber: true}
+	o := l.Interface().(OrderedObject)
+	if v, ok := o.Get("z"); !ok || !reflect.DeepEqual(v, []interface{}{json.Number("2")}) {
+		t.Errorf("Get(%q) = %v, %v; want the last member", "z", v, ok)
+	}
+	if _, ok := o.Get("y"); ok {
+		t.Errorf("Get(%q) found a nested member", "y")
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	if want := `{"z":1,"a":{"y":"x","b":true},"z":[2]}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s; want %s", data, want)
+	}
+}
+
 func TestConsumed(t *testing.T) {
 	for i, test := range []struct {
 		toParse   string
@@ -355,6 +427,159 @@ func TestJsonNumber(t *testing.T) {
 	}
 }
 
+func TestTime(t *testing.T) {
+	for i, test := range []struct {
+		toParse   string
+		layout    string
+		want      time.Time
+		wantError bool
+	}{
+		{toParse: `"2016-01-02T14:15:10Z"`, layout: time.RFC3339, want: time.Date(2016, 1, 2, 14, 15, 10, 0, time.UTC)},
+		{toParse: `"2016-01-02T14:15:10.5Z"`, layout: time.RFC3339, want: time.Date(2016, 1, 2, 14, 15, 10, 5e8, time.UTC)},
+		{toParse: `"2016-01-02"`, layout: "2006-01-02", want: time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{toParse: `null`, layout: time.RFC3339},
+
+		{toParse: `"2016-01-02"`, layout: time.RFC3339, wantError: true},
+		{toParse: `1451744110`, layout: time.RFC3339, wantError: true},
+	} {
+		l := Lexer{Data: []byte(test.toParse)}
+
+		got := l.Time(test.layout)
+		if !got.Equal(test.want) && !test.wantError {
+			t.Errorf("[%d, %q] Time() = %v; want %v", i, test.toParse, got, test.want)
+		}
+
+		err := l.Error()
+		if err != nil && !test.wantError {
+			t.Errorf("[%d, %q] Time() error: %v", i, test.toParse, err)
+		} else if err == nil && test.wantError {
+			t.Errorf("[%d, %q] Time() ok; want error", i, test.toParse)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	for i, test := range []struct {
+		toParse   string
+		want      time.Duration
+		wantError bool
+	}{
+		{toParse: `"1h30m"`, want: 90 * time.Minute},
+		{toParse: `"-1.5s"`, want: -1500 * time.Millisecond},
+		{toParse: `5400000000000`, want: 90 * time.Minute},
+		{toParse: `null`, want: 0},
+
+		{toParse: `"90"`, wantError: true},
+		{toParse: `1.5`, wantError: true},
+		{toParse: `true`, wantError: true},
+	} {
+		l := Lexer{Data: []byte(test.toParse)}
+
+		got := l.Duration()
+		if got != test.want && !test.wantError {
+			t.Errorf("[%d, %q] Duration() = %v; want %v", i, test.toParse, got, test.want)
+		}
+
+		err := l.Error()
+		if err != nil && !test.wantError {
+			t.Errorf("[%d, %q] Duration() error: %v", i, test.toParse, err)
+		} else if err == nil && test.wantError {
+			t.Errorf("[%d, %q] Duration() ok; want error", i, test.toParse)
+		}
+	}
+}
+
+func TestNumberText(t *testing.T) {
+	for i, test := range []struct {
+		toParse   string
+		want      string
+		wantError bool
+	}{
+		{toParse: `123456789012345678901234567890`, want: "123456789012345678901234567890"},
+		{toParse: `-0.1e-400`, want: "-0.1e-400"},
+
+		{toParse: `"1"`, wantError: true},
+		{toParse: `null`, wantError: true},
+	} {
+		l := Lexer{Data: []byte(test.toParse)}
+
+		got := l.NumberText()
+		if got != test.want && !test.wantError {
+			t.Errorf("[%d, %q] NumberText() = %q; want %q", i, test.toParse, got, test.want)
+		}
+
+		err := l.Error()
+		if err != nil && !test.wantError {
+			t.Errorf("[%d, %q] NumberText() error: %v", i, test.toParse, err)
+		} else if err == nil && test.wantError {
+			t.Errorf("[%d, %q] NumberText() ok; want error", i, test.toParse)
+		}
+	}
+}
+
+func TestBigInt(t *testing.T) {
+	for i, test := range []struct {
+		toParse   string
+		want      string
+		wantError bool
+	}{
+		{toParse: `123456789012345678901234567890`, want: "123456789012345678901234567890"},
+		{toParse: `-18446744073709551616`, want: "-18446744073709551616"},
+		{toParse: `"18446744073709551616"`, want: "18446744073709551616"},
+		{toParse: `null`, want: "<nil>"},
+
+		{toParse: `1.5`, wantError: true},
+		{toParse: `1e3`, wantError: true},
+		{toParse: `"abc"`, wantError: true},
+		{toParse: `true`, wantError: true},
+	} {
+		l := Lexer{Data: []byte(test.toParse)}
+
+		got := l.BigInt().String()
+		if got != test.want && !test.wantError {
+			t.Errorf("[%d, %q] BigInt() = %v; want %v", i, test.toParse, got, test.want)
+		}
+
+		err := l.Error()
+		if err != nil && !test.wantError {
+			t.Errorf("[%d, %q] BigInt() error: %v", i, test.toParse, err)
+		} else if err == nil && test.wantError {
+			t.Errorf("[%d, %q] BigInt() ok; want error", i, test.toParse)
+		}
+	}
+}
+
+func TestBigFloat(t *testing.T) {
+	for i, test := range []struct {
+		toParse   string
+		want      string
+		wantError bool
+	}{
+		{toParse: `0.1`, want: "0.1"},
+		{toParse: `12345678901234567890.123456789`, want: "1.2345678901234567890123456789e+19"},
+		{toParse: `"-1.5e-400"`, want: "-1.5e-400"},
+		{toParse: `null`, want: "<nil>"},
+
+		{toParse: `"Inf"`, wantError: true},
+		{toParse: `"abc"`, wantError: true},
+		{toParse: `[]`, wantError: true},
+	} {
+		l := Lexer{Data: []byte(test.toParse)}
+
+		got := fmt.Sprint(l.BigFloat())
+		if got != test.want && !test.wantError {
+			t.Errorf("[%d, %q] BigFloat() = %v; want %v", i, test.toParse, got, test.want)
+		}
+
+		err := l.Error()
+		if err != nil && !test.wantError {
+			t.Errorf("[%d, %q] BigFloat() error: %v", i, test.toParse, err)
+		} else if err == nil && test.wantError {
+			t.Errorf("[%d, %q] BigFloat() ok; want error", i, test.toParse)
+		}
+	}
+}
+
 func TestFetchStringUnterminatedString(t *testing.T) {
 	for _, test := range []struct {
 		data []byte
@@ -388,3 +613,399 @@ func TestCurrentToken(t *testing.T) {
 		l.Skip()
 	}
 }
+
+func TestReader(t *testing.T) {
+	for i, test := range []string{
+		`null`,
+		`true`,
+		`false`,
+		`12.35e-15`,
+		`"simple string"`,
+		`"\n\t\"\/\\\f\r 😀"`,
+		`{"a":5 , "b" : "string", "c": [1, 2, {"d": null}], "e": false}`,
+		`[` + strings.Repeat(`"abcdefghijklmnopqrstuvwxyz", `, 1000) + `1]`,
+		`{"` + strings.Repeat("k", 10000) + `": "` + strings.Repeat(`v\"`, 5000) + `"}`,
+
+		``,
+		`nul`,
+		`"unterminated`,
+		`[1, 2`,
+		`{"a": tru}`,
+	} {
+		l := Lexer{Data: []byte(test)}
+		want := l.Interface()
+		wantErr := l.Error()
+
+		for _, r := range []io.Reader{
+			strings.NewReader(test),
+			iotest.OneByteReader(strings.NewReader(test)),
+			iotest.HalfReader(strings.NewReader(test)),
+		} {
+			l := Lexer{Reader: r}
+			got := l.Interface()
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("[%d, %T] Interface() = %v; want %v", i, r, got, want)
+			}
+			if err := l.Error(); !reflect.DeepEqual(err, wantErr) {
+				t.Errorf("[%d, %T] Interface() error = %v; want %v", i, r, err, wantErr)
+			}
+		}
+	}
+}
+
+func TestLookupString(t *testing.T) {
+	long := strings.Repeat("x", 3*readChunkSize)
+	for i, test := range []struct {
+		data      string
+		wantValue string
+		wantFound bool
+	}{
+		{data: `{"type": "a", "b": 1}`, wantValue: "a", wantFound: true},
+		{data: ` { "b": [1, {"type": "x"}], "c": {"type": "y"}, "type": "a\u0062" } `, wantValue: "ab", wantFound: true},
+		{data: `{"b": "` + long + `", "type": "a"}`, wantValue: "a", wantFound: true},
+		{data: `{"b": 1}`},
+		{data: `{"type": 1}`},
+		{data: `["type", "a"]`},
+		{data: `"type"`},
+		{data: `{"b": tru, "type": "a"}`},
+	} {
+		l := Lexer{Data: []byte(test.data)}
+		want := l.Interface()
+		wantErr := l.Error()
+
+		for _, l := range []*Lexer{
+			{Data: []byte(test.data)},
+			{Reader: strings.NewReader(test.data)},
+			{Reader: iotest.OneByteReader(strings.NewReader(test.data))},
+		} {
+			value, found := l.LookupString("type")
+			if value != test.wantValue || found != test.wantFound {
+				t.Errorf("[%d] LookupString() = %q, %v; want %q, %v", i, value, found, test.wantValue, test.wantFound)
+			}
+
+			// The value is not consumed by the lookup.
+			got := l.Interface()
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("[%d] Interface() = %v; want %v", i, got, want)
+			}
+			// The error context depends on the data kept in the window in streaming mode.
+			if err := l.Error(); (err == nil) != (wantErr == nil) || err != nil && err.(*LexerError).Offset != wantErr.(*LexerError).Offset {
+				t.Errorf("[%d] Interface() error = %v; want %v", i, err, wantErr)
+			}
+		}
+	}
+}
+
+func TestReaderSkipRecursive(t *testing.T) {
+	data := `{"skip": [1, {"a": "]}"}, 3], "raw": {"b": [true, null]}, "n": 42}  `
+
+	l := Lexer{Reader: iotest.OneByteReader(strings.NewReader(data))}
+	l.Delim('{')
+	var raw []byte
+	var n int
+	for !l.IsDelim('}') {
+		key := l.String()
+		l.WantColon()
+		switch key {
+		case "raw":
+			raw = l.Raw()
+		case "n":
+			n = l.Int()
+		default:
+			l.SkipRecursive()
+		}
+		l.WantComma()
+	}
+	l.Delim('}')
+	l.Consumed()
+
+	if err := l.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(raw) != `{"b": [true, null]}` {
+		t.Errorf("Raw() = %s; want %s", raw, `{"b": [true, null]}`)
+	}
+	if n != 42 {
+		t.Errorf("Int() = %d; want 42", n)
+	}
+}
+
+func TestReaderErrorOffset(t *testing.T) {
+	data := strings.Repeat(" ", 3*readChunkSize) + `[1, 2, x]`
+
+	l := Lexer{Reader: strings.NewReader(data)}
+	l.Interface()
+
+	err, ok := l.Error().(*LexerError)
+	if !ok {
+		t.Fatalf("Error() = %v; want *LexerError", l.Error())
+	}
+	if want := strings.Index(data, "x"); err.Offset != want {
+		t.Errorf("Offset = %d; want %d", err.Offset, want)
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	l := Lexer{Reader: iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader(`[1, 2]`)))}
+	l.Interface()
+
+	if err := l.Error(); err != iotest.ErrTimeout {
+		t.Errorf("Error() = %v; want %v", err, iotest.ErrTimeout)
+	}
+}
+
+func TestPath(t *testing.T) {
+	l := Lexer{Data: []byte(`{"items":[{"price":"x"}]}`)}
+	if got := l.Path(); got != "$" {
+		t.Errorf("Path() = %q; want %q", got, "$")
+	}
+
+	l.PushField("items", "Order.Items", "[]Item")
+	l.PushIndex(3)
+	l.PushField("unit price", "", "")
+	if got, want := l.Path(), `$.items[3]["unit price"]`; got != want {
+		t.Errorf("Path() = %q; want %q", got, want)
+	}
+	l.PopPath()
+	l.PushField("price", "Item.Price", "float64")
+	if got, want := l.Path(), "$.items[3].price"; got != want {
+		t.Errorf("Path() = %q; want %q", got, want)
+	}
+
+	l.AddError(&LexerError{Reason: "bad price"})
+	err, ok := l.Error().(*LexerError)
+	if !ok {
+		t.Fatalf("Error() = %v; want *LexerError", l.Error())
+	}
+	if err.Path != "$.items[3].price" || err.Field != "Item.Price" || err.Type != "float64" {
+		t.Errorf("got path %q, field %q, type %q", err.Path, err.Field, err.Type)
+	}
+	want := "parse error: bad price near offset 0 (line 1, column 1) of '' at $.items[3].price (decoding Item.Price of type float64)"
+	if err.Error() != want {
+		t.Errorf("Error() = %q; want %q", err.Error(), want)
+	}
+
+	l.PopPath()
+	l.PopPath()
+	l.PopPath()
+	if got := l.Path(); got != "$" {
+		t.Errorf("Path() = %q; want %q", got, "$")
+	}
+}
+
+func TestPathDeep(t *testing.T) {
+	l := Lexer{}
+	want := "$"
+	for i := 0; i < 2*pathStackSize; i++ {
+		l.PushIndex(i)
+		want += "[" + strconv.Itoa(i) + "]"
+	}
+	if got := l.Path(); got != want {
+		t.Errorf("Path() = %q; want %q", got, want)
+	}
+
+	for i := 0; i < 2*pathStackSize; i++ {
+		l.PopPath()
+	}
+	l.PushField("a", "", "")
+	if got := l.Path(); got != "$.a" {
+		t.Errorf("Path() = %q; want %q", got, "$.a")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		var l Lexer
+		for i := 0; i < pathStackSize; i++ {
+			l.PushField("a", "T.A", "int")
+		}
+		for i := 0; i < pathStackSize; i++ {
+			l.PopPath()
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("path tracking allocates %v times; want 0", allocs)
+	}
+}
+
+func TestErrorPosition(t *testing.T) {
+	data := "{\n  \"a\": 1,\n  \"b\": [1, 2,\n    x]\n}"
+	offset := strings.Index(data, "x")
+
+	for i, test := range []struct {
+		name  string
+		lexer Lexer
+	}{
+		{name: "data", lexer: Lexer{Data: []byte(data)}},
+		{name: "reader", lexer: Lexer{Reader: strings.NewReader(strings.Repeat("\n", 3*readChunkSize) + data)}},
+	} {
+		l := test.lexer
+		l.Interface()
+
+		err, ok := l.Error().(*LexerError)
+		if !ok {
+			t.Errorf("[%d, %s] Error() = %v; want *LexerError", i, test.name, l.Error())
+			continue
+		}
+
+		line, column := 4, 5
+		if test.name == "reader" {
+			line += 3 * readChunkSize
+		}
+		if err.Line != line || err.Column != column {
+			t.Errorf("[%d, %s] position = %d:%d; want %d:%d", i, test.name, err.Line, err.Column, line, column)
+		}
+		if test.name == "data" && err.Offset != offset {
+			t.Errorf("[%d, %s] Offset = %d; want %d", i, test.name, err.Offset, offset)
+		}
+	}
+}
+
+func TestErrorPositionMultiple(t *testing.T) {
+	l := Lexer{Data: []byte("[1,\n\"a\", 2,\n\n  \"b\",\n\"c\"]"), UseMultipleErrors: true}
+	l.Delim('[')
+	for !l.IsDelim(']') {
+		l.Int()
+		l.WantComma()
+	}
+	l.Delim(']')
+
+	var got [][2]int
+	for _, err := range l.GetNonFatalErrors() {
+		got = append(got, [2]int{err.Line, err.Column})
+	}
+	want := [][2]int{{2, 1}, {4, 3}, {5, 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("error positions = %v; want %v", got, want)
+	}
+
+	// An error preceding the previous one is positioned from the start of the window.
+	err := &LexerError{Offset: 1, Reason: "first"}
+	l.AddError(err)
+	if err.Line != 1 || err.Column != 2 {
+		t.Errorf("position = %d:%d; want 1:2", err.Line, err.Column)
+	}
+}
+
+func TestErrorContextAtEnd(t *testing.T) {
+	data := "[" + strings.Repeat(`"abcdefghij", `, 100) + "x]"
+
+	l := Lexer{Data: []byte(data)}
+	l.Interface()
+
+	err, ok := l.Error().(*LexerError)
+	if !ok {
+		t.Fatalf("Error() = %v; want *LexerError", l.Error())
+	}
+	if want := data[len(data)-defaultErrorContextLen:]; err.Data != want {
+		t.Errorf("Data = %q; want %q", err.Data, want)
+	}
+}
+
+func TestErrorContextLen(t *testing.T) {
+	for i, test := range []struct {
+		len  int
+		want string
+	}{
+		{len: 0, want: "xyzxyzxyz2..."},
+		{len: 6, want: "xyz..."},
+		{len: 100, want: "[1, xyzxyzxyz2345678]"},
+	} {
+		l := Lexer{Data: []byte(`[1, xyzxyzxyz2345678]`), ErrorContextLen: test.len}
+		l.Interface()
+
+		err, ok := l.Error().(*LexerError)
+		if !ok {
+			t.Errorf("[%d] Error() = %v; want *LexerError", i, l.Error())
+			continue
+		}
+		if err.Data != test.want {
+			t.Errorf("[%d] Data = %q; want %q", i, err.Data, test.want)
+		}
+	}
+}
+
+func TestProjection(t *testing.T) {
+	p, err := NewProjection("$.id", "author.name", `$.tags[*]["tag name"]`, "attrs", "attrs.color")
+	if err != nil {
+		t.Fatalf("NewProjection() error: %v", err)
+	}
+
+	l := Lexer{Projection: p}
+	for _, name := range []string{"id", "author", "tags", "attrs"} {
+		if !l.Projected(name) {
+			t.Errorf("Projected(%q) = false at %v", name, l.Path())
+		}
+	}
+	if l.Projected("title") {
+		t.Errorf("Projected(%q) = true at %v", "title", l.Path())
+	}
+
+	for _, test := range []struct {
+		path []string // Member names, "" for an array element.
+		name string
+		want bool
+	}{
+		{path: []string{"author"}, name: "name", want: true},
+		{path: []string{"author"}, name: "email", want: false},
+		{path: []string{"tags", ""}, name: "tag name", want: true},
+		{path: []string{"tags", ""}, name: "score", want: false},
+		{path: []string{"attrs"}, name: "size", want: true},
+		{path: []string{"id"}, name: "any", want: true},
+		{path: []string{"title"}, name: "any", want: false},
+	} {
+		for _, name := range test.path {
+			if name == "" {
+				l.PushIndex(0)
+			} else {
+				l.PushField(name, "", "")
+			}
+		}
+		if got := l.Projected(test.name); got != test.want {
+			t.Errorf("Projected(%q) = %v at %v; want %v", test.name, got, l.Path(), test.want)
+		}
+		for range test.path {
+			l.PopPath()
+		}
+	}
+
+	for _, paths := range [][]string{nil, {"$"}, {"id", ""}} {
+		p, err := NewProjection(paths...)
+		if err != nil {
+			t.Fatalf("NewProjection(%q) error: %v", paths, err)
+		}
+		l := Lexer{Projection: p}
+		if got, want := l.Projected("title"), len(paths) > 0; got != want {
+			t.Errorf("NewProjection(%q): Projected(%q) = %v; want %v", paths, "title", got, want)
+		}
+	}
+
+	for _, path := range []string{"a..b", "$a", "a[0]", `a["b`, `a["b"`, `a["b"]c`, "a.", "[*]a"} {
+		if _, err := NewProjection(path); err == nil {
+			t.Errorf("NewProjection(%q) succeeded; want an error", path)
+		}
+	}
+}
+
+func TestAddValidationError(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		add  func(l *Lexer, e error)
+		want []string
+	}{
+		{name: "non-fatal", add: (*Lexer).AddNonFatalError, want: []string{"a"}},
+		{name: "validation", add: (*Lexer).AddValidationError, want: []string{"a", "b"}},
+	} {
+		l := Lexer{Data: []byte(`"x"`), UseMultipleErrors: true}
+		_ = l.String()
+		for _, reason := range []string{"a", "b", "b"} {
+			test.add(&l, errors.New(reason))
+		}
+
+		var got []string
+		for _, err := range l.GetNonFatalErrors() {
+			got = append(got, err.Reason)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("[%s] reasons = %q; want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package jlexer
+
+import "encoding/json"
+
+// Member is a member of an OrderedObject.
+type Member struct {
+	Key   string
+	Value interface{}
+}
+
+// OrderedObject is an object decoded by Lexer.Interface if UseOrderedObjects is set. It keeps
+// the members in the order of the input, including duplicates.
+type OrderedObject []Member
+
+// Get returns the value of the last member with the given key.
+func (o OrderedObject) Get(key string) (value interface{}, found bool) {
+	for i := len(o) - 1; i >= 0; i-- {
+		if o[i].Key == key {
+			return o[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// MarshalJSON implements encoding/json.Marshaler interface, encoding the members in order.
+func (o OrderedObject) MarshalJSON() ([]byte, error) {
+	if o == nil {
+		return []byte("null"), nil
+	}
+
+	buf := []byte{'{'}
+	for i, m := range o {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		key, err := json.Marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, key...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+	}
+	return append(buf, '}'), nil
+}
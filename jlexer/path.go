@@ -0,0 +1,139 @@
+package jlexer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mailru/easyjson/internal/jsonpath"
+)
+
+// pathElem is an element of the path to the value being decoded: either an
+// object member or an array element.
+type pathElem struct {
+	name  string // Object member name.
+	index int    // Array element index, if name is not set.
+	isKey bool   // Whether the element is an object member.
+
+	field string // Go struct field the member is decoded into, if known.
+	typ   string // Go type the member is decoded into, if known.
+
+	projection *jsonpath.Node // Projection of the value, nil if it is decoded as a whole.
+}
+
+// pathStackSize is the depth of the path kept in the lexer itself, so that decoding values
+// nested up to it does not allocate.
+const pathStackSize = 16
+
+// pathStack is the path to the value being decoded.
+type pathStack struct {
+	elems [pathStackSize]pathElem
+	more  []pathElem // Elements beyond pathStackSize.
+	n     int
+}
+
+func (s *pathStack) push(e pathElem) {
+	if s.n < pathStackSize {
+		s.elems[s.n] = e
+	} else {
+		s.more = append(s.more, e)
+	}
+	s.n++
+}
+
+func (s *pathStack) pop() {
+	if s.n == 0 {
+		return
+	}
+	s.n--
+	if s.n >= pathStackSize {
+		s.more = s.more[:s.n-pathStackSize]
+	}
+}
+
+func (s *pathStack) at(i int) *pathElem {
+	if i < pathStackSize {
+		return &s.elems[i]
+	}
+	return &s.more[i-pathStackSize]
+}
+
+// PushField records that the value of object member name is being decoded into
+// the Go struct field (e.g. "Item.Price") of type typ. The field and the type
+// are optional. Every PushField must be followed by a PopPath call once the value
+// is decoded.
+func (r *Lexer) PushField(name, field, typ string) {
+	projection := r.projectionNode()
+	if projection != nil {
+		var ok bool
+		if projection, ok = projection.Members[name]; !ok {
+			projection = jsonpath.Excluded
+		}
+	}
+	r.path.push(pathElem{name: name, isKey: true, field: field, typ: typ, projection: projection})
+}
+
+// PushIndex records that the array element with index i is being decoded.
+// Every PushIndex must be followed by a PopPath call once the element is decoded.
+func (r *Lexer) PushIndex(i int) {
+	r.path.push(pathElem{index: i, projection: r.projectionNode()})
+}
+
+// PopPath removes the last element from the path to the value being decoded.
+func (r *Lexer) PopPath() {
+	r.path.pop()
+}
+
+// Path returns the JSON path to the value being decoded, e.g. "$.items[3].price".
+func (r *Lexer) Path() string {
+	var b strings.Builder
+	b.WriteByte('$')
+	for i := 0; i < r.path.n; i++ {
+		e := r.path.at(i)
+		switch {
+		case !e.isKey:
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(e.index))
+			b.WriteByte(']')
+		case isPathIdent(e.name):
+			b.WriteByte('.')
+			b.WriteString(e.name)
+		default:
+			b.WriteByte('[')
+			b.WriteString(strconv.Quote(e.name))
+			b.WriteByte(']')
+		}
+	}
+	return b.String()
+}
+
+// isPathIdent returns whether the object member name can be written in dot
+// notation in a JSON path.
+func isPathIdent(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// pathError sets the path to the value being decoded and the innermost Go
+// struct field being decoded in err.
+func (r *Lexer) pathError(err *LexerError) *LexerError {
+	if r.path.n == 0 || err.Path != "" {
+		return err
+	}
+
+	err.Path = r.Path()
+	for i := r.path.n - 1; i >= 0; i-- {
+		if e := r.path.at(i); e.field != "" {
+			err.Field = e.field
+			err.Type = e.typ
+			break
+		}
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package jlexer
+
+import "github.com/mailru/easyjson/internal/jsonpath"
+
+// Projection is a set of JSON paths to the values to decode. Generated decoders skip the object
+// members that are not on any of the paths without decoding them, see Lexer.Projection.
+//
+// A Projection is immutable and may be shared by concurrent lexers.
+type Projection struct {
+	root *jsonpath.Node
+}
+
+// NewProjection returns the projection decoding the values at the given paths, e.g. "$.id",
+// "author.name" or "$.tags[*].name". Paths consist of object member names, in dot notation or
+// quoted in brackets (e.g. `$["first name"]`), and may start with "$". Array elements are
+// decoded with the projection of the array, so a "[*]" element is optional. The whole value at
+// the end of a path is decoded.
+func NewProjection(paths ...string) (*Projection, error) {
+	root, err := jsonpath.NewTree(paths...)
+	if err != nil {
+		return nil, err
+	}
+	return &Projection{root: root}, nil
+}
+
+// projectionNode returns the projection of the value being decoded, nil if it is decoded as a
+// whole.
+func (r *Lexer) projectionNode() *jsonpath.Node {
+	if r.path.n > 0 {
+		return r.path.at(r.path.n - 1).projection
+	}
+	if r.Projection != nil {
+		return r.Projection.root
+	}
+	return nil
+}
+
+// Projected returns whether the member with the given name of the object being decoded is on a
+// path of the projection of the lexer. Generated decoders skip the members that are not.
+func (r *Lexer) Projected(name string) bool {
+	node := r.projectionNode()
+	if node == nil {
+		return true
+	}
+	_, ok := node.Members[name]
+	return ok
+}
@@ -0,0 +1,62 @@
+package jlexer
+
+import "time"
+
+// Time reads a string literal and parses it as a time using the given layout. A null leaves the
+// zero time.
+func (r *Lexer) Time(layout string) time.Time {
+	if r.token.kind == TokenUndef && r.Ok() {
+		r.FetchToken()
+	}
+	if r.Ok() && r.token.kind == TokenNull {
+		r.Null()
+		return time.Time{}
+	}
+
+	s := r.String()
+	if !r.Ok() {
+		return time.Time{}
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		r.addNonfatalError(&LexerError{
+			Offset: r.offset + r.start,
+			Reason: err.Error(),
+			Data:   s,
+		})
+	}
+	return t
+}
+
+// Duration reads a duration either as a string accepted by time.ParseDuration, e.g. "1h30m", or
+// as an integer number of nanoseconds. A null leaves the zero duration.
+func (r *Lexer) Duration() time.Duration {
+	if r.token.kind == TokenUndef && r.Ok() {
+		r.FetchToken()
+	}
+	if !r.Ok() {
+		return 0
+	}
+
+	switch r.token.kind {
+	case TokenNull:
+		r.Null()
+		return 0
+	case TokenNumber:
+		return time.Duration(r.Int64())
+	}
+
+	s := r.UnsafeString()
+	if !r.Ok() {
+		return 0
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		r.addNonfatalError(&LexerError{
+			Offset: r.offset + r.start,
+			Reason: err.Error(),
+			Data:   s,
+		})
+	}
+	return d
+}
@@ -0,0 +1,128 @@
+package jwriter
+
+import (
+	"errors"
+	"math/big"
+)
+
+// NumberText appends the text of a number literal as is, or formatted as float64 with the
+// Canonical flag. An invalid number sets the error.
+func (w *Writer) NumberText(s string) {
+	if !isNumber(s) {
+		if w.Error == nil {
+			w.Error = errors.New("jwriter: invalid number literal " + s)
+		}
+		return
+	}
+	if w.canonical() {
+		w.canonicalNumber(s)
+		return
+	}
+	w.Buffer.AppendString(s)
+}
+
+// BigInt appends n as a number literal, or null if n is nil. With the Canonical flag, n is
+// rounded to float64.
+func (w *Writer) BigInt(n *big.Int) {
+	if n == nil {
+		w.RawString("null")
+		return
+	}
+	if w.canonical() {
+		f, _ := new(big.Float).SetInt(n).Float64()
+		w.canonicalFloat(f)
+		return
+	}
+	w.Buffer.Buf = n.Append(w.Buffer.Buf, 10)
+}
+
+// BigIntStr appends n as a string holding a number literal, or null if n is nil.
+func (w *Writer) BigIntStr(n *big.Int) {
+	if n == nil {
+		w.RawString("null")
+		return
+	}
+	w.Buffer.Buf = append(w.Buffer.Buf, '"')
+	w.Buffer.Buf = n.Append(w.Buffer.Buf, 10)
+	w.Buffer.Buf = append(w.Buffer.Buf, '"')
+}
+
+// BigFloat appends f as a number literal with the shortest decimal text that f is parsed back
+// from at its precision, or null if f is nil. With the Canonical flag, f is rounded to float64.
+// An infinite f sets the error.
+func (w *Writer) BigFloat(f *big.Float) {
+	if f == nil {
+		w.RawString("null")
+		return
+	}
+	if f.IsInf() {
+		if w.Error == nil {
+			w.Error = errors.New("jwriter: unsupported value: " + f.String())
+		}
+		return
+	}
+	if w.canonical() {
+		f64, _ := f.Float64()
+		w.canonicalFloat(f64)
+		return
+	}
+	w.Buffer.Buf = f.Append(w.Buffer.Buf, 'g', -1)
+}
+
+// BigFloatStr appends f as a string holding a number literal, or null if f is nil. An infinite
+// f sets the error.
+func (w *Writer) BigFloatStr(f *big.Float) {
+	if f == nil {
+		w.RawString("null")
+		return
+	}
+	if f.IsInf() {
+		if w.Error == nil {
+			w.Error = errors.New("jwriter: unsupported value: " + f.String())
+		}
+		return
+	}
+	w.Buffer.Buf = append(w.Buffer.Buf, '"')
+	w.Buffer.Buf = f.Append(w.Buffer.Buf, 'g', -1)
+	w.Buffer.Buf = append(w.Buffer.Buf, '"')
+}
+
+// isNumber reports whether s is a number literal of the JSON grammar.
+func isNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(s) && s[i] == '0':
+		i++
+	case i < len(s) && s[i] >= '1' && s[i] <= '9':
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	default:
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		if i == len(s) || s[i] < '0' || s[i] > '9' {
+			return false
+		}
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if i == len(s) || s[i] < '0' || s[i] > '9' {
+			return false
+		}
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	}
+	return i == len(s)
+}
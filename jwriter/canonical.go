@@ -0,0 +1,217 @@
+package jwriter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+
+	"github.com/mailru/easyjson/buffer"
+)
+
+// canonicalObject is an object being encoded with the Canonical flag. Its members are written
+// to a buffer of their own and sorted once the object ends.
+type canonicalObject struct {
+	parent  buffer.Buffer // Buffer of the enclosing value.
+	depth   int           // Nesting level of the members.
+	members []int         // Offsets of the members in the buffer of the object.
+}
+
+// canonicalMember is a member of a canonicalObject.
+type canonicalMember struct {
+	key  []uint16 // Member name in UTF-16 code units, the sort key.
+	data []byte   // Encoded member name and value.
+}
+
+// maxExactInt is the magnitude up to which all integers are represented exactly by float64
+// values. Larger integers are written rounded to float64 in canonical JSON, like by the other
+// implementations of RFC 8785.
+const maxExactInt = 1 << 53
+
+// canonical returns true if canonical JSON is written.
+func (w *Writer) canonical() bool {
+	return w.Flags&Canonical != 0
+}
+
+// canonicalObjectStart starts an object whose members are sorted by canonicalObjectEnd.
+func (w *Writer) canonicalObjectStart() {
+	w.objects = append(w.objects, canonicalObject{parent: w.Buffer, depth: w.depth + 1})
+	w.Buffer = buffer.Buffer{}
+}
+
+// canonicalMemberStart records the start of a member of the current object.
+func (w *Writer) canonicalMemberStart() {
+	if n := len(w.objects); n > 0 && w.objects[n-1].depth == w.depth {
+		w.objects[n-1].members = append(w.objects[n-1].members, w.Buffer.Size())
+	}
+}
+
+// canonicalObjectEnd writes the members of the current object to the buffer of the enclosing
+// value, sorted by their names in UTF-16 code units as required by RFC 8785.
+func (w *Writer) canonicalObjectEnd() {
+	o := w.objects[len(w.objects)-1]
+	w.objects = w.objects[:len(w.objects)-1]
+
+	data := w.Buffer.BuildBytes()
+	w.Buffer = o.parent
+
+	bounds := append(o.members, len(data))
+	if len(o.members) == 0 || o.members[0] > 0 {
+		bounds = append([]int{0}, bounds...)
+	}
+
+	members := make([]canonicalMember, 0, len(bounds)-1)
+	for i := 0; i+1 < len(bounds); i++ {
+		m := data[bounds[i]:bounds[i+1]]
+		// Commas preceding the next member may be written either before or after the
+		// start of the member is recorded.
+		if len(m) > 0 && m[0] == ',' {
+			m = m[1:]
+		}
+		if len(m) > 0 && m[len(m)-1] == ',' {
+			m = m[:len(m)-1]
+		}
+		if len(m) == 0 {
+			continue
+		}
+		members = append(members, canonicalMember{key: memberKey(m), data: m})
+	}
+	sort.SliceStable(members, func(i, j int) bool { return lessUTF16(members[i].key, members[j].key) })
+
+	w.Buffer.AppendByte('{')
+	for i, m := range members {
+		if i > 0 {
+			w.Buffer.AppendByte(',')
+		}
+		w.Buffer.AppendBytes(m.data)
+	}
+	w.Buffer.AppendByte('}')
+}
+
+// memberKey returns the name of the encoded member m in UTF-16 code units.
+func memberKey(m []byte) []uint16 {
+	end := 1
+	for ; end < len(m) && m[end] != '"'; end++ {
+		if m[end] == '\\' {
+			end++
+		}
+	}
+	if end >= len(m) {
+		return nil
+	}
+
+	var key string
+	if err := json.Unmarshal(m[:end+1], &key); err != nil {
+		return nil
+	}
+	return utf16.Encode([]rune(key))
+}
+
+func lessUTF16(a, b []uint16) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// canonicalFloat appends the shortest representation of f that round-trips, formatted as by
+// the ECMAScript Number.prototype.toString method as required by RFC 8785.
+func (w *Writer) canonicalFloat(f float64) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		if w.Error == nil {
+			w.Error = fmt.Errorf("jwriter: unsupported value in canonical JSON: %v", f)
+		}
+		w.Buffer.AppendString("null")
+		return
+	}
+	if f == 0 {
+		// Negative zero is written as 0.
+		f = 0
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	w.Buffer.EnsureSpace(25)
+	start := len(w.Buffer.Buf)
+	w.Buffer.Buf = strconv.AppendFloat(w.Buffer.Buf, f, format, -1, 64)
+	if format == 'e' {
+		// Remove the leading zero of a two-digit exponent, e.g. 1e-07 becomes 1e-7.
+		b := w.Buffer.Buf[start:]
+		if n := len(b); n >= 4 && b[n-2] == '0' && (b[n-3] == '-' || b[n-3] == '+') && b[n-4] == 'e' {
+			b[n-2] = b[n-1]
+			w.Buffer.Buf = w.Buffer.Buf[:len(w.Buffer.Buf)-1]
+		}
+	}
+}
+
+// canonicalNumber appends the number literal s formatted as float64.
+func (w *Writer) canonicalNumber(s string) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil && !math.IsInf(f, 0) {
+		if w.Error == nil {
+			w.Error = err
+		}
+		return
+	}
+	// Numbers out of the float64 range are infinite, which canonicalFloat reports.
+	w.canonicalFloat(f)
+}
+
+// canonicalRaw appends the canonical form of the raw JSON data.
+func (w *Writer) canonicalRaw(data []byte) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		if w.Error == nil {
+			w.Error = err
+		}
+		return
+	}
+	w.canonicalValue(v)
+}
+
+// canonicalValue appends the canonical form of value v decoded by encoding/json.
+func (w *Writer) canonicalValue(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		w.RawString("null")
+	case bool:
+		w.Bool(v)
+	case string:
+		w.String(v)
+	case json.Number:
+		w.canonicalNumber(string(v))
+	case []interface{}:
+		w.ArrayStart()
+		for i, elem := range v {
+			if i > 0 {
+				w.RawByte(',')
+			}
+			w.ElemStart()
+			w.canonicalValue(elem)
+		}
+		w.ArrayEnd()
+	case map[string]interface{}:
+		w.ObjectStart()
+		first := true
+		for key, value := range v {
+			if !first {
+				w.RawByte(',')
+			}
+			first = false
+			w.ElemStart()
+			w.String(key)
+			w.Colon()
+			w.canonicalValue(value)
+		}
+		w.ObjectEnd()
+	}
+}
@@ -0,0 +1,65 @@
+package jwriter
+
+import "github.com/mailru/easyjson/internal/jsonpath"
+
+// FieldMask is a set of JSON paths to the fields to encode, e.g. the sparse fieldset of a
+// ?fields=id,name,owner.email query. Generated encoders leave out the object members that are
+// not on any of the paths, see Writer.Mask.
+//
+// A FieldMask is immutable and may be shared by concurrent writers.
+type FieldMask struct {
+	root *jsonpath.Node
+}
+
+// NewFieldMask returns the mask encoding the fields at the given paths, e.g. "id", "$.name" or
+// "owner.email". Paths consist of object member names, in dot notation or quoted in brackets
+// (e.g. `$["first name"]`), and may start with "$". Array elements are encoded with the mask
+// of the array, so a "[*]" element is optional. The whole value at the end of a path is
+// encoded.
+func NewFieldMask(paths ...string) (*FieldMask, error) {
+	root, err := jsonpath.NewTree(paths...)
+	if err != nil {
+		return nil, err
+	}
+	return &FieldMask{root: root}, nil
+}
+
+// maskNode returns the mask of the value being encoded, nil if it is encoded as a whole.
+func (w *Writer) maskNode() *jsonpath.Node {
+	if len(w.maskPath) > 0 {
+		return w.maskPath[len(w.maskPath)-1]
+	}
+	return w.Mask.root
+}
+
+// Masked returns whether the members of the object being encoded are limited by the mask of the
+// writer. Generated encoders only check the members with EnterField if they are.
+func (w *Writer) Masked() bool {
+	return w.Mask != nil && w.maskNode() != nil
+}
+
+// EnterField returns whether the member with the given name of the object being encoded is on
+// a path of the mask of the writer. If it is, the member value is encoded with the mask of
+// the member and must be followed by a LeaveField call. Generated encoders leave out the
+// members that are not.
+func (w *Writer) EnterField(name string) bool {
+	if w.Mask == nil {
+		return true
+	}
+	node := w.maskNode()
+	if node != nil {
+		var ok bool
+		if node, ok = node.Members[name]; !ok {
+			return false
+		}
+	}
+	w.maskPath = append(w.maskPath, node)
+	return true
+}
+
+// LeaveField must be called once the value of a member entered with EnterField is encoded.
+func (w *Writer) LeaveField() {
+	if w.Mask != nil && len(w.maskPath) > 0 {
+		w.maskPath = w.maskPath[:len(w.maskPath)-1]
+	}
+}
@@ -0,0 +1,34 @@
+package jwriter
+
+import (
+	"errors"
+	"time"
+)
+
+// Time appends t formatted with the given layout as a string. Like time.Time.MarshalJSON, it
+// fails for RFC 3339 layouts if the year is outside of the range [0,9999].
+func (w *Writer) Time(t time.Time, layout string) {
+	if layout != time.RFC3339 && layout != time.RFC3339Nano {
+		w.String(t.Format(layout))
+		return
+	}
+	if y := t.Year(); y < 0 || y >= 10000 {
+		if w.Error == nil {
+			w.Error = errors.New("Time.MarshalJSON: year outside of range [0,9999]")
+		}
+		return
+	}
+
+	w.Buffer.EnsureSpace(len(time.RFC3339Nano) + 2)
+	w.Buffer.Buf = append(w.Buffer.Buf, '"')
+	w.Buffer.Buf = t.AppendFormat(w.Buffer.Buf, layout)
+	w.Buffer.Buf = append(w.Buffer.Buf, '"')
+}
+
+// Duration appends d as a string in the form produced by time.Duration.String, e.g. "1h30m0s".
+func (w *Writer) Duration(d time.Duration) {
+	w.Buffer.EnsureSpace(len("-2562047h47m16.854775808s") + 2)
+	w.Buffer.Buf = append(w.Buffer.Buf, '"')
+	w.Buffer.Buf = append(w.Buffer.Buf, d.String()...)
+	w.Buffer.Buf = append(w.Buffer.Buf, '"')
+}
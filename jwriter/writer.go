@@ -2,11 +2,15 @@
 package jwriter
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 	"strconv"
+	"strings"
 	"unicode/utf8"
 
 	"github.com/mailru/easyjson/buffer"
+	"github.com/mailru/easyjson/internal/jsonpath"
 )
 
 // Flags describe various encoding options. The behavior may be actually implemented in the encoder, but
@@ -16,15 +20,38 @@ type Flags int
 const (
 	NilMapAsEmpty   Flags = 1 << iota // Encode nil map as '{}' rather than 'null'.
 	NilSliceAsEmpty                   // Encode nil slice as '[]' rather than 'null'.
+
+	// Canonical makes the output canonical JSON (RFC 8785), e.g. to sign it: object members
+	// are sorted by name, numbers are formatted as in ECMAScript and strings are escaped
+	// minimally. The output is not indented.
+	Canonical
 )
 
 // Writer is a JSON writer.
+//
+// If Prefix or Indent is set, the output is indented: each element of an array or an object
+// begins on a new line starting with Prefix followed by one copy of Indent per nesting level,
+// as in encoding/json.MarshalIndent.
 type Writer struct {
 	Flags Flags
 
 	Error        error
 	Buffer       buffer.Buffer
 	NoEscapeHTML bool
+
+	Prefix string
+	Indent string
+
+	// Mask limits encoding to the fields at its paths, all fields are encoded if nil. It is
+	// only applied by the encoders generated with the -field_mask option.
+	Mask *FieldMask
+
+	depth int  // Nesting level of the current array or object.
+	empty bool // Whether no elements were written to the current array or object yet.
+
+	maskPath []*jsonpath.Node // Masks of the members being encoded, maintained by the encoders.
+
+	objects []canonicalObject // Objects being encoded with the Canonical flag.
 }
 
 // Size returns the size of the data that was written out.
@@ -69,12 +96,19 @@ func (w *Writer) RawString(s string) {
 
 // Raw appends raw binary data to the buffer or sets the error if it is given. Useful for
 // calling with results of MarshalJSON-like functions.
+//
+// If the output is indented, the data is reindented to match the current nesting level. If
+// the Canonical flag is set, the data is canonicalized.
 func (w *Writer) Raw(data []byte, err error) {
 	switch {
 	case w.Error != nil:
 		return
 	case err != nil:
 		w.Error = err
+	case len(data) > 0 && w.canonical():
+		w.canonicalRaw(data)
+	case len(data) > 0 && w.indented():
+		w.rawIndent(data)
 	case len(data) > 0:
 		w.Buffer.AppendBytes(data)
 	default:
@@ -82,6 +116,115 @@ func (w *Writer) Raw(data []byte, err error) {
 	}
 }
 
+// rawIndent appends raw JSON data to the buffer, indenting it according to the current
+// nesting level. The data is appended as is if it is not valid JSON.
+func (w *Writer) rawIndent(data []byte) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, w.Prefix+strings.Repeat(w.Indent, w.depth), w.Indent); err != nil {
+		w.Buffer.AppendBytes(data)
+		return
+	}
+	w.Buffer.AppendBytes(buf.Bytes())
+}
+
+// indented returns true if the output is indented.
+func (w *Writer) indented() bool {
+	return (w.Prefix != "" || w.Indent != "") && !w.canonical()
+}
+
+// newline starts a new line indented according to the current nesting level.
+func (w *Writer) newline() {
+	w.Buffer.AppendByte('\n')
+	w.Buffer.AppendString(w.Prefix)
+	for i := 0; i < w.depth; i++ {
+		w.Buffer.AppendString(w.Indent)
+	}
+}
+
+// ObjectStart writes the opening brace of an object.
+func (w *Writer) ObjectStart() {
+	if w.canonical() {
+		w.canonicalObjectStart()
+	} else {
+		w.Buffer.AppendByte('{')
+	}
+	w.depth++
+	w.empty = true
+}
+
+// ObjectEnd writes the closing brace of an object.
+func (w *Writer) ObjectEnd() {
+	w.depth--
+	if w.indented() && !w.empty {
+		w.newline()
+	}
+	w.empty = false
+	if w.canonical() && len(w.objects) > 0 {
+		w.canonicalObjectEnd()
+	} else {
+		w.Buffer.AppendByte('}')
+	}
+}
+
+// ArrayStart writes the opening bracket of an array.
+func (w *Writer) ArrayStart() {
+	w.Buffer.AppendByte('[')
+	w.depth++
+	w.empty = true
+}
+
+// ArrayEnd writes the closing bracket of an array.
+func (w *Writer) ArrayEnd() {
+	w.depth--
+	if w.indented() && !w.empty {
+		w.newline()
+	}
+	w.empty = false
+	w.Buffer.AppendByte(']')
+}
+
+// ElemStart must be called before writing each element of an array or each member name
+// of an object, after the separating comma.
+func (w *Writer) ElemStart() {
+	if w.canonical() {
+		w.canonicalMemberStart()
+	}
+	if w.indented() {
+		w.empty = false
+		w.newline()
+	}
+}
+
+// Colon writes a colon separating an object member name from its value.
+func (w *Writer) Colon() {
+	if w.indented() {
+		w.Buffer.AppendString(": ")
+	} else {
+		w.Buffer.AppendByte(':')
+	}
+}
+
+// RawField appends a pre-encoded object member name followed by a colon, and optionally
+// preceded by a comma, e.g. `,"name":`. It can be used instead of RawString, ElemStart and
+// Colon calls.
+func (w *Writer) RawField(s string) {
+	if w.canonical() {
+		w.canonicalMemberStart()
+	}
+	if !w.indented() {
+		w.Buffer.AppendString(s)
+		return
+	}
+
+	if s[0] == ',' {
+		w.Buffer.AppendByte(',')
+		s = s[1:]
+	}
+	w.ElemStart()
+	w.Buffer.AppendString(s[:len(s)-1])
+	w.Colon()
+}
+
 // RawText encloses raw binary data in quotes and appends in to the buffer.
 // Useful for calling with results of MarshalText-like functions.
 func (w *Writer) RawText(data []byte, err error) {
@@ -124,11 +267,19 @@ func (w *Writer) Uint32(n uint32) {
 }
 
 func (w *Writer) Uint(n uint) {
+	if uint64(n) > maxExactInt && w.canonical() {
+		w.canonicalFloat(float64(n))
+		return
+	}
 	w.Buffer.EnsureSpace(20)
 	w.Buffer.Buf = strconv.AppendUint(w.Buffer.Buf, uint64(n), 10)
 }
 
 func (w *Writer) Uint64(n uint64) {
+	if n > maxExactInt && w.canonical() {
+		w.canonicalFloat(float64(n))
+		return
+	}
 	w.Buffer.EnsureSpace(20)
 	w.Buffer.Buf = strconv.AppendUint(w.Buffer.Buf, n, 10)
 }
@@ -149,11 +300,19 @@ func (w *Writer) Int32(n int32) {
 }
 
 func (w *Writer) Int(n int) {
+	if (int64(n) > maxExactInt || int64(n) < -maxExactInt) && w.canonical() {
+		w.canonicalFloat(float64(n))
+		return
+	}
 	w.Buffer.EnsureSpace(21)
 	w.Buffer.Buf = strconv.AppendInt(w.Buffer.Buf, int64(n), 10)
 }
 
 func (w *Writer) Int64(n int64) {
+	if (n > maxExactInt || n < -maxExactInt) && w.canonical() {
+		w.canonicalFloat(float64(n))
+		return
+	}
 	w.Buffer.EnsureSpace(21)
 	w.Buffer.Buf = strconv.AppendInt(w.Buffer.Buf, n, 10)
 }
@@ -236,6 +395,13 @@ func (w *Writer) Int64Str(n int64) {
 }
 
 func (w *Writer) Float32(n float32) {
+	if w.canonical() {
+		// The number is formatted as the float64 value of its shortest float32 representation,
+		// which is what the readers of the output decode.
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(n), 'g', -1, 32), 64)
+		w.canonicalFloat(f)
+		return
+	}
 	w.Buffer.EnsureSpace(20)
 	w.Buffer.Buf = strconv.AppendFloat(w.Buffer.Buf, float64(n), 'g', -1, 32)
 }
@@ -248,6 +414,10 @@ func (w *Writer) Float32Str(n float32) {
 }
 
 func (w *Writer) Float64(n float64) {
+	if w.canonical() {
+		w.canonicalFloat(n)
+		return
+	}
 	w.Buffer.EnsureSpace(20)
 	w.Buffer.Buf = strconv.AppendFloat(w.Buffer.Buf, n, 'g', -1, 64)
 }
@@ -297,8 +467,9 @@ func (w *Writer) String(s string) {
 
 	p := 0 // last non-escape symbol
 
+	canonical := w.canonical()
 	escapeTable := &htmlEscapeTable
-	if w.NoEscapeHTML {
+	if w.NoEscapeHTML || canonical {
 		escapeTable = &htmlNoEscapeTable
 	}
 
@@ -324,6 +495,17 @@ func (w *Writer) String(s string) {
 				w.Buffer.AppendString(`\\`)
 			case '"':
 				w.Buffer.AppendString(`\"`)
+			case '\b', '\f':
+				switch {
+				case !canonical:
+					w.Buffer.AppendString(`\u00`)
+					w.Buffer.AppendByte(chars[c>>4])
+					w.Buffer.AppendByte(chars[c&0xf])
+				case c == '\b':
+					w.Buffer.AppendString(`\b`)
+				default:
+					w.Buffer.AppendString(`\f`)
+				}
 			default:
 				w.Buffer.AppendString(`\u00`)
 				w.Buffer.AppendByte(chars[c>>4])
@@ -346,7 +528,7 @@ func (w *Writer) String(s string) {
 		}
 
 		// jsonp stuff - tab separator and line separator
-		if runeValue == '\u2028' || runeValue == '\u2029' {
+		if (runeValue == '\u2028' || runeValue == '\u2029') && !canonical {
 			w.Buffer.AppendString(s[p:i])
 			w.Buffer.AppendString(`\u202`)
 			w.Buffer.AppendByte(chars[runeValue&0xf])
@@ -0,0 +1,265 @@
+package easyjson
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+// Value is a lazily decoded JSON value. It keeps the raw bytes of the value, which are output
+// as is during marshaling, and decodes them only when requested: the members of an object or
+// the elements of an array are indexed on the first Get or Index call, and scalar values are
+// decoded by the Int64, Float64, String etc. methods. For example, a message can be routed by
+// the value of one of its members and re-emitted untouched:
+//
+//	var msg easyjson.Value
+//	if err := easyjson.Unmarshal(data, &msg); err != nil {
+//		...
+//	}
+//	kind, err := msg.Get("meta", "kind").String()
+//
+// Values share the bytes they are decoded from. A Value is not safe for concurrent use, as the
+// index is built on demand.
+type Value struct {
+	raw []byte
+	err error // Set for the values returned for missing members and elements.
+
+	indexed bool
+	names   []string // Member names of an object, nil for an array.
+	elems   []*Value // Member values of an object or elements of an array.
+}
+
+// NewValue returns the value holding the JSON in data. The data is not validated until it is
+// decoded.
+func NewValue(data []byte) *Value {
+	return &Value{raw: bytes.TrimSpace(data)}
+}
+
+// Raw returns the raw bytes of the value, nil for a missing value.
+func (v *Value) Raw() []byte {
+	return v.raw
+}
+
+// Exists returns whether the value was found, i.e. whether it was not returned by Get or Index
+// for a missing member or element.
+func (v *Value) Exists() bool {
+	return v.err == nil && len(v.raw) > 0
+}
+
+// IsNull returns whether the value is a JSON null.
+func (v *Value) IsNull() bool {
+	return string(v.raw) == "null"
+}
+
+// index indexes the members of an object or the elements of an array.
+func (v *Value) index() error {
+	if v.err != nil || v.indexed {
+		return v.err
+	}
+
+	l := jlexer.Lexer{Data: v.raw}
+	switch {
+	case l.IsDelim('{'):
+		l.Delim('{')
+		for !l.IsDelim('}') && l.Ok() {
+			name := l.String()
+			l.WantColon()
+			v.names = append(v.names, name)
+			v.elems = append(v.elems, &Value{raw: l.Raw()})
+			l.WantComma()
+		}
+		l.Delim('}')
+	case l.IsDelim('['):
+		l.Delim('[')
+		for !l.IsDelim(']') && l.Ok() {
+			v.elems = append(v.elems, &Value{raw: l.Raw()})
+			l.WantComma()
+		}
+		l.Delim(']')
+	default:
+		l.SkipRecursive()
+	}
+	l.Consumed()
+	if err := l.Error(); err != nil {
+		v.names, v.elems = nil, nil
+		return err
+	}
+	v.indexed = true
+	return nil
+}
+
+// Get returns the value at the path of object member names, e.g. Get("a", "b") returns the
+// member "b" of the object that is the member "a" of v. If there are duplicate members, the
+// last one is returned. For a missing member, the returned value does not exist and its
+// methods return an error.
+func (v *Value) Get(path ...string) *Value {
+	for _, name := range path {
+		if err := v.index(); err != nil {
+			return &Value{err: err}
+		}
+		if !v.isObject() {
+			return &Value{err: fmt.Errorf("easyjson: member %q not found, the value is not an object", name)}
+		}
+
+		found := false
+		for i := len(v.names) - 1; i >= 0; i-- {
+			if v.names[i] == name {
+				v, found = v.elems[i], true
+				break
+			}
+		}
+		if !found {
+			return &Value{err: fmt.Errorf("easyjson: member %q not found", name)}
+		}
+	}
+	return v
+}
+
+// Index returns the element with index i of an array. For a missing element, the returned
+// value does not exist and its methods return an error.
+func (v *Value) Index(i int) *Value {
+	if err := v.index(); err != nil {
+		return &Value{err: err}
+	}
+	if !v.isArray() {
+		return &Value{err: fmt.Errorf("easyjson: element %d not found, the value is not an array", i)}
+	}
+	if i < 0 || i >= len(v.elems) {
+		return &Value{err: fmt.Errorf("easyjson: element %d not found, the array has %d elements", i, len(v.elems))}
+	}
+	return v.elems[i]
+}
+
+// Len returns the number of members of an object or elements of an array.
+func (v *Value) Len() (int, error) {
+	if err := v.index(); err != nil {
+		return 0, err
+	}
+	if !v.isObject() && !v.isArray() {
+		return 0, fmt.Errorf("easyjson: %s is not an object or an array", v.quote())
+	}
+	return len(v.elems), nil
+}
+
+// Keys returns the member names of an object in the order of the input.
+func (v *Value) Keys() ([]string, error) {
+	if err := v.index(); err != nil {
+		return nil, err
+	}
+	if !v.isObject() {
+		return nil, fmt.Errorf("easyjson: %s is not an object", v.quote())
+	}
+	return v.names, nil
+}
+
+func (v *Value) isObject() bool {
+	return len(v.raw) > 0 && v.raw[0] == '{'
+}
+
+func (v *Value) isArray() bool {
+	return len(v.raw) > 0 && v.raw[0] == '['
+}
+
+// decode decodes the value with function f.
+func (v *Value) decode(f func(l *jlexer.Lexer)) error {
+	if v.err != nil {
+		return v.err
+	}
+	l := jlexer.Lexer{Data: v.raw}
+	f(&l)
+	l.Consumed()
+	return l.Error()
+}
+
+// String decodes a string value.
+func (v *Value) String() (s string, err error) {
+	err = v.decode(func(l *jlexer.Lexer) { s = l.String() })
+	return s, err
+}
+
+// Bool decodes a boolean value.
+func (v *Value) Bool() (b bool, err error) {
+	err = v.decode(func(l *jlexer.Lexer) { b = l.Bool() })
+	return b, err
+}
+
+// Int decodes an integer value.
+func (v *Value) Int() (n int, err error) {
+	err = v.decode(func(l *jlexer.Lexer) { n = l.Int() })
+	return n, err
+}
+
+// Int64 decodes an integer value.
+func (v *Value) Int64() (n int64, err error) {
+	err = v.decode(func(l *jlexer.Lexer) { n = l.Int64() })
+	return n, err
+}
+
+// Uint64 decodes an unsigned integer value.
+func (v *Value) Uint64() (n uint64, err error) {
+	err = v.decode(func(l *jlexer.Lexer) { n = l.Uint64() })
+	return n, err
+}
+
+// Float64 decodes a number value.
+func (v *Value) Float64() (n float64, err error) {
+	err = v.decode(func(l *jlexer.Lexer) { n = l.Float64() })
+	return n, err
+}
+
+// Interface decodes the value into an interface{} analogous to the 'encoding/json' package.
+func (v *Value) Interface() (i interface{}, err error) {
+	err = v.decode(func(l *jlexer.Lexer) { i = l.Interface() })
+	return i, err
+}
+
+// Unmarshal decodes the value into the object.
+func (v *Value) Unmarshal(u Unmarshaler) error {
+	return v.decode(u.UnmarshalEasyJSON)
+}
+
+// MarshalEasyJSON does JSON marshaling using easyjson interface. The raw bytes of the value
+// are output as is.
+func (v *Value) MarshalEasyJSON(w *jwriter.Writer) {
+	if len(v.raw) == 0 {
+		w.RawString("null")
+	} else {
+		w.Raw(v.raw, nil)
+	}
+}
+
+// UnmarshalEasyJSON does JSON unmarshaling using easyjson interface. The value is not decoded
+// until it is accessed.
+func (v *Value) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	*v = Value{raw: l.Raw()}
+}
+
+// MarshalJSON implements encoding/json.Marshaler interface.
+func (v Value) MarshalJSON() ([]byte, error) {
+	if len(v.raw) == 0 {
+		return nullBytes, nil
+	}
+	return v.raw, nil
+}
+
+// UnmarshalJSON implements encoding/json.Unmarshaler interface.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	*v = Value{raw: append([]byte(nil), data...)}
+	return nil
+}
+
+// IsDefined is required for integration with omitempty easyjson logic.
+func (v *Value) IsDefined() bool {
+	return len(v.raw) > 0
+}
+
+// quote returns the raw bytes of the value for error messages, truncated if they are long.
+func (v *Value) quote() string {
+	if len(v.raw) > 32 {
+		return strconv.Quote(string(v.raw[:32])) + "..."
+	}
+	return strconv.Quote(string(v.raw))
+}
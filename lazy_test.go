@@ -0,0 +1,122 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mailru/easyjson/jwriter"
+)
+
+const lazyTestData = ` {"meta": {"kind": "order", "id": 42, "tags": ["a", "b"]}, "price": 9.5,
+  "ok": true, "none": null, "name": "x", "name": "y", "payload": {"deep": [1, {"z": "é"}]}} `
+
+func TestValueGet(t *testing.T) {
+	var v Value
+	if err := Unmarshal([]byte(lazyTestData), &v); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+
+	if got, err := v.Get("meta", "kind").String(); err != nil || got != "order" {
+		t.Errorf(`Get("meta", "kind").String() = %q, %v; want "order"`, got, err)
+	}
+	if got, err := v.Get("meta", "id").Int(); err != nil || got != 42 {
+		t.Errorf(`Get("meta", "id").Int() = %v, %v; want 42`, got, err)
+	}
+	if got, err := v.Get("meta", "tags").Index(1).String(); err != nil || got != "b" {
+		t.Errorf(`Get("meta", "tags").Index(1).String() = %q, %v; want "b"`, got, err)
+	}
+	if got, err := v.Get("price").Float64(); err != nil || got != 9.5 {
+		t.Errorf(`Get("price").Float64() = %v, %v; want 9.5`, got, err)
+	}
+	if got, err := v.Get("ok").Bool(); err != nil || !got {
+		t.Errorf(`Get("ok").Bool() = %v, %v; want true`, got, err)
+	}
+	if !v.Get("none").IsNull() || !v.Get("none").Exists() {
+		t.Errorf(`Get("none") is not an existing null`)
+	}
+	if got, err := v.Get("name").String(); err != nil || got != "y" {
+		t.Errorf(`Get("name").String() = %q, %v; want the last duplicate "y"`, got, err)
+	}
+	if got, err := v.Get("payload", "deep").Index(1).Get("z").String(); err != nil || got != "é" {
+		t.Errorf(`Get("payload", "deep").Index(1).Get("z").String() = %q, %v; want "é"`, got, err)
+	}
+	if got, want := string(v.Get("payload").Raw()), `{"deep": [1, {"z": "é"}]}`; got != want {
+		t.Errorf(`Get("payload").Raw() = %s; want %s`, got, want)
+	}
+	if got, err := v.Get("meta", "tags").Interface(); err != nil || !reflect.DeepEqual(got, []interface{}{"a", "b"}) {
+		t.Errorf(`Get("meta", "tags").Interface() = %v, %v; want [a b]`, got, err)
+	}
+
+	if n, err := v.Len(); err != nil || n != 7 {
+		t.Errorf("Len() = %v, %v; want 7", n, err)
+	}
+	if keys, err := v.Get("meta").Keys(); err != nil || !reflect.DeepEqual(keys, []string{"kind", "id", "tags"}) {
+		t.Errorf(`Get("meta").Keys() = %q, %v`, keys, err)
+	}
+
+	for _, missing := range []*Value{
+		v.Get("missing"),
+		v.Get("meta", "missing", "kind"),
+		v.Get("price", "x"),
+		v.Get("meta", "tags").Index(2),
+		v.Index(0),
+	} {
+		if missing.Exists() {
+			t.Errorf("missing value %s exists", missing.Raw())
+		}
+		if _, err := missing.Int(); err == nil {
+			t.Errorf("Int() of a missing value succeeded")
+		}
+	}
+	if _, err := v.Get("meta", "kind").Int(); err == nil {
+		t.Errorf(`Get("meta", "kind").Int() succeeded for a string`)
+	}
+}
+
+func TestValuePassthrough(t *testing.T) {
+	v := NewValue([]byte(lazyTestData))
+	if kind, err := v.Get("meta", "kind").String(); err != nil || kind != "order" {
+		t.Fatalf(`Get("meta", "kind").String() = %q, %v`, kind, err)
+	}
+
+	w := jwriter.Writer{}
+	w.RawString(`{"routed":`)
+	v.Get("payload").MarshalEasyJSON(&w)
+	w.RawString(`}`)
+	got, err := w.BuildBytes()
+	if err != nil {
+		t.Fatalf("MarshalEasyJSON() error: %v", err)
+	}
+	if want := `{"routed":{"deep": [1, {"z": "é"}]}}`; string(got) != want {
+		t.Errorf("MarshalEasyJSON() = %s; want %s", got, want)
+	}
+
+	var s struct {
+		A Value `json:"a"`
+		B Value `json:"b,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(`{"a": [1, 2]}`), &s); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if n, err := s.A.Len(); err != nil || n != 2 {
+		t.Errorf("Len() = %v, %v; want 2", n, err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	if want := `{"a":[1,2],"b":null}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s; want %s", data, want)
+	}
+}
+
+func TestValueInvalid(t *testing.T) {
+	v := NewValue([]byte(`{"a": 1,}`))
+	if _, err := v.Get("a").Int(); err == nil {
+		t.Errorf("Get() of invalid JSON succeeded")
+	}
+	if err := Unmarshal([]byte(`{"a": 1`), &Value{}); err == nil {
+		t.Errorf("Unmarshal() of truncated JSON succeeded")
+	}
+}
@@ -0,0 +1,163 @@
+package easyjson
+
+import (
+	"encoding/json"
+
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+// OrderedMap is a JSON object that keeps its keys in insertion order, which is the order of
+// the input for decoded objects. It is encoded in the same order.
+//
+// Values of decoded members are decoded like by Lexer.Interface, except that objects are
+// decoded as *OrderedMap, also when nested in arrays. The zero value is an empty map ready to
+// use, encoded as null unless the NilMapAsEmpty flag of the writer is set.
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// Len returns the number of keys in the map.
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Keys returns the keys of the map in insertion order. The returned slice must not be
+// modified.
+func (m *OrderedMap) Keys() []string {
+	return m.keys
+}
+
+// Get returns the value of the key.
+func (m *OrderedMap) Get(key string) (value interface{}, found bool) {
+	value, found = m.values[key]
+	return value, found
+}
+
+// Set sets the value of the key. A new key is added after the existing ones, while an
+// existing key keeps its position.
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if m.values == nil {
+		m.values = make(map[string]interface{})
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Delete removes the key from the map.
+func (m *OrderedMap) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// MarshalEasyJSON does JSON marshaling using easyjson interface.
+func (m *OrderedMap) MarshalEasyJSON(w *jwriter.Writer) {
+	if m.values == nil && (w.Flags&jwriter.NilMapAsEmpty) == 0 {
+		w.RawString("null")
+		return
+	}
+
+	w.ObjectStart()
+	for i, key := range m.keys {
+		if i > 0 {
+			w.RawByte(',')
+		}
+		w.ElemStart()
+		w.String(key)
+		w.Colon()
+		marshalOrderedValue(w, m.values[key])
+	}
+	w.ObjectEnd()
+}
+
+// marshalOrderedValue encodes a value of an OrderedMap.
+func marshalOrderedValue(w *jwriter.Writer, v interface{}) {
+	switch v := v.(type) {
+	case Marshaler:
+		v.MarshalEasyJSON(w)
+	case json.Marshaler:
+		w.Raw(v.MarshalJSON())
+	case []interface{}:
+		if v == nil && (w.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			w.RawString("null")
+			return
+		}
+		w.ArrayStart()
+		for i, elem := range v {
+			if i > 0 {
+				w.RawByte(',')
+			}
+			w.ElemStart()
+			marshalOrderedValue(w, elem)
+		}
+		w.ArrayEnd()
+	default:
+		w.Raw(json.Marshal(v))
+	}
+}
+
+// UnmarshalEasyJSON does JSON unmarshaling using easyjson interface.
+func (m *OrderedMap) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	*m = OrderedMap{}
+	if l.IsNull() {
+		l.Skip()
+		return
+	}
+
+	m.values = make(map[string]interface{})
+	l.Delim('{')
+	for !l.IsDelim('}') && l.Ok() {
+		key := l.String()
+		l.WantColon()
+		m.Set(key, unmarshalOrderedValue(l))
+		l.WantComma()
+	}
+	l.Delim('}')
+}
+
+// unmarshalOrderedValue decodes a value of an OrderedMap.
+func unmarshalOrderedValue(l *jlexer.Lexer) interface{} {
+	switch {
+	case l.IsDelim('{'):
+		m := &OrderedMap{}
+		m.UnmarshalEasyJSON(l)
+		return m
+	case l.IsDelim('['):
+		a := []interface{}{}
+		l.Delim('[')
+		for !l.IsDelim(']') && l.Ok() {
+			a = append(a, unmarshalOrderedValue(l))
+			l.WantComma()
+		}
+		l.Delim(']')
+		return a
+	default:
+		return l.Interface()
+	}
+}
+
+// MarshalJSON implements encoding/json.Marshaler interface.
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	m.MarshalEasyJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements encoding/json.Unmarshaler interface.
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	l := jlexer.Lexer{Data: data}
+	m.UnmarshalEasyJSON(&l)
+	l.Consumed()
+	return l.Error()
+}
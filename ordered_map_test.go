@@ -0,0 +1,79 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mailru/easyjson/jwriter"
+)
+
+func TestOrderedMap(t *testing.T) {
+	data := `{"z":1,"a":{"y":[{"c":null,"b":"x"}],"x":true},"m":2.5,"z":3}`
+
+	var m OrderedMap
+	if err := Unmarshal([]byte(data), &m); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if got, want := m.Keys(), []string{"z", "a", "m"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %q; want %q", got, want)
+	}
+	if v, ok := m.Get("z"); !ok || v != float64(3) {
+		t.Errorf("Get(%q) = %v, %v; want the last value 3", "z", v, ok)
+	}
+	a, _ := m.Get("a")
+	if got, want := a.(*OrderedMap).Keys(), []string{"y", "x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("nested Keys() = %q; want %q", got, want)
+	}
+
+	out, err := Marshal(&m)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	if want := `{"z":3,"a":{"y":[{"c":null,"b":"x"}],"x":true},"m":2.5}`; string(out) != want {
+		t.Errorf("Marshal() = %s; want %s", out, want)
+	}
+
+	m.Delete("z")
+	m.Delete("missing")
+	m.Set("m", "s")
+	m.Set("b", []int{1})
+	if m.Len() != 3 {
+		t.Errorf("Len() = %d; want 3", m.Len())
+	}
+	out, err = json.Marshal(&m)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	if want := `{"a":{"y":[{"c":null,"b":"x"}],"x":true},"m":"s","b":[1]}`; string(out) != want {
+		t.Errorf("json.Marshal() = %s; want %s", out, want)
+	}
+
+	var j OrderedMap
+	if err := json.Unmarshal([]byte(`{"b":1,"a":2}`), &j); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if got, want := j.Keys(), []string{"b", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %q; want %q", got, want)
+	}
+}
+
+func TestOrderedMapZero(t *testing.T) {
+	var m OrderedMap
+	if out, err := Marshal(&m); err != nil || string(out) != "null" {
+		t.Errorf("Marshal() = %s, %v; want null", out, err)
+	}
+	w := jwriter.Writer{Flags: jwriter.NilMapAsEmpty}
+	m.MarshalEasyJSON(&w)
+	if out, err := w.BuildBytes(); err != nil || string(out) != "{}" {
+		t.Errorf("MarshalEasyJSON() with NilMapAsEmpty = %s, %v; want {}", out, err)
+	}
+
+	m.Set("a", 1)
+	if err := Unmarshal([]byte("null"), &m); err != nil || m.Len() != 0 {
+		t.Errorf("Unmarshal(null) = %v, %d keys; want an empty map", err, m.Len())
+	}
+	if err := Unmarshal([]byte(`{"a":1,}`), &m); err == nil {
+		t.Errorf("Unmarshal() of invalid JSON succeeded")
+	}
+}
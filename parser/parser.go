@@ -5,32 +5,68 @@ import (
 	"go/parser"
 	"go/token"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
 const (
-	structComment     = "easyjson:json"
-	structSkipComment = "easyjson:skip"
+	structComment          = "easyjson:json"
+	structSkipComment      = "easyjson:skip"
+	caseInsensitiveComment = "easyjson:case_insensitive"
+	unionComment           = "easyjson:union"
+	enumComment            = "easyjson:enum"
+	enumNameComment        = "easyjson:name"
 )
 
+// qualifiedIdentRegexp matches package-qualified identifiers in type arguments.
+var qualifiedIdentRegexp = regexp.MustCompile(`\b([A-Za-z_]\w*)\.[A-Za-z_]\w*`)
+
 type Parser struct {
 	PkgPath     string
 	PkgName     string
 	StructNames []string
 	AllStructs  bool
+
+	// CaseInsensitiveNames lists the types of StructNames marked with the
+	// easyjson:case_insensitive directive.
+	CaseInsensitiveNames []string
+
+	// Unions maps the interface types marked with the easyjson:union
+	// directive to the directive arguments: the discriminator key followed by
+	// value=Type pairs, e.g. 'type circle=Circle square=*Square'.
+	Unions map[string]string
+
+	// Enums maps the integer types marked with the easyjson:enum directive to
+	// the directive arguments, e.g. 'trim_prefix=Color snake_case'.
+	Enums map[string]string
+
+	// EnumConsts maps the types of Enums to their constants in declaration
+	// order, either 'Name' or 'Name=json' for the constants renamed with the
+	// easyjson:name directive.
+	EnumConsts map[string][]string
+
+	// Imports lists the import specs (in `name "path"` form) referenced by
+	// type arguments of generic type instantiations.
+	Imports []string
 }
 
 type visitor struct {
 	*Parser
 
-	name string
+	name            string
+	caseInsensitive bool
+	imports         map[string]string
 }
 
-func (p *Parser) needType(comments *ast.CommentGroup) (skip, explicit bool) {
+// commentLines returns the trimmed lines of a comment group with comment
+// markers stripped.
+func commentLines(comments *ast.CommentGroup) []string {
 	if comments == nil {
-		return
+		return nil
 	}
 
+	var lines []string
 	for _, v := range comments.List {
 		comment := v.Text
 
@@ -46,18 +82,170 @@ func (p *Parser) needType(comments *ast.CommentGroup) (skip, explicit bool) {
 		}
 
 		for _, comment := range strings.Split(comment, "\n") {
-			comment = strings.TrimSpace(comment)
+			lines = append(lines, strings.TrimSpace(comment))
+		}
+	}
+	return lines
+}
+
+func (p *Parser) needType(comments *ast.CommentGroup) (skip, explicit bool) {
+	for _, comment := range commentLines(comments) {
+		if strings.HasPrefix(comment, structSkipComment) {
+			return true, false
+		}
+		if strings.HasPrefix(comment, structComment) {
+			return false, true
+		}
+	}
+	return
+}
 
-			if strings.HasPrefix(comment, structSkipComment) {
-				return true, false
+// hasDirective reports whether the comments contain a line starting with the
+// given directive.
+func hasDirective(comments *ast.CommentGroup, directive string) bool {
+	for _, comment := range commentLines(comments) {
+		if strings.HasPrefix(comment, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// union returns the arguments of the easyjson:union directive.
+func union(comments *ast.CommentGroup) (string, bool) {
+	for _, comment := range commentLines(comments) {
+		if strings.HasPrefix(comment, unionComment+" ") {
+			return strings.TrimSpace(comment[len(unionComment):]), true
+		}
+	}
+	return "", false
+}
+
+// directiveArgs returns the arguments of the given directive, which may have
+// none.
+func directiveArgs(comments *ast.CommentGroup, directive string) (string, bool) {
+	for _, comment := range commentLines(comments) {
+		if comment == directive || strings.HasPrefix(comment, directive+" ") {
+			return strings.TrimSpace(comment[len(directive):]), true
+		}
+	}
+	return "", false
+}
+
+// addConsts records the typed constants declared by a const declaration, the
+// candidates for the constants of enum types.
+func (v *visitor) addConsts(decl *ast.GenDecl) {
+	var typ string
+	for _, spec := range decl.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		// A spec without a type and values repeats the previous one.
+		switch ident, ok := vs.Type.(*ast.Ident); {
+		case ok:
+			typ = ident.Name
+		case vs.Type != nil || len(vs.Values) > 0:
+			typ = ""
+		}
+		if typ == "" {
+			continue
+		}
+
+		rename, ok := directiveArgs(vs.Doc, enumNameComment)
+		if !ok {
+			rename, ok = directiveArgs(vs.Comment, enumNameComment)
+		}
+		for _, name := range vs.Names {
+			if name.Name == "_" {
+				continue
+			}
+			c := name.Name
+			if ok && rename != "" && len(vs.Names) == 1 {
+				c += "=" + rename
 			}
-			if strings.HasPrefix(comment, structComment) {
-				return false, true
+			if v.EnumConsts == nil {
+				v.EnumConsts = map[string][]string{}
 			}
+			v.EnumConsts[typ] = append(v.EnumConsts[typ], c)
 		}
 	}
+}
 
-	return
+// addTypes adds the types to generate marshalers for.
+func (v *visitor) addTypes(names ...string) {
+	v.StructNames = append(v.StructNames, names...)
+	if v.caseInsensitive {
+		v.CaseInsensitiveNames = append(v.CaseInsensitiveNames, names...)
+	}
+}
+
+// instantiations returns the instantiations of the generic type name listed
+// after the easyjson:json directive, e.g. '//easyjson:json Page[User] Page[int]'.
+func (v *visitor) instantiations(name string, comments *ast.CommentGroup) []string {
+	var types []string
+	for _, comment := range commentLines(comments) {
+		if !strings.HasPrefix(comment, structComment) {
+			continue
+		}
+		for _, t := range splitTypeList(comment[len(structComment):]) {
+			if !strings.HasPrefix(t, name+"[") {
+				continue
+			}
+			types = append(types, t)
+			for _, m := range qualifiedIdentRegexp.FindAllStringSubmatch(t, -1) {
+				v.addImport(m[1])
+			}
+		}
+	}
+	return types
+}
+
+// addImport records the import of the current file with the given name so
+// that it is available to the bootstrapping code.
+func (v *visitor) addImport(name string) {
+	path, ok := v.imports[name]
+	if !ok {
+		return
+	}
+	spec := name + " " + strconv.Quote(path)
+	for _, s := range v.Imports {
+		if s == spec {
+			return
+		}
+	}
+	v.Imports = append(v.Imports, spec)
+}
+
+// splitTypeList splits s into whitespace separated type expressions, keeping
+// type argument lists like 'Pair[string, int]' intact.
+func splitTypeList(s string) []string {
+	var (
+		types []string
+		depth int
+		start = -1
+	)
+	for i, c := range s {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0 && (c == ' ' || c == '\t'):
+			if start >= 0 {
+				types = append(types, s[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		types = append(types, s[start:])
+	}
+	return types
 }
 
 func (v *visitor) Visit(n ast.Node) (w ast.Visitor) {
@@ -66,12 +254,29 @@ func (v *visitor) Visit(n ast.Node) (w ast.Visitor) {
 		return v
 	case *ast.File:
 		v.PkgName = n.Name.String()
+		v.imports = map[string]string{}
+		for _, imp := range n.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+			name := path[strings.LastIndex(path, "/")+1:]
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			v.imports[name] = path
+		}
 		return v
 
 	case *ast.GenDecl:
+		if n.Tok == token.CONST {
+			v.addConsts(n)
+			return nil
+		}
+
 		skip, explicit := v.needType(n.Doc)
 
-		if skip || explicit {
+		if skip || explicit || hasDirective(n.Doc, caseInsensitiveComment) || hasDirective(n.Doc, unionComment) || hasDirective(n.Doc, enumComment) {
 			for _, nc := range n.Specs {
 				switch nct := nc.(type) {
 				case *ast.TypeSpec:
@@ -86,21 +291,43 @@ func (v *visitor) Visit(n ast.Node) (w ast.Visitor) {
 		if skip {
 			return nil
 		}
+		if args, ok := union(n.Doc); ok {
+			if v.Unions == nil {
+				v.Unions = map[string]string{}
+			}
+			v.Unions[n.Name.String()] = args
+			return nil
+		}
+		if args, ok := directiveArgs(n.Doc, enumComment); ok {
+			if v.Enums == nil {
+				v.Enums = map[string]string{}
+			}
+			v.Enums[n.Name.String()] = args
+			v.StructNames = append(v.StructNames, n.Name.String())
+			return nil
+		}
 		if !explicit && !v.AllStructs {
 			return nil
 		}
 
 		v.name = n.Name.String()
+		v.caseInsensitive = hasDirective(n.Doc, caseInsensitiveComment)
+
+		// Generic types are generated only for explicitly listed instantiations.
+		if n.TypeParams != nil && n.TypeParams.NumFields() > 0 {
+			v.addTypes(v.instantiations(v.name, n.Doc)...)
+			return nil
+		}
 
 		// Allow to specify non-structs explicitly independent of '-all' flag.
 		if explicit {
-			v.StructNames = append(v.StructNames, v.name)
+			v.addTypes(v.name)
 			return nil
 		}
 
 		return v
 	case *ast.StructType:
-		v.StructNames = append(v.StructNames, v.name)
+		v.addTypes(v.name)
 		return nil
 	}
 	return nil
@@ -130,6 +357,13 @@ func (p *Parser) Parse(fname string, isDir bool) error {
 
 		ast.Walk(&visitor{Parser: p}, f)
 	}
+
+	// Only the constants of the enum types are needed.
+	for typ := range p.EnumConsts {
+		if _, ok := p.Enums[typ]; !ok {
+			delete(p.EnumConsts, typ)
+		}
+	}
 	return nil
 }
 
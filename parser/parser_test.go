@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func Test_splitTypeList(t *testing.T) {
+	tests := map[string]struct {
+		in   string
+		want []string
+	}{
+		"empty": {
+			in:   "",
+			want: nil,
+		},
+		"single": {
+			in:   " Page[User]",
+			want: []string{"Page[User]"},
+		},
+		"multiple": {
+			in:   " Page[User]\tPage[int]  Page[opt.Int] ",
+			want: []string{"Page[User]", "Page[int]", "Page[opt.Int]"},
+		},
+		"multiple type arguments": {
+			in:   " Pair[string, User] Pair[map[string]int, []User]",
+			want: []string{"Pair[string, User]", "Pair[map[string]int, []User]"},
+		},
+	}
+	for name := range tests {
+		tt := tests[name]
+		t.Run(name, func(t *testing.T) {
+			if got := splitTypeList(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitTypeList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_caseInsensitiveDirective(t *testing.T) {
+	const src = `package p
+
+//easyjson:json
+//easyjson:case_insensitive
+type A struct{}
+
+//easyjson:case_insensitive
+type B struct{}
+
+type C struct{}
+
+//easyjson:case_insensitive
+//easyjson:json Page[A]
+type Page[T any] struct{}
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Parser{AllStructs: true}
+	ast.Walk(&visitor{Parser: p}, f)
+
+	if want := []string{"A", "B", "C", "Page[A]"}; !reflect.DeepEqual(p.StructNames, want) {
+		t.Errorf("StructNames = %q, want %q", p.StructNames, want)
+	}
+	if want := []string{"A", "B", "Page[A]"}; !reflect.DeepEqual(p.CaseInsensitiveNames, want) {
+		t.Errorf("CaseInsensitiveNames = %q, want %q", p.CaseInsensitiveNames, want)
+	}
+}
+
+func Test_unionDirective(t *testing.T) {
+	const src = `package p
+
+//easyjson:union type circle=Circle square=*Square
+type Shape interface{ Area() float64 }
+
+type (
+	//easyjson:union kind a=A
+	Node interface{}
+)
+
+type Circle struct{}
+
+type Square struct{}
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Parser{AllStructs: true}
+	ast.Walk(&visitor{Parser: p}, f)
+
+	if want := []string{"Circle", "Square"}; !reflect.DeepEqual(p.StructNames, want) {
+		t.Errorf("StructNames = %q, want %q", p.StructNames, want)
+	}
+	want := map[string]string{
+		"Shape": "type circle=Circle square=*Square",
+		"Node":  "kind a=A",
+	}
+	if !reflect.DeepEqual(p.Unions, want) {
+		t.Errorf("Unions = %q, want %q", p.Unions, want)
+	}
+}
+
+func Test_enumDirective(t *testing.T) {
+	const src = `package p
+
+//easyjson:enum trim_prefix=Color snake_case
+type Color int
+
+const (
+	ColorRed Color = iota
+	ColorDarkBlue //easyjson:name navy
+	_
+	// Deprecated: use ColorRed.
+	//easyjson:name crimson
+	ColorCrimson
+	Other = 1
+	ColorDefault Color = ColorRed
+)
+
+type (
+	//easyjson:enum
+	Level uint8
+)
+
+const LevelDebug, LevelInfo Level = 1, 2
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Parser{}
+	ast.Walk(&visitor{Parser: p}, f)
+
+	if want := []string{"Color", "Level"}; !reflect.DeepEqual(p.StructNames, want) {
+		t.Errorf("StructNames = %q, want %q", p.StructNames, want)
+	}
+	if want := map[string]string{"Color": "trim_prefix=Color snake_case", "Level": ""}; !reflect.DeepEqual(p.Enums, want) {
+		t.Errorf("Enums = %q, want %q", p.Enums, want)
+	}
+	want := map[string][]string{
+		"Color": {"ColorRed", "ColorDarkBlue=navy", "ColorCrimson=crimson", "ColorDefault"},
+		"Level": {"LevelDebug", "LevelInfo"},
+	}
+	if !reflect.DeepEqual(p.EnumConsts, want) {
+		t.Errorf("EnumConsts = %q, want %q", p.EnumConsts, want)
+	}
+}
@@ -0,0 +1,451 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+// PatchOp is an operation of a JSON patch (RFC 6902).
+type PatchOp struct {
+	Op    string     // "add", "remove", "replace", "move", "copy" or "test".
+	Path  string     // JSON pointer (RFC 6901) to the target location.
+	From  string     // JSON pointer to the source location of "move" and "copy" operations.
+	Value RawMessage // Value of "add", "replace" and "test" operations.
+}
+
+// Patch is a JSON patch (RFC 6902): a list of operations applied to a JSON document in order.
+// Patches are returned by the DiffJSON methods generated with the -diff_json option.
+type Patch []PatchOp
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// EscapePointerToken escapes the object member name for use in a JSON pointer (RFC 6901),
+// e.g. "a/b" becomes "a~1b".
+func EscapePointerToken(name string) string {
+	return pointerEscaper.Replace(name)
+}
+
+// hasValue returns whether the value is a member of the operation.
+func (op *PatchOp) hasValue() bool {
+	return op.Op == "add" || op.Op == "replace" || op.Op == "test"
+}
+
+// hasFrom returns whether the from location is a member of the operation.
+func (op *PatchOp) hasFrom() bool {
+	return op.Op == "move" || op.Op == "copy"
+}
+
+// MarshalEasyJSON does JSON marshaling using easyjson interface.
+func (op *PatchOp) MarshalEasyJSON(w *jwriter.Writer) {
+	w.ObjectStart()
+	w.ElemStart()
+	w.String("op")
+	w.Colon()
+	w.String(op.Op)
+	w.RawByte(',')
+	w.ElemStart()
+	w.String("path")
+	w.Colon()
+	w.String(op.Path)
+	if op.hasFrom() {
+		w.RawByte(',')
+		w.ElemStart()
+		w.String("from")
+		w.Colon()
+		w.String(op.From)
+	}
+	if op.hasValue() {
+		w.RawByte(',')
+		w.ElemStart()
+		w.String("value")
+		w.Colon()
+		op.Value.MarshalEasyJSON(w)
+	}
+	w.ObjectEnd()
+}
+
+// UnmarshalEasyJSON does JSON unmarshaling using easyjson interface.
+func (op *PatchOp) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	*op = PatchOp{}
+	if l.IsNull() {
+		l.Skip()
+		return
+	}
+
+	l.Delim('{')
+	for !l.IsDelim('}') && l.Ok() {
+		key := l.UnsafeFieldName(false)
+		l.WantColon()
+		switch key {
+		case "op":
+			op.Op = l.String()
+		case "path":
+			op.Path = l.String()
+		case "from":
+			op.From = l.String()
+		case "value":
+			op.Value = append(RawMessage(nil), l.Raw()...)
+		default:
+			l.SkipRecursive()
+		}
+		l.WantComma()
+	}
+	l.Delim('}')
+}
+
+// MarshalEasyJSON does JSON marshaling using easyjson interface.
+func (p Patch) MarshalEasyJSON(w *jwriter.Writer) {
+	if p == nil && (w.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		w.RawString("null")
+		return
+	}
+
+	w.ArrayStart()
+	for i := range p {
+		if i > 0 {
+			w.RawByte(',')
+		}
+		w.ElemStart()
+		p[i].MarshalEasyJSON(w)
+	}
+	w.ArrayEnd()
+}
+
+// UnmarshalEasyJSON does JSON unmarshaling using easyjson interface.
+func (p *Patch) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	*p = nil
+	if l.IsNull() {
+		l.Skip()
+		return
+	}
+
+	l.Delim('[')
+	for !l.IsDelim(']') && l.Ok() {
+		var op PatchOp
+		op.UnmarshalEasyJSON(l)
+		*p = append(*p, op)
+		l.WantComma()
+	}
+	l.Delim(']')
+}
+
+// MarshalJSON implements encoding/json.Marshaler interface.
+func (p Patch) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	p.MarshalEasyJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements encoding/json.Unmarshaler interface.
+func (p *Patch) UnmarshalJSON(data []byte) error {
+	l := jlexer.Lexer{Data: data}
+	p.UnmarshalEasyJSON(&l)
+	l.Consumed()
+	return l.Error()
+}
+
+// Apply applies the operations of the patch to the JSON document in data and returns the
+// patched document. If an operation fails, e.g. a "test" operation or the removal of a missing
+// member, no document is returned. Objects keep the order of their members, and numbers are
+// output as they are in the input.
+func (p Patch) Apply(data []byte) ([]byte, error) {
+	doc, err := parsePatchValue(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range p {
+		if doc, err = p[i].apply(doc); err != nil {
+			return nil, fmt.Errorf("easyjson: patch operation %d (%s %s): %v", i, p[i].Op, p[i].Path, err)
+		}
+	}
+
+	w := jwriter.Writer{}
+	marshalOrderedValue(&w, doc)
+	return w.BuildBytes()
+}
+
+// parsePatchValue decodes the JSON in data into the values of OrderedMap members.
+func parsePatchValue(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("missing value")
+	}
+	l := jlexer.Lexer{Data: data, UseNumber: true}
+	v := unmarshalOrderedValue(&l)
+	l.Consumed()
+	return v, l.Error()
+}
+
+// parsePointer returns the reference tokens of the JSON pointer.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = pointerUnescaper.Replace(t)
+	}
+	return tokens, nil
+}
+
+// apply applies the operation to the document and returns the patched document.
+func (op *PatchOp) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	switch {
+	case op.hasValue():
+		if value, err = parsePatchValue(op.Value); err != nil {
+			return nil, err
+		}
+	case op.hasFrom():
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" && len(from) < len(path) && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move a value into itself")
+		}
+		if value, err = getPatchValue(doc, from); err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if doc, err = removePatchValue(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			value = copyPatchValue(value)
+		}
+	}
+
+	switch op.Op {
+	case "add", "move", "copy":
+		return updatePatchValue(doc, path, func(parent interface{}, token string) (interface{}, error) {
+			switch parent := parent.(type) {
+			case *OrderedMap:
+				parent.Set(token, value)
+				return parent, nil
+			case []interface{}:
+				i := len(parent)
+				if token != "-" {
+					if i, err = arrayIndex(token, len(parent)+1); err != nil {
+						return nil, err
+					}
+				}
+				parent = append(parent, nil)
+				copy(parent[i+1:], parent[i:])
+				parent[i] = value
+				return parent, nil
+			}
+			return nil, fmt.Errorf("cannot add member %q to %s", token, patchValueKind(parent))
+		}, value)
+
+	case "remove":
+		return removePatchValue(doc, path)
+
+	case "replace":
+		return updatePatchValue(doc, path, func(parent interface{}, token string) (interface{}, error) {
+			switch parent := parent.(type) {
+			case *OrderedMap:
+				if _, ok := parent.Get(token); !ok {
+					return nil, fmt.Errorf("member %q not found", token)
+				}
+				parent.Set(token, value)
+				return parent, nil
+			case []interface{}:
+				i, err := arrayIndex(token, len(parent))
+				if err != nil {
+					return nil, err
+				}
+				parent[i] = value
+				return parent, nil
+			}
+			return nil, fmt.Errorf("member %q not found in %s", token, patchValueKind(parent))
+		}, value)
+
+	case "test":
+		v, err := getPatchValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equalPatchValues(v, value) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// updatePatchValue calls update with the parent of the value at the path and the last token
+// of the path, and sets the parent to the returned value. If the path is empty, the document
+// is replaced with root.
+func updatePatchValue(doc interface{}, path []string, update func(parent interface{}, token string) (interface{}, error), root interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return root, nil
+	}
+	if len(path) == 1 {
+		return update(doc, path[0])
+	}
+
+	child, err := getPatchValue(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = updatePatchValue(child, path[1:], update, root); err != nil {
+		return nil, err
+	}
+	switch doc := doc.(type) {
+	case *OrderedMap:
+		doc.Set(path[0], child)
+	case []interface{}:
+		i, _ := arrayIndex(path[0], len(doc))
+		doc[i] = child
+	}
+	return doc, nil
+}
+
+// getPatchValue returns the value at the path of the document.
+func getPatchValue(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch v := doc.(type) {
+		case *OrderedMap:
+			var ok bool
+			if doc, ok = v.Get(token); !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+		case []interface{}:
+			i, err := arrayIndex(token, len(v))
+			if err != nil {
+				return nil, err
+			}
+			doc = v[i]
+		default:
+			return nil, fmt.Errorf("member %q not found in %s", token, patchValueKind(doc))
+		}
+	}
+	return doc, nil
+}
+
+// removePatchValue removes the value at the path of the document.
+func removePatchValue(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	return updatePatchValue(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch parent := parent.(type) {
+		case *OrderedMap:
+			if _, ok := parent.Get(token); !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			parent.Delete(token)
+			return parent, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(parent))
+			if err != nil {
+				return nil, err
+			}
+			return append(parent[:i], parent[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("member %q not found in %s", token, patchValueKind(parent))
+	}, nil)
+}
+
+// arrayIndex returns the index in the token, which must be less than n.
+func arrayIndex(token string, n int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || token != strconv.Itoa(i) {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i >= n {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// copyPatchValue returns a deep copy of the value.
+func copyPatchValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *OrderedMap:
+		m := &OrderedMap{values: make(map[string]interface{}, len(v.values))}
+		for _, key := range v.keys {
+			m.Set(key, copyPatchValue(v.values[key]))
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, elem := range v {
+			a[i] = copyPatchValue(elem)
+		}
+		return a
+	}
+	return v
+}
+
+// equalPatchValues reports whether the values are equal as defined for the "test" operation:
+// numbers are compared by value and the members of objects regardless of their order.
+func equalPatchValues(a, b interface{}) bool {
+	switch a := a.(type) {
+	case *OrderedMap:
+		b, ok := b.(*OrderedMap)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		for _, key := range a.keys {
+			v, ok := b.Get(key)
+			if !ok || !equalPatchValues(a.values[key], v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalPatchValues(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if a == b {
+			return true
+		}
+		x, errA := a.Float64()
+		y, errB := b.Float64()
+		return errA == nil && errB == nil && x == y
+	}
+	return a == b
+}
+
+// patchValueKind returns the kind of the value for error messages.
+func patchValueKind(v interface{}) string {
+	switch v.(type) {
+	case *OrderedMap:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case nil:
+		return "null"
+	}
+	return "a scalar value"
+}
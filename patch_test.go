@@ -0,0 +1,74 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPatchApply(t *testing.T) {
+	doc := `{"b":{"c":[1,2]},"a":"x","big":12345678901234567890}`
+	for _, test := range []struct {
+		patch string
+		want  string
+		err   string
+	}{
+		{patch: `[]`, want: doc},
+		{patch: `[{"op":"add","path":"/d","value":null}]`, want: `{"b":{"c":[1,2]},"a":"x","big":12345678901234567890,"d":null}`},
+		{patch: `[{"op":"add","path":"/b/c/1","value":3}]`, want: `{"b":{"c":[1,3,2]},"a":"x","big":12345678901234567890}`},
+		{patch: `[{"op":"add","path":"/b/c/-","value":3}]`, want: `{"b":{"c":[1,2,3]},"a":"x","big":12345678901234567890}`},
+		{patch: `[{"op":"remove","path":"/b/c/0"}]`, want: `{"b":{"c":[2]},"a":"x","big":12345678901234567890}`},
+		{patch: `[{"op":"replace","path":"/a","value":{"y":1}}]`, want: `{"b":{"c":[1,2]},"a":{"y":1},"big":12345678901234567890}`},
+		{patch: `[{"op":"move","from":"/b/c","path":"/c"}]`, want: `{"b":{},"a":"x","big":12345678901234567890,"c":[1,2]}`},
+		{patch: `[{"op":"copy","from":"/b","path":"/a"},{"op":"add","path":"/a/d","value":1}]`, want: `{"b":{"c":[1,2]},"a":{"c":[1,2],"d":1},"big":12345678901234567890}`},
+		{patch: `[{"op":"test","path":"/b","value":{"c":[1.0,2e0]}}]`, want: doc},
+		{patch: `[{"op":"replace","path":"","value":[]}]`, want: `[]`},
+		{patch: `[{"op":"add","path":"/a~1b~0","value":1}]`, want: `{"b":{"c":[1,2]},"a":"x","big":12345678901234567890,"a/b~":1}`},
+
+		{patch: `[{"op":"test","path":"/a","value":"y"}]`, err: "test failed"},
+		{patch: `[{"op":"remove","path":"/d"}]`, err: `member "d" not found`},
+		{patch: `[{"op":"replace","path":"/b/c/2","value":1}]`, err: "out of range"},
+		{patch: `[{"op":"add","path":"/b/c/01","value":1}]`, err: "invalid array index"},
+		{patch: `[{"op":"move","from":"/b","path":"/b/d"}]`, err: "into itself"},
+		{patch: `[{"op":"add","path":"/d"}]`, err: "missing value"},
+		{patch: `[{"op":"add","path":"d","value":1}]`, err: "invalid JSON pointer"},
+		{patch: `[{"op":"frobnicate","path":"/a"}]`, err: "unknown operation"},
+	} {
+		var patch Patch
+		if err := Unmarshal([]byte(test.patch), &patch); err != nil {
+			t.Errorf("Unmarshal(%s) error: %v", test.patch, err)
+			continue
+		}
+
+		got, err := patch.Apply([]byte(doc))
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Apply(%s) error = %v; want %q", test.patch, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Apply(%s) error: %v", test.patch, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("Apply(%s) = %s; want %s", test.patch, got, test.want)
+		}
+	}
+}
+
+func TestPatchMarshal(t *testing.T) {
+	data := `[{"op":"add","path":"/a","value":[1]},{"op":"remove","path":"/b"},{"op":"move","path":"/c","from":"/d"}]`
+
+	var patch Patch
+	if err := json.Unmarshal([]byte(data), &patch); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	got, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	if string(got) != data {
+		t.Errorf("json.Marshal() = %s; want %s", got, data)
+	}
+}
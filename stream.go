@@ -0,0 +1,81 @@
+package easyjson
+
+import (
+	"io"
+
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+// Decoder reads and decodes a sequence of JSON values from an input stream, e.g. a
+// JSON Lines (newline-delimited JSON) file. Values may be separated by any whitespace.
+type Decoder struct {
+	l jlexer.Lexer
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{l: jlexer.Lexer{Reader: r}}
+}
+
+// More reports whether there is another value to decode in the input stream.
+func (d *Decoder) More() bool {
+	return d.l.CurrentToken() != jlexer.TokenUndef
+}
+
+// Decode reads the next JSON value from the input stream and decodes it into the object.
+// It returns io.EOF if there are no more values in the stream.
+func (d *Decoder) Decode(v Unmarshaler) error {
+	// The first token of the value is fetched before decoding it, so the decoder does
+	// not treat the value as the whole input and does not reject the values that follow.
+	if !d.More() {
+		return d.l.Error()
+	}
+
+	v.UnmarshalEasyJSON(&d.l)
+	if err := d.l.Error(); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+// Encoder writes a sequence of JSON values to an output stream, each value followed by
+// a newline, which makes the output a valid JSON Lines stream.
+type Encoder struct {
+	w io.Writer
+
+	prefix string
+	indent string
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// SetIndent instructs the encoder to indent each subsequent encoded value as if by
+// MarshalIndent.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.prefix = prefix
+	e.indent = indent
+}
+
+// Encode writes the JSON encoding of the object followed by a newline to the stream.
+func (e *Encoder) Encode(v Marshaler) error {
+	jw := jwriter.Writer{Prefix: e.prefix, Indent: e.indent}
+	if isNilInterface(v) {
+		jw.Raw(nullBytes, nil)
+	} else {
+		v.MarshalEasyJSON(&jw)
+	}
+	if jw.Error != nil {
+		return jw.Error
+	}
+	jw.RawByte('\n')
+
+	_, err := jw.DumpTo(e.w)
+	return err
+}
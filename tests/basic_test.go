@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/mailru/easyjson"
 	"github.com/mailru/easyjson/jwriter"
@@ -91,6 +93,22 @@ func TestUnmarshal(t *testing.T) {
 	}
 }
 
+func TestUnmarshalFromReader(t *testing.T) {
+	for i, test := range testCases {
+		v1 := reflect.New(reflect.TypeOf(test.Decoded).Elem()).Interface()
+		v := v1.(easyjson.Unmarshaler)
+
+		err := easyjson.UnmarshalFromReader(iotest.OneByteReader(strings.NewReader(test.Encoded)), v)
+		if err != nil {
+			t.Errorf("[%d, %T] UnmarshalFromReader() error: %v", i, test.Decoded, err)
+		}
+
+		if !reflect.DeepEqual(v, test.Decoded) {
+			t.Errorf("[%d, %T] UnmarshalFromReader(): got \n%+v\n\t\t want \n%+v", i, test.Decoded, v, test.Decoded)
+		}
+	}
+}
+
 func TestRawMessageSTD(t *testing.T) {
 	type T struct {
 		F    easyjson.RawMessage
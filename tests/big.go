@@ -0,0 +1,14 @@
+package tests
+
+import "math/big"
+
+//easyjson:json
+type BigNumbers struct {
+	Amount   *big.Int            `json:"amount"`
+	Total    big.Int             `json:"total"`
+	Quoted   *big.Int            `json:"quoted,string"`
+	Rate     *big.Float          `json:"rate"`
+	Price    big.Float           `json:"price,format=number"`
+	Balances map[string]*big.Int `json:"balances,omitempty"`
+	Fees     []big.Float         `json:"fees,omitempty,format=number"`
+}
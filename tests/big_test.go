@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+func mustBigInt(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid big.Int " + s)
+	}
+	return n
+}
+
+func mustBigFloat(s string) *big.Float {
+	f, _, err := big.ParseFloat(s, 10, 128, big.ToNearestEven)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func TestBigNumbers(t *testing.T) {
+	v := BigNumbers{
+		Amount:   mustBigInt("123456789012345678901234567890"),
+		Total:    *mustBigInt("-18446744073709551616"),
+		Quoted:   mustBigInt("18446744073709551616"),
+		Rate:     mustBigFloat("0.125"),
+		Price:    *mustBigFloat("12345678901234567890.5"),
+		Balances: map[string]*big.Int{"alice": mustBigInt("99999999999999999999")},
+		Fees:     []big.Float{*mustBigFloat("1.5"), *mustBigFloat("-0.25")},
+	}
+
+	data, err := easyjson.Marshal(v)
+	if err != nil {
+		t.Fatalf("easyjson.Marshal() error: %v", err)
+	}
+	want := `{"amount":123456789012345678901234567890,"total":-18446744073709551616,` +
+		`"quoted":"18446744073709551616","rate":"0.125","price":1.23456789012345678905e+19,` +
+		`"balances":{"alice":99999999999999999999},"fees":[1.5,-0.25]}`
+	if string(data) != want {
+		t.Errorf("easyjson.Marshal() = %s; want %s", data, want)
+	}
+
+	var got BigNumbers
+	if err := easyjson.Unmarshal(data, &got); err != nil {
+		t.Fatalf("easyjson.Unmarshal() error: %v", err)
+	}
+	if got.Amount.Cmp(v.Amount) != 0 || got.Total.Cmp(&v.Total) != 0 || got.Quoted.Cmp(v.Quoted) != 0 {
+		t.Errorf("easyjson.Unmarshal() = %v, %v, %v; want %v, %v, %v", got.Amount, &got.Total, got.Quoted, v.Amount, &v.Total, v.Quoted)
+	}
+	if got.Rate.Cmp(v.Rate) != 0 || got.Price.Cmp(&v.Price) != 0 {
+		t.Errorf("easyjson.Unmarshal() = %v, %v; want %v, %v", got.Rate, &got.Price, v.Rate, &v.Price)
+	}
+	if got.Balances["alice"].Cmp(v.Balances["alice"]) != 0 {
+		t.Errorf("easyjson.Unmarshal() balances = %v; want %v", got.Balances, v.Balances)
+	}
+	if len(got.Fees) != 2 || got.Fees[0].Cmp(&v.Fees[0]) != 0 || got.Fees[1].Cmp(&v.Fees[1]) != 0 {
+		t.Errorf("easyjson.Unmarshal() fees = %v; want %v", got.Fees, v.Fees)
+	}
+}
+
+func TestBigNumbersCompat(t *testing.T) {
+	v := BigNumbers{
+		Amount: mustBigInt("123456789012345678901234567890"),
+		Total:  *mustBigInt("42"),
+		Rate:   mustBigFloat("0.5"),
+	}
+
+	data, err := easyjson.Marshal(v)
+	if err != nil {
+		t.Fatalf("easyjson.Marshal() error: %v", err)
+	}
+	var std struct {
+		Amount *big.Int   `json:"amount"`
+		Total  big.Int    `json:"total"`
+		Rate   *big.Float `json:"rate"`
+	}
+	if err := json.Unmarshal(data, &std); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error: %v", data, err)
+	}
+	if std.Amount.Cmp(v.Amount) != 0 || std.Total.Cmp(&v.Total) != 0 || std.Rate.Cmp(v.Rate) != 0 {
+		t.Errorf("json.Unmarshal(%s) = %+v", data, std)
+	}
+
+	std.Total.SetInt64(7)
+	data, err = json.Marshal(&std)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	var got BigNumbers
+	if err := easyjson.Unmarshal(data, &got); err != nil {
+		t.Fatalf("easyjson.Unmarshal(%s) error: %v", data, err)
+	}
+	if got.Amount.Cmp(v.Amount) != 0 || got.Total.Int64() != 7 || got.Rate.Cmp(v.Rate) != 0 {
+		t.Errorf("easyjson.Unmarshal(%s) = %+v", data, got)
+	}
+}
+
+func TestBigNumbersNull(t *testing.T) {
+	data, err := easyjson.Marshal(BigNumbers{})
+	if err != nil {
+		t.Fatalf("easyjson.Marshal() error: %v", err)
+	}
+	if want := `{"amount":null,"total":0,"quoted":null,"rate":null,"price":0}`; string(data) != want {
+		t.Errorf("easyjson.Marshal() = %s; want %s", data, want)
+	}
+
+	var v BigNumbers
+	if err := easyjson.Unmarshal([]byte(`{"amount":null,"balances":{"alice":null,"bob":"1"}}`), &v); err != nil {
+		t.Fatalf("easyjson.Unmarshal() error: %v", err)
+	}
+	if v.Amount != nil || len(v.Balances) != 2 || v.Balances["alice"] != nil || v.Balances["bob"].Int64() != 1 {
+		t.Errorf("easyjson.Unmarshal() = %v, %v; want <nil>, map[alice:<nil> bob:1]", v.Amount, v.Balances)
+	}
+}
+
+func TestBigNumbersErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"amount":1.5}`,
+		`{"total":"abc"}`,
+		`{"quoted":true}`,
+		`{"rate":"Inf"}`,
+		`{"fees":[1,{}]}`,
+	} {
+		var v BigNumbers
+		if err := easyjson.Unmarshal([]byte(data), &v); err == nil {
+			t.Errorf("easyjson.Unmarshal(%s) ok; want error", data)
+		}
+	}
+
+	inf := new(big.Float).SetInf(false)
+	if _, err := easyjson.Marshal(BigNumbers{Rate: inf}); err == nil {
+		t.Errorf("easyjson.Marshal() of %v ok; want error", inf)
+	}
+}
+
+func TestNumberText(t *testing.T) {
+	for _, test := range []struct {
+		text    string
+		wantErr bool
+	}{
+		{text: "123456789012345678901234567890"},
+		{text: "-0.5e+10"},
+		{text: "0"},
+
+		{text: "", wantErr: true},
+		{text: "01", wantErr: true},
+		{text: "1.", wantErr: true},
+		{text: "+1", wantErr: true},
+		{text: "1e", wantErr: true},
+		{text: "NaN", wantErr: true},
+	} {
+		w := jwriter.Writer{}
+		w.NumberText(test.text)
+		if test.wantErr {
+			if w.Error == nil {
+				t.Errorf("NumberText(%q) ok; want error", test.text)
+			}
+			continue
+		}
+		if w.Error != nil {
+			t.Errorf("NumberText(%q) error: %v", test.text, w.Error)
+		}
+		if got := string(w.Buffer.BuildBytes()); got != test.text {
+			t.Errorf("NumberText(%q) = %s", test.text, got)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package tests
+
+import "github.com/mailru/easyjson"
+
+//easyjson:json
+type CanonicalDoc struct {
+	Strings []string            `json:"strings"`
+	Numbers []float64           `json:"numbers"`
+	Small   float32             `json:"small"`
+	Keys    map[string]int      `json:"keys"`
+	Nested  *CanonicalDoc       `json:"nested,omitempty"`
+	Raw     easyjson.RawMessage `json:"raw,omitempty"`
+	Extra   map[string]string   `json:",inline"`
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+func marshalCanonical(t *testing.T, v easyjson.Marshaler) string {
+	t.Helper()
+	// Canonical output is never indented.
+	w := jwriter.Writer{Flags: jwriter.Canonical, Indent: "  "}
+	v.MarshalEasyJSON(&w)
+	data, err := w.BuildBytes()
+	if err != nil {
+		t.Fatalf("MarshalEasyJSON() error: %v", err)
+	}
+	return string(data)
+}
+
+func TestCanonical(t *testing.T) {
+	v := CanonicalDoc{
+		Strings: []string{"€$\u000f\nA'B\"\\/<>&\b\f "},
+		Numbers: []float64{333333333.33333329, 1e30, 4.50, 2e-3, 1e-27, math.Copysign(0, -1), 1e21, 1e20, 5e-324, 1.7976931348623157e308, -1.5e-7},
+		Small:   0.1,
+		// The keys of RFC 8785, section 3.2.3, sorted by UTF-16 code units.
+		Keys:   map[string]int{"€": 1, "\r": 2, "דּ": 3, "1": 4, "\U0001F600": 5, "\u0080": 6, "ö": 7},
+		Nested: &CanonicalDoc{Small: 1, Extra: map[string]string{"a": "x"}},
+		Raw:    easyjson.RawMessage(`{"numbers":[333333333.33333329,1E30,4.50,2e-3,0.000000000000000000000000001],"string":"\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/","literals":[null,true,false]}`),
+		Extra:  map[string]string{"zz": "z", "b": "b"},
+	}
+
+	want := `{"b":"b","keys":{"\r":2,"1":4,"` + "\u0080" + `":6,"ö":7,"€":1,"😀":5,"דּ":3},` +
+		`"nested":{"a":"x","keys":null,"numbers":null,"small":1,"strings":null},` +
+		`"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27,0,1e+21,100000000000000000000,5e-324,1.7976931348623157e+308,-1.5e-7],` +
+		`"raw":{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"},` +
+		`"small":0.1,"strings":["€$\u000f\nA'B\"\\/<>&\b\f` + " " + `"],"zz":"z"}`
+	if got := marshalCanonical(t, v); got != want {
+		t.Errorf("MarshalEasyJSON() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCanonicalNaN(t *testing.T) {
+	w := jwriter.Writer{Flags: jwriter.Canonical}
+	CanonicalDoc{Numbers: []float64{math.NaN()}}.MarshalEasyJSON(&w)
+	if _, err := w.BuildBytes(); err == nil {
+		t.Errorf("MarshalEasyJSON() of NaN succeeded")
+	}
+}
+
+func TestCanonicalIntegers(t *testing.T) {
+	for _, test := range []struct {
+		Write func(w *jwriter.Writer)
+		Want  string
+	}{
+		{Write: func(w *jwriter.Writer) { w.Int64(1 << 53) }, Want: "9007199254740992"},
+		{Write: func(w *jwriter.Writer) { w.Int64(1<<53 + 1) }, Want: "9007199254740992"},
+		{Write: func(w *jwriter.Writer) { w.Int64(-(1<<53 + 3)) }, Want: "-9007199254740996"},
+		{Write: func(w *jwriter.Writer) { w.Int64(math.MaxInt64) }, Want: "9223372036854776000"},
+		{Write: func(w *jwriter.Writer) { w.Int(1<<53 + 1) }, Want: "9007199254740992"},
+		{Write: func(w *jwriter.Writer) { w.Uint64(math.MaxUint64) }, Want: "18446744073709552000"},
+		{Write: func(w *jwriter.Writer) { w.Uint(1<<53 + 1) }, Want: "9007199254740992"},
+		{Write: func(w *jwriter.Writer) { w.BigInt(new(big.Int).Lsh(big.NewInt(1), 70)) }, Want: "1.1805916207174113e+21"},
+		{Write: func(w *jwriter.Writer) { w.BigInt(big.NewInt(1<<53 + 1)) }, Want: "9007199254740992"},
+		{Write: func(w *jwriter.Writer) { w.BigFloat(big.NewFloat(0.5)) }, Want: "0.5"},
+		{Write: func(w *jwriter.Writer) { w.NumberText("9007199254740993") }, Want: "9007199254740992"},
+		{Write: func(w *jwriter.Writer) { w.NumberText("1.50E2") }, Want: "150"},
+		{Write: func(w *jwriter.Writer) { w.Raw([]byte(`[9007199254740993,-0]`), nil) }, Want: "[9007199254740992,0]"},
+	} {
+		w := jwriter.Writer{Flags: jwriter.Canonical}
+		test.Write(&w)
+		got, err := w.BuildBytes()
+		if err != nil {
+			t.Errorf("BuildBytes() error: %v", err)
+		} else if string(got) != test.Want {
+			t.Errorf("BuildBytes() = %s; want %s", got, test.Want)
+		}
+	}
+
+	w := jwriter.Writer{Flags: jwriter.Canonical}
+	w.BigInt(new(big.Int).Lsh(big.NewInt(1), 1100))
+	if _, err := w.BuildBytes(); err == nil {
+		t.Errorf("BuildBytes() of an integer out of the float64 range succeeded")
+	}
+}
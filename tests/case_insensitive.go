@@ -0,0 +1,14 @@
+package tests
+
+//easyjson:json
+//easyjson:case_insensitive
+type CaseInsensitive struct {
+	Name   string `json:"name"`
+	UserID int    `json:"userId"`
+	Tags   []string
+}
+
+//easyjson:json
+type CaseSensitive struct {
+	Name string `json:"name"`
+}
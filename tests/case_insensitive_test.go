@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+)
+
+func TestCaseInsensitive(t *testing.T) {
+	for i, test := range []struct {
+		Data string
+		Want CaseInsensitive
+	}{
+		{Data: `{"name":"a","userId":1,"Tags":["x"]}`, Want: CaseInsensitive{Name: "a", UserID: 1, Tags: []string{"x"}}},
+		{Data: `{"NAME":"a","userid":1,"tags":["x"]}`, Want: CaseInsensitive{Name: "a", UserID: 1, Tags: []string{"x"}}},
+		{Data: `{"Name":"a","name":"b"}`, Want: CaseInsensitive{Name: "b"}},
+		{Data: `{"nam":"a","user_id":1}`, Want: CaseInsensitive{}},
+	} {
+		var got CaseInsensitive
+		if err := easyjson.Unmarshal([]byte(test.Data), &got); err != nil {
+			t.Errorf("[%d] Unmarshal() error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("[%d] Unmarshal() = %+v; want %+v", i, got, test.Want)
+		}
+	}
+}
+
+func TestCaseSensitive(t *testing.T) {
+	var got CaseSensitive
+	if err := easyjson.Unmarshal([]byte(`{"Name":"a"}`), &got); err != nil {
+		t.Errorf("Unmarshal() error: %v", err)
+	}
+	if got.Name != "" {
+		t.Errorf("Unmarshal() = %+v; want empty", got)
+	}
+}
+
+func TestCaseInsensitiveProjected(t *testing.T) {
+	p, err := jlexer.NewProjection("userId", "Tags")
+	if err != nil {
+		t.Fatalf("NewProjection() error: %v", err)
+	}
+
+	var got CaseInsensitive
+	in := jlexer.Lexer{Data: []byte(`{"NAME":"a","USERID":1,"userId":2,"tags":["x"],"other":{}}`), Projection: p}
+	got.UnmarshalEasyJSON(&in)
+	if err := in.Error(); err != nil {
+		t.Errorf("UnmarshalEasyJSON() error: %v", err)
+	}
+	if want := (CaseInsensitive{UserID: 2, Tags: []string{"x"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalEasyJSON() = %+v; want %+v", got, want)
+	}
+}
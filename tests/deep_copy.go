@@ -0,0 +1,48 @@
+package tests
+
+import "time"
+
+//easyjson:json
+type CopyItem struct {
+	Name  string
+	Tags  []string
+	Attrs map[string][]int
+	Next  *CopyItem
+	When  time.Time
+	Any   interface{}
+	Pet   Pet
+	Pets  []Pet
+	Grid  [2][]int
+	*CopyMeta
+
+	Cache []int `json:"-"`
+}
+
+type CopyMeta struct {
+	Labels map[string]string
+}
+
+//easyjson:json
+type CopyList []CopyItem
+
+//easyjson:json CopyBox[int] CopyBox[CopyMeta]
+type CopyBox[T any] struct {
+	Items []T
+}
+
+//easyjson:union kind cat=Cat dog=*Dog
+type Pet interface {
+	Sound() string
+}
+
+type Cat struct {
+	Lives int
+}
+
+func (Cat) Sound() string { return "meow" }
+
+type Dog struct {
+	Toys []string
+}
+
+func (*Dog) Sound() string { return "woof" }
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDeepCopy(t *testing.T) {
+	for i, test := range []struct {
+		Value  CopyItem
+		Modify func(*CopyItem)
+	}{
+		{Value: CopyItem{Name: "a", When: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}},
+		{Value: CopyItem{Tags: []string{"x", "y"}}, Modify: func(v *CopyItem) { v.Tags[0] = "z" }},
+		{Value: CopyItem{Attrs: map[string][]int{"k": {1, 2}}}, Modify: func(v *CopyItem) { v.Attrs["k"][0] = 3 }},
+		{Value: CopyItem{Next: &CopyItem{Name: "b", Tags: []string{}}}, Modify: func(v *CopyItem) { v.Next.Name = "c" }},
+		{Value: CopyItem{Any: map[string]interface{}{"n": 1.0}}, Modify: func(v *CopyItem) { v.Any.(map[string]interface{})["n"] = 2.0 }},
+		{Value: CopyItem{Pet: &Dog{Toys: []string{"ball"}}}, Modify: func(v *CopyItem) { v.Pet.(*Dog).Toys[0] = "stick" }},
+		{Value: CopyItem{Pets: []Pet{Cat{Lives: 9}, nil, &Dog{}}}, Modify: func(v *CopyItem) { v.Pets[2].(*Dog).Toys = []string{"bone"} }},
+		{Value: CopyItem{Grid: [2][]int{{1}, nil}}, Modify: func(v *CopyItem) { v.Grid[0][0] = 2 }},
+		{Value: CopyItem{CopyMeta: &CopyMeta{Labels: map[string]string{"l": "v"}}}, Modify: func(v *CopyItem) { v.CopyMeta.Labels["l"] = "w" }},
+	} {
+		cp := test.Value.DeepCopy()
+		if !reflect.DeepEqual(cp, test.Value) {
+			t.Errorf("[%d] DeepCopy() = %+v; want %+v", i, cp, test.Value)
+			continue
+		}
+
+		// Modifications of the copy do not affect the original.
+		if test.Modify != nil {
+			test.Modify(&cp)
+			if reflect.DeepEqual(cp, test.Value) {
+				t.Errorf("[%d] original after modifying the copy = %+v", i, test.Value)
+			}
+		}
+	}
+
+	// Fields that are not encoded are copied shallowly.
+	orig := CopyItem{Cache: []int{1}}
+	if cp := orig.DeepCopy(); &cp.Cache[0] != &orig.Cache[0] {
+		t.Errorf("DeepCopy() copied the Cache field not encoded to JSON")
+	}
+}
+
+func TestDeepCopyList(t *testing.T) {
+	orig := CopyList{{Name: "a", Tags: []string{"x", "y"}}}
+	cp := orig.DeepCopy()
+	cp[0].Tags[0] = "z"
+	if orig[0].Tags[0] != "x" {
+		t.Errorf("original after modifying the copy = %+v", orig)
+	}
+
+	box := CopyBox[CopyMeta]{Items: []CopyMeta{{Labels: map[string]string{"a": "b"}}}}
+	boxCopy := box.DeepCopy()
+	boxCopy.Items[0].Labels["a"] = "c"
+	if box.Items[0].Labels["a"] != "b" {
+		t.Errorf("original after modifying the copy = %+v", box)
+	}
+
+	// Instantiations without generated methods are copied with reflection.
+	other := CopyBox[*CopyMeta]{Items: []*CopyMeta{{Labels: map[string]string{"a": "b"}}}}
+	otherCopy := other.DeepCopy()
+	if !otherCopy.Equal(other) {
+		t.Errorf("DeepCopy() = %+v; want %+v", otherCopy, other)
+	}
+	otherCopy.Items[0].Labels["a"] = "c"
+	if other.Items[0].Labels["a"] != "b" {
+		t.Errorf("original after modifying the copy = %+v", other.Items[0])
+	}
+}
+
+func TestEqual(t *testing.T) {
+	for i, test := range []struct {
+		A, B CopyItem
+		Want bool
+	}{
+		{A: CopyItem{Name: "a", Tags: []string{"x"}}, B: CopyItem{Name: "a", Tags: []string{"x"}}, Want: true},
+		{
+			A:    CopyItem{When: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+			B:    CopyItem{When: time.Date(2020, 1, 2, 4, 4, 5, 0, time.FixedZone("", 3600))},
+			Want: true,
+		},
+		{A: CopyItem{Cache: []int{1}}, B: CopyItem{}, Want: true},
+		{A: CopyItem{Name: "a"}, B: CopyItem{Name: "b"}, Want: false},
+		{A: CopyItem{Tags: []string{"x", "y"}}, B: CopyItem{Tags: []string{"x"}}, Want: false},
+		{A: CopyItem{Next: &CopyItem{Tags: []string{}}}, B: CopyItem{Next: &CopyItem{}}, Want: false},
+		{A: CopyItem{Attrs: map[string][]int{"k": {1, 2}}}, B: CopyItem{Attrs: map[string][]int{"k": {1, 3}}}, Want: false},
+		{
+			A:    CopyItem{When: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+			B:    CopyItem{When: time.Date(2020, 1, 2, 3, 4, 6, 0, time.UTC)},
+			Want: false,
+		},
+		{A: CopyItem{Any: map[string]interface{}{"n": 1.0}}, B: CopyItem{Any: map[string]interface{}{"n": 2.0}}, Want: false},
+		{A: CopyItem{Pet: &Dog{}}, B: CopyItem{Pet: Cat{}}, Want: false},
+		{A: CopyItem{Pets: []Pet{Cat{Lives: 9}}}, B: CopyItem{Pets: []Pet{Cat{Lives: 8}}}, Want: false},
+		{A: CopyItem{Grid: [2][]int{{1}, nil}}, B: CopyItem{Grid: [2][]int{{1}, {}}}, Want: false},
+		{A: CopyItem{CopyMeta: &CopyMeta{}}, B: CopyItem{}, Want: false},
+	} {
+		if got := test.A.Equal(test.B); got != test.Want {
+			t.Errorf("[%d] Equal() = %v; want %v", i, got, test.Want)
+		}
+		if got := test.B.Equal(test.A); got != test.Want {
+			t.Errorf("[%d] Equal() reversed = %v; want %v", i, got, test.Want)
+		}
+	}
+
+	if !(CopyBox[int]{Items: []int{1}}).Equal(CopyBox[int]{Items: []int{1}}) {
+		t.Errorf("CopyBox.Equal() = false; want true")
+	}
+	if (CopyBox[int]{Items: []int{1}}).Equal(CopyBox[int]{Items: []int{2}}) {
+		t.Errorf("CopyBox.Equal() = true; want false")
+	}
+}
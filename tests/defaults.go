@@ -0,0 +1,27 @@
+package tests
+
+import "github.com/mailru/easyjson/opt"
+
+type DefaultsPort uint16
+
+//easyjson:json
+type Defaults struct {
+	Host    string       `json:"host" default:"localhost"`
+	Port    DefaultsPort `json:"port" default:"8080"`
+	Debug   bool         `json:"debug" default:"true"`
+	Ratio   float64      `json:"ratio" default:"0.5"`
+	Timeout opt.Int      `json:"timeout" default:"30"`
+	Tags    []string     `json:"tags" default:"a,b"`
+	Empty   []int        `json:"empty" default:""`
+	NoDef   int          `json:"no_def"`
+}
+
+type DefaultsMeta struct {
+	Name string `json:"name" default:"n"`
+}
+
+//easyjson:json
+type DefaultsInline struct {
+	Meta     DefaultsMeta `json:",inline"`
+	MetaName string       `json:"meta_name,required" default:"m"`
+}
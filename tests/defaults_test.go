@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/opt"
+)
+
+func TestDefaults(t *testing.T) {
+	for i, test := range []struct {
+		Data string
+		Want Defaults
+	}{
+		{
+			Data: `{}`,
+			Want: Defaults{
+				Host:    "localhost",
+				Port:    8080,
+				Debug:   true,
+				Ratio:   0.5,
+				Timeout: opt.OInt(30),
+				Tags:    []string{"a", "b"},
+				Empty:   []int{},
+			},
+		},
+		{
+			Data: `{"host":"example.com","port":80,"debug":false,"ratio":0,"timeout":1,"tags":[],"empty":[1],"no_def":1}`,
+			Want: Defaults{
+				Host:    "example.com",
+				Port:    80,
+				Debug:   false,
+				Ratio:   0,
+				Timeout: opt.OInt(1),
+				Tags:    []string{},
+				Empty:   []int{1},
+				NoDef:   1,
+			},
+		},
+	} {
+		var got Defaults
+		if err := easyjson.Unmarshal([]byte(test.Data), &got); err != nil {
+			t.Errorf("[%d] Unmarshal() error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("[%d] Unmarshal() = %+v; want %+v", i, got, test.Want)
+		}
+	}
+}
+
+func TestDefaultsInline(t *testing.T) {
+	for i, test := range []struct {
+		Data string
+		Want DefaultsInline
+		Err  bool
+	}{
+		{Data: `{"meta_name":"x"}`, Want: DefaultsInline{Meta: DefaultsMeta{Name: "n"}, MetaName: "x"}},
+		{Data: `{"name":"y","meta_name":"x"}`, Want: DefaultsInline{Meta: DefaultsMeta{Name: "y"}, MetaName: "x"}},
+		{Data: `{"name":"y"}`, Err: true},
+	} {
+		var got DefaultsInline
+		err := easyjson.Unmarshal([]byte(test.Data), &got)
+		if test.Err {
+			if err == nil {
+				t.Errorf("[%d] Unmarshal() error = nil; want an error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d] Unmarshal() error: %v", i, err)
+		} else if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("[%d] Unmarshal() = %+v; want %+v", i, got, test.Want)
+		}
+	}
+}
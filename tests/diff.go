@@ -0,0 +1,36 @@
+package tests
+
+import "time"
+
+//easyjson:json
+type DiffUser struct {
+	Name     string                 `json:"name"`
+	Nick     string                 `json:"nick,omitempty"`
+	Email    *string                `json:"email"`
+	Tags     []string               `json:"tags"`
+	Address  DiffAddress            `json:"address"`
+	Manager  *DiffUser              `json:"manager,omitempty"`
+	Labels   map[string]string      `json:"labels"`
+	Places   map[string]DiffAddress `json:"places"`
+	Grid     [2]int                 `json:"grid"`
+	Updated  time.Time              `json:"updated"`
+	Any      interface{}            `json:"any"`
+	Data     []byte                 `json:"data"`
+	Extra    map[string]interface{} `json:",inline"`
+	Internal string                 `json:"-"`
+}
+
+type DiffAddress struct {
+	City      string `json:"city"`
+	Street    string `json:"street"`
+	ZipOrPost string `json:"zip/post"`
+	PostCode  string
+}
+
+//easyjson:json
+type DiffList []DiffAddress
+
+//easyjson:json DiffBox[int]
+type DiffBox[T any] struct {
+	Value T `json:"value"`
+}
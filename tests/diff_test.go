@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mailru/easyjson"
+)
+
+func TestDiffJSON(t *testing.T) {
+	a := DiffUser{
+		Name:    "alice",
+		Tags:    []string{"a", "b", "c"},
+		Address: DiffAddress{City: "Paris", Street: "Rivoli"},
+	}
+	b := DiffUser{
+		Name:     "alice",
+		Nick:     "al",
+		Tags:     []string{"a", "b"},
+		Address:  DiffAddress{City: "Nice", Street: "Rivoli", ZipOrPost: "06000", PostCode: "06000"},
+		Internal: "ignored",
+	}
+
+	patch, err := a.DiffJSON(b)
+	if err != nil {
+		t.Fatalf("DiffJSON() error: %v", err)
+	}
+	got, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	want := `[` +
+		`{"op":"add","path":"/nick","value":"al"},` +
+		`{"op":"remove","path":"/tags/2"},` +
+		`{"op":"replace","path":"/address/city","value":"Nice"},` +
+		`{"op":"replace","path":"/address/zip~1post","value":"06000"},` +
+		`{"op":"replace","path":"/address/post_code","value":"06000"}` +
+		`]`
+	if string(got) != want {
+		t.Errorf("DiffJSON() = %s; want %s", got, want)
+	}
+
+	if patch, err := a.DiffJSON(a); err != nil || len(patch) != 0 {
+		t.Errorf("DiffJSON() of equal values = %+v, %v; want no operations", patch, err)
+	}
+}
+
+func TestDiffJSONApply(t *testing.T) {
+	for _, test := range []struct {
+		A, B DiffUser
+	}{
+		{A: DiffUser{Email: new(string)}, B: DiffUser{}},
+		{A: DiffUser{}, B: DiffUser{Email: new(string)}},
+		{A: DiffUser{Tags: []string{"a", "b", "c"}}, B: DiffUser{}},
+		{A: DiffUser{Tags: []string{"a"}}, B: DiffUser{Tags: []string{"a", "d", "e"}}},
+		{A: DiffUser{Tags: []string{"a", "b"}}, B: DiffUser{Tags: []string{}}},
+		{A: DiffUser{}, B: DiffUser{Manager: &DiffUser{Name: "carol"}}},
+		{
+			A: DiffUser{Labels: map[string]string{"team": "core", "role": "dev"}},
+			B: DiffUser{Labels: map[string]string{"role": "ops", "a/b~c": "x"}},
+		},
+		{A: DiffUser{Labels: map[string]string{"team": "core"}}, B: DiffUser{}},
+		{
+			A: DiffUser{Places: map[string]DiffAddress{"home": {City: "Lyon"}}},
+			B: DiffUser{Places: map[string]DiffAddress{"home": {City: "Lyon", Street: "Garibaldi"}}},
+		},
+		{
+			A: DiffUser{Places: map[string]DiffAddress{"home": {City: "Lyon"}}},
+			B: DiffUser{Places: map[string]DiffAddress{"home": {City: "Lyon"}, "work": {City: "Lille"}}},
+		},
+		{A: DiffUser{Grid: [2]int{1, 2}}, B: DiffUser{Grid: [2]int{1, 3}}},
+		{
+			A: DiffUser{Updated: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+			B: DiffUser{Updated: time.Date(2020, 1, 2, 4, 4, 5, 0, time.UTC)},
+		},
+		{A: DiffUser{Any: map[string]interface{}{"n": 1.0}}, B: DiffUser{Any: []interface{}{"x"}}},
+		{A: DiffUser{Data: []byte("data")}, B: DiffUser{Data: []byte("other")}},
+		{A: DiffUser{Extra: map[string]interface{}{"x": 1.0}}, B: DiffUser{Extra: map[string]interface{}{"y": "z"}}},
+	} {
+		a, b := test.A, test.B
+		patch, err := a.DiffJSON(b)
+		if err != nil {
+			t.Errorf("DiffJSON(%+v, %+v) error: %v", a, b, err)
+			continue
+		}
+		if len(patch) == 0 {
+			t.Errorf("DiffJSON(%+v, %+v) returned no operations", a, b)
+			continue
+		}
+
+		data, err := easyjson.Marshal(a)
+		if err != nil {
+			t.Fatalf("easyjson.Marshal() error: %v", err)
+		}
+		patched, err := patch.Apply(data)
+		if err != nil {
+			t.Errorf("Apply(%+v) error: %v", patch, err)
+			continue
+		}
+
+		want, err := easyjson.Marshal(b)
+		if err != nil {
+			t.Fatalf("easyjson.Marshal() error: %v", err)
+		}
+		var gotDoc, wantDoc interface{}
+		if err := json.Unmarshal(patched, &gotDoc); err != nil {
+			t.Errorf("json.Unmarshal(%s) error: %v", patched, err)
+			continue
+		}
+		if err := json.Unmarshal(want, &wantDoc); err != nil {
+			t.Fatalf("json.Unmarshal() error: %v", err)
+		}
+		if !reflect.DeepEqual(gotDoc, wantDoc) {
+			t.Errorf("patched document = %s; want %s", patched, want)
+		}
+	}
+}
+
+func TestDiffJSONList(t *testing.T) {
+	a := DiffList{{City: "Paris"}}
+	b := DiffList{{City: "Nice"}, {City: "Lyon"}}
+
+	patch, err := DiffList.DiffJSON(a, b)
+	if err != nil {
+		t.Fatalf("DiffJSON() error: %v", err)
+	}
+	got, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	want := `[{"op":"replace","path":"/0/city","value":"Nice"},` +
+		`{"op":"add","path":"/1","value":{"city":"Lyon","street":"","zip/post":"","post_code":""}}]`
+	if string(got) != want {
+		t.Errorf("DiffJSON() = %s; want %s", got, want)
+	}
+
+	box := DiffBox[int]{Value: 1}
+	wantBox := easyjson.Patch{{Op: "replace", Path: "/value", Value: easyjson.RawMessage("2")}}
+	if patch, err := box.DiffJSON(DiffBox[int]{Value: 2}); err != nil || !reflect.DeepEqual(patch, wantBox) {
+		t.Errorf("DiffJSON() = %+v, %v; want %+v", patch, err, wantBox)
+	}
+
+	// Instantiations without a generated DiffJSON report an error.
+	if patch, err := (DiffBox[string]{Value: "a"}).DiffJSON(DiffBox[string]{Value: "b"}); err == nil {
+		t.Errorf("DiffJSON() = %+v; want an error", patch)
+	}
+}
@@ -0,0 +1,32 @@
+package tests
+
+//easyjson:enum trim_prefix=Color snake_case
+type Color int
+
+const (
+	ColorRed Color = iota + 1
+	ColorDarkBlue
+	ColorGreen //easyjson:name verde
+
+	// ColorDefault is an alias of ColorRed.
+	ColorDefault Color = ColorRed
+)
+
+//easyjson:enum trim_prefix=Level fallback=LevelUnknown
+type Level uint8
+
+const (
+	LevelUnknown Level = iota
+	LevelDebug
+	LevelInfo
+)
+
+//easyjson:json
+type Paint struct {
+	Color   Color            `json:"color"`
+	Colors  []Color          `json:"colors"`
+	Palette map[Color]int    `json:"palette"`
+	Level   Level            `json:"level"`
+	Ptr     *Color           `json:"ptr,omitempty"`
+	Levels  map[string]Level `json:"levels,omitempty"`
+}
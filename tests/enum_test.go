@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mailru/easyjson"
+)
+
+func TestEnum(t *testing.T) {
+	c := ColorGreen
+	v := Paint{
+		Color:   ColorDarkBlue,
+		Colors:  []Color{ColorRed, ColorDefault},
+		Palette: map[Color]int{ColorGreen: 1},
+		Level:   LevelInfo,
+		Ptr:     &c,
+	}
+
+	data, err := easyjson.Marshal(v)
+	if err != nil {
+		t.Fatalf("easyjson.Marshal() error: %v", err)
+	}
+	want := `{"color":"dark_blue","colors":["red","red"],"palette":{"verde":1},"level":"Info","ptr":"verde"}`
+	if string(data) != want {
+		t.Errorf("easyjson.Marshal() = %s; want %s", data, want)
+	}
+	if std, err := json.Marshal(v); err != nil || string(std) != want {
+		t.Errorf("json.Marshal() = %s, %v; want %s", std, err, want)
+	}
+
+	var got Paint
+	if err := easyjson.Unmarshal([]byte(want), &got); err != nil {
+		t.Fatalf("easyjson.Unmarshal() error: %v", err)
+	}
+	v.Colors = []Color{ColorRed, ColorRed}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("easyjson.Unmarshal() = %+v; want %+v", got, v)
+	}
+}
+
+func TestEnumDecode(t *testing.T) {
+	var v Paint
+	if err := easyjson.Unmarshal([]byte(`{"color":"default","level":"Trace","levels":{"a":"Debug","b":"x"}}`), &v); err != nil {
+		t.Fatalf("easyjson.Unmarshal() error: %v", err)
+	}
+	if v.Color != ColorRed || v.Level != LevelUnknown || v.Levels["a"] != LevelDebug || v.Levels["b"] != LevelUnknown {
+		t.Errorf("easyjson.Unmarshal() = %+v", v)
+	}
+
+	var c Color
+	if err := c.UnmarshalText([]byte("dark_blue")); err != nil || c != ColorDarkBlue {
+		t.Errorf("UnmarshalText() = %v, %v; want %v", c, err, ColorDarkBlue)
+	}
+}
+
+func TestEnumErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"color":"blue"}`,
+		`{"color":"Red"}`,
+		`{"color":1}`,
+		`{"palette":{"blue":1}}`,
+	} {
+		var v Paint
+		if err := easyjson.Unmarshal([]byte(data), &v); err == nil {
+			t.Errorf("easyjson.Unmarshal(%s) ok; want error", data)
+		}
+	}
+
+	if _, err := easyjson.Marshal(Paint{Color: Color(42)}); err == nil || !strings.Contains(err.Error(), "unknown Color value 42") {
+		t.Errorf("easyjson.Marshal() error = %v; want unknown Color value 42", err)
+	}
+	if data, err := easyjson.Marshal(Paint{Color: ColorRed, Level: Level(42)}); err != nil || !strings.Contains(string(data), `"level":"Unknown"`) {
+		t.Errorf("easyjson.Marshal() = %s, %v; want level Unknown", data, err)
+	}
+	if _, err := Color(0).MarshalText(); err == nil {
+		t.Errorf("MarshalText() ok for unknown value; want error")
+	}
+}
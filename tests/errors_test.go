@@ -283,3 +283,47 @@ func TestMultipleErrorsIntMap(t *testing.T) {
 		}
 	}
 }
+
+func TestErrorPath(t *testing.T) {
+	for i, test := range []struct {
+		Data  []byte
+		Into  interface{ UnmarshalEasyJSON(*jlexer.Lexer) }
+		Path  string
+		Field string
+		Type  string
+	}{
+		{
+			Data: []byte(`{"int":"a"}`),
+			Into: &ErrorStruct{},
+			Path: "$.int", Field: "ErrorStruct.Int", Type: "int",
+		},
+		{
+			Data: []byte(`{"int":1,"int_slice":[1,"a"]}`),
+			Into: &ErrorStruct{},
+			Path: "$.int_slice[1]", Field: "ErrorStruct.IntSlice", Type: "[]int",
+		},
+		{
+			Data: []byte(`{"int":1,"error_struct":{"slice":[1,2,{}]}}`),
+			Into: &ErrorNestedStruct{},
+			Path: "$.error_struct.slice[2]", Field: "ErrorStruct.Slice", Type: "[]int",
+		},
+		{
+			Data: []byte(`[1,2,true]`),
+			Into: &ErrorIntSlice{},
+			Path: "$[2]",
+		},
+	} {
+		l := jlexer.Lexer{Data: test.Data}
+		test.Into.UnmarshalEasyJSON(&l)
+
+		err, ok := l.Error().(*jlexer.LexerError)
+		if !ok {
+			t.Errorf("[%d] TestErrorPath(): want *jlexer.LexerError, got %v", i, l.Error())
+			continue
+		}
+		if err.Path != test.Path || err.Field != test.Field || err.Type != test.Type {
+			t.Errorf("[%d] TestErrorPath(): got path %q, field %q, type %q; want %q, %q, %q",
+				i, err.Path, err.Field, err.Type, test.Path, test.Field, test.Type)
+		}
+	}
+}
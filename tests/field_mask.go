@@ -0,0 +1,16 @@
+package tests
+
+//easyjson:json
+type MaskedUser struct {
+	ID     int               `json:"id"`
+	Name   string            `json:"name"`
+	Email  string            `json:"email,omitempty"`
+	Owner  *MaskedUser       `json:"owner,omitempty"`
+	Groups []MaskedGroup     `json:"groups"`
+	Labels map[string]string `json:"labels"`
+}
+
+type MaskedGroup struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+var maskedUserValue = MaskedUser{
+	ID:     1,
+	Name:   "ann",
+	Owner:  &MaskedUser{ID: 2, Name: "bob", Email: "bob@example.com"},
+	Groups: []MaskedGroup{{ID: 10, Name: "admins"}, {ID: 11, Name: "users"}},
+	Labels: map[string]string{"team": "core"},
+}
+
+func TestFieldMask(t *testing.T) {
+	for _, test := range []struct {
+		Paths []string
+		Want  string
+	}{
+		{
+			Paths: []string{"$"},
+			Want:  `{"id":1,"name":"ann","owner":{"id":2,"name":"bob","email":"bob@example.com","groups":null,"labels":null},"groups":[{"id":10,"name":"admins"},{"id":11,"name":"users"}],"labels":{"team":"core"}}`,
+		},
+		{
+			Paths: []string{"id", "name", "owner.email"},
+			Want:  `{"id":1,"name":"ann","owner":{"email":"bob@example.com"}}`,
+		},
+		{
+			Paths: []string{"name"},
+			Want:  `{"name":"ann"}`,
+		},
+		{
+			Paths: []string{"email", "groups[*].name", "labels.team", "labels.missing"},
+			Want:  `{"groups":[{"name":"admins"},{"name":"users"}],"labels":{"team":"core"}}`,
+		},
+		{
+			Paths: []string{"owner", "owner.name"},
+			Want:  `{"owner":{"id":2,"name":"bob","email":"bob@example.com","groups":null,"labels":null}}`,
+		},
+		{
+			Paths: nil,
+			Want:  `{}`,
+		},
+	} {
+		m, err := jwriter.NewFieldMask(test.Paths...)
+		if err != nil {
+			t.Fatalf("NewFieldMask(%q) error: %v", test.Paths, err)
+		}
+		got, err := easyjson.MarshalMasked(maskedUserValue, m)
+		if err != nil {
+			t.Errorf("MarshalMasked(%q) error: %v", test.Paths, err)
+			continue
+		}
+		if string(got) != test.Want {
+			t.Errorf("MarshalMasked(%q) = %s; want %s", test.Paths, got, test.Want)
+		}
+	}
+}
+
+func TestFieldMaskIndent(t *testing.T) {
+	m, err := jwriter.NewFieldMask("name", "groups.id")
+	if err != nil {
+		t.Fatalf("NewFieldMask() error: %v", err)
+	}
+	w := jwriter.Writer{Mask: m, Indent: "  "}
+	maskedUserValue.MarshalEasyJSON(&w)
+	got, err := w.BuildBytes()
+	if err != nil {
+		t.Fatalf("MarshalEasyJSON() error: %v", err)
+	}
+	want := `{
+  "name": "ann",
+  "groups": [
+    {
+      "id": 10
+    },
+    {
+      "id": 11
+    }
+  ]
+}`
+	if string(got) != want {
+		t.Errorf("MarshalEasyJSON() = %s; want %s", got, want)
+	}
+}
+
+func TestFieldMaskErrors(t *testing.T) {
+	for _, path := range []string{"a..b", "$a", "a[0]", `a["b`, "a."} {
+		if _, err := jwriter.NewFieldMask(path); err == nil {
+			t.Errorf("NewFieldMask(%q) succeeded; want an error", path)
+		}
+	}
+}
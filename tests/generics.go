@@ -0,0 +1,27 @@
+package tests
+
+import "github.com/mailru/easyjson/opt"
+
+//easyjson:json Page[GenericItem] Page[opt.Int]
+type Page[T any] struct {
+	Items []T
+	Next  *T `json:",omitempty"`
+	Total int
+}
+
+//easyjson:json Pair[string, GenericItem]
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type GenericItem struct {
+	Name string
+}
+
+//easyjson:json
+type GenericContainer struct {
+	Page   Page[GenericItem]
+	Counts Page[opt.Int]
+	Pairs  []Pair[string, GenericItem]
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/opt"
+)
+
+func TestGenerics(t *testing.T) {
+	for i, test := range []struct {
+		Decoded easyjson.MarshalerUnmarshaler
+		Encoded string
+	}{
+		{
+			Decoded: &Page[GenericItem]{
+				Items: []GenericItem{{Name: "a"}, {Name: "b"}},
+				Next:  &GenericItem{Name: "c"},
+				Total: 3,
+			},
+			Encoded: `{"Items":[{"Name":"a"},{"Name":"b"}],"Next":{"Name":"c"},"Total":3}`,
+		},
+		{
+			Decoded: &Page[opt.Int]{Items: []opt.Int{opt.OInt(1), opt.OInt(2)}, Total: 2},
+			Encoded: `{"Items":[1,2],"Total":2}`,
+		},
+		{
+			Decoded: &Pair[string, GenericItem]{Key: "k", Value: GenericItem{Name: "v"}},
+			Encoded: `{"Key":"k","Value":{"Name":"v"}}`,
+		},
+		{
+			Decoded: &GenericContainer{
+				Page:   Page[GenericItem]{Items: []GenericItem{{Name: "a"}}, Total: 1},
+				Counts: Page[opt.Int]{Items: []opt.Int{opt.OInt(1)}, Total: 1},
+				Pairs:  []Pair[string, GenericItem]{{Key: "k", Value: GenericItem{Name: "v"}}},
+			},
+			Encoded: `{"Page":{"Items":[{"Name":"a"}],"Total":1},"Counts":{"Items":[1],"Total":1},"Pairs":[{"Key":"k","Value":{"Name":"v"}}]}`,
+		},
+	} {
+		data, err := easyjson.Marshal(test.Decoded)
+		if err != nil {
+			t.Errorf("[%d, %T] Marshal() error: %v", i, test.Decoded, err)
+		} else if string(data) != test.Encoded {
+			t.Errorf("[%d, %T] Marshal() = %s; want %s", i, test.Decoded, data, test.Encoded)
+		}
+
+		v := reflect.New(reflect.TypeOf(test.Decoded).Elem()).Interface().(easyjson.MarshalerUnmarshaler)
+		if err := easyjson.Unmarshal([]byte(test.Encoded), v); err != nil {
+			t.Errorf("[%d, %T] Unmarshal() error: %v", i, test.Decoded, err)
+		} else if !reflect.DeepEqual(v, test.Decoded) {
+			t.Errorf("[%d, %T] Unmarshal() = %+v; want %+v", i, test.Decoded, v, test.Decoded)
+		}
+	}
+}
+
+func TestGenericsNotGenerated(t *testing.T) {
+	_, err := easyjson.Marshal(Page[int]{})
+	if err == nil || !strings.Contains(err.Error(), "no marshaler generated") {
+		t.Errorf("Marshal() error = %v; want no marshaler generated error", err)
+	}
+
+	var p Page[int]
+	err = easyjson.Unmarshal([]byte(`{}`), &p)
+	if err == nil || !strings.Contains(err.Error(), "no unmarshaler generated") {
+		t.Errorf("Unmarshal() error = %v; want no unmarshaler generated error", err)
+	}
+}
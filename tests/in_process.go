@@ -0,0 +1,24 @@
+package tests
+
+import "github.com/mailru/easyjson/opt"
+
+// The marshalers are generated with the -in_process flag, see Makefile.
+
+//easyjson:json
+type InProcess struct {
+	InProcessBase
+	Name  string `json:"name,required"`
+	Count opt.Int
+	Items []InProcessItem
+	Page  Page[GenericItem]
+}
+
+type InProcessBase struct {
+	ID int64 `json:"id,string"`
+}
+
+//easyjson:json
+type InProcessItem struct {
+	Data []byte
+	Next *InProcessItem `json:",omitempty"`
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/opt"
+)
+
+func TestInProcess(t *testing.T) {
+	v := InProcess{
+		InProcessBase: InProcessBase{ID: 1},
+		Name:          "a",
+		Count:         opt.OInt(2),
+		Items: []InProcessItem{
+			{Data: []byte("b"), Next: &InProcessItem{}},
+		},
+		Page: Page[GenericItem]{Items: []GenericItem{{Name: "c"}}, Total: 1},
+	}
+	want := `{"name":"a","Count":2,"Items":[{"Data":"Yg==","Next":{"Data":null}}],"Page":{"Items":[{"Name":"c"}],"Total":1},"id":"1"}`
+
+	data, err := easyjson.Marshal(v)
+	if err != nil {
+		t.Errorf("Marshal() error: %v", err)
+	} else if string(data) != want {
+		t.Errorf("Marshal() = %s; want %s", data, want)
+	}
+
+	var got InProcess
+	if err := easyjson.Unmarshal([]byte(want), &got); err != nil {
+		t.Errorf("Unmarshal() error: %v", err)
+	} else if !reflect.DeepEqual(got, v) {
+		t.Errorf("Unmarshal() = %+v; want %+v", got, v)
+	}
+
+	err = easyjson.Unmarshal([]byte(`{}`), &got)
+	if err == nil || err.Error() != "key 'name' is required" {
+		t.Errorf("Unmarshal() error = %v; want required key error", err)
+	}
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mailru/easyjson"
+)
+
+func TestMarshalIndent(t *testing.T) {
+	for i, test := range testCases {
+		got, err := easyjson.MarshalIndent(test.Decoded.(easyjson.Marshaler), "\t", "  ")
+		if err != nil {
+			t.Errorf("[%d, %T] MarshalIndent() error: %v", i, test.Decoded, err)
+		}
+
+		want, err := json.MarshalIndent(test.Decoded, "\t", "  ")
+		if err != nil {
+			t.Errorf("[%d, %T] json.MarshalIndent() error: %v", i, test.Decoded, err)
+		}
+
+		if string(got) != string(want) {
+			t.Errorf("[%d, %T] MarshalIndent(): got \n%s\n\t\t want \n%s", i, test.Decoded, got, want)
+		}
+	}
+}
+
+func TestEncoderSetIndent(t *testing.T) {
+	var got bytes.Buffer
+	enc := easyjson.NewEncoder(&got)
+	enc.SetIndent("", " ")
+
+	if err := enc.Encode(&SubStruct{Value: "a"}); err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+
+	want := "{\n \"Value\": \"a\",\n \"Value2\": \"\"\n}\n"
+	if got.String() != want {
+		t.Errorf("Encode(): got %q; want %q", got.String(), want)
+	}
+}
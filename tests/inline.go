@@ -0,0 +1,46 @@
+package tests
+
+type InlineTypeMeta struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+}
+
+type InlineObjectMeta struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+//easyjson:json
+type InlineObject struct {
+	TypeMeta InlineTypeMeta    `json:",inline"`
+	Meta     *InlineObjectMeta `json:",inline"`
+	Spec     string            `json:"spec"`
+}
+
+//easyjson:json
+type InlineMap struct {
+	ID    int                    `json:"id"`
+	Extra map[string]interface{} `json:",inline"`
+}
+
+//easyjson:json
+type InlineTypedMap struct {
+	Meta   InlineObjectMeta   `json:",inline"`
+	Values map[string]float64 `json:",inline"`
+}
+
+//easyjson:json
+type InlinePtr struct {
+	Spec string            `json:"spec,omitempty"`
+	Meta *InlineObjectMeta `json:",inline"`
+}
+
+//easyjson:json
+type InlineNested struct {
+	Mid InlineMid `json:",inline"`
+}
+
+type InlineMid struct {
+	Meta *InlineObjectMeta `json:",inline"`
+	Spec string            `json:"spec"`
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mailru/easyjson"
+)
+
+func TestInline(t *testing.T) {
+	for i, test := range []struct {
+		Data  string
+		Value interface{}
+		Empty interface{}
+	}{
+		{
+			Data: `{"spec":"s","name":"web","labels":{"app":"web"},"kind":"Pod","apiVersion":"v1"}`,
+			Value: &InlineObject{
+				TypeMeta: InlineTypeMeta{Kind: "Pod", APIVersion: "v1"},
+				Meta:     &InlineObjectMeta{Name: "web", Labels: map[string]string{"app": "web"}},
+				Spec:     "s",
+			},
+			Empty: &InlineObject{},
+		},
+		{
+			Data:  `{"id":1,"color":"red"}`,
+			Value: &InlineMap{ID: 1, Extra: map[string]interface{}{"color": "red"}},
+			Empty: &InlineMap{},
+		},
+		{
+			Data:  `{"id":1}`,
+			Value: &InlineMap{ID: 1},
+			Empty: &InlineMap{},
+		},
+		{
+			Data:  `{"name":"n","x":1.5}`,
+			Value: &InlineTypedMap{Meta: InlineObjectMeta{Name: "n"}, Values: map[string]float64{"x": 1.5}},
+			Empty: &InlineTypedMap{},
+		},
+		{
+			Data:  `{"spec":"s","name":"n"}`,
+			Value: &InlineNested{Mid: InlineMid{Meta: &InlineObjectMeta{Name: "n"}, Spec: "s"}},
+			Empty: &InlineNested{},
+		},
+	} {
+		data, err := easyjson.Marshal(test.Value.(easyjson.Marshaler))
+		if err != nil {
+			t.Errorf("[%d, %T] Marshal() error: %v", i, test.Value, err)
+		} else if string(data) != test.Data {
+			t.Errorf("[%d, %T] Marshal() = %s; want %s", i, test.Value, data, test.Data)
+		}
+
+		if err := easyjson.Unmarshal([]byte(test.Data), test.Empty.(easyjson.Unmarshaler)); err != nil {
+			t.Errorf("[%d, %T] Unmarshal() error: %v", i, test.Empty, err)
+		} else if !reflect.DeepEqual(test.Empty, test.Value) {
+			t.Errorf("[%d, %T] Unmarshal() = %+v; want %+v", i, test.Empty, test.Empty, test.Value)
+		}
+	}
+}
+
+func TestInlineNilPointer(t *testing.T) {
+	for i, test := range []struct {
+		Value easyjson.Marshaler
+		Data  string
+	}{
+		{Value: &InlineObject{Spec: "x"}, Data: `{"spec":"x","kind":"","apiVersion":""}`},
+		{Value: &InlinePtr{}, Data: `{}`},
+		{Value: &InlinePtr{Spec: "x"}, Data: `{"spec":"x"}`},
+		{Value: &InlinePtr{Meta: &InlineObjectMeta{Name: "n"}}, Data: `{"name":"n"}`},
+		{Value: &InlinePtr{Spec: "x", Meta: &InlineObjectMeta{Name: "n"}}, Data: `{"spec":"x","name":"n"}`},
+	} {
+		data, err := easyjson.Marshal(test.Value)
+		if err != nil {
+			t.Errorf("[%d] Marshal() error: %v", i, err)
+		} else if string(data) != test.Data {
+			t.Errorf("[%d] Marshal() = %s; want %s", i, data, test.Data)
+		}
+	}
+}
+
+func TestInlineMapKeyCopied(t *testing.T) {
+	data := []byte(`{"id":1,"key":"value"}`)
+
+	var v InlineMap
+	if err := easyjson.Unmarshal(data, &v); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	copy(data, `{"id":1,"xxx":"value"}`)
+
+	if _, ok := v.Extra["key"]; !ok {
+		t.Errorf("Extra = %v; want key %q", v.Extra, "key")
+	}
+}
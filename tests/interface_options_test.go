@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+)
+
+func TestInterfaceOptions(t *testing.T) {
+	data := `{"Value":{"id":9007199254740993,"score":0.5,"kind":"a"},"Slice":[1,2.5],"Map":{"n":12}}`
+
+	l := jlexer.Lexer{Data: []byte(data), UseInt64: true, UseOrderedObjects: true}
+	var got NestedInterfaces
+	got.UnmarshalEasyJSON(&l)
+	if err := l.Error(); err != nil {
+		t.Fatalf("UnmarshalEasyJSON() error: %v", err)
+	}
+	want := NestedInterfaces{
+		Value: jlexer.OrderedObject{
+			{Key: "id", Value: int64(9007199254740993)},
+			{Key: "score", Value: 0.5},
+			{Key: "kind", Value: "a"},
+		},
+		Slice: []interface{}{int64(1), 2.5},
+		Map:   map[string]interface{}{"n": int64(12)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalEasyJSON() = %#v; want %#v", got, want)
+	}
+
+	out, err := easyjson.Marshal(got)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	if string(out) != data {
+		t.Errorf("Marshal() = %s; want %s", out, data)
+	}
+
+	l = jlexer.Lexer{Data: []byte(data), UseNumber: true}
+	got = NestedInterfaces{}
+	got.UnmarshalEasyJSON(&l)
+	if err := l.Error(); err != nil {
+		t.Fatalf("UnmarshalEasyJSON() error: %v", err)
+	}
+	if id := got.Value.(map[string]interface{})["id"]; id != json.Number("9007199254740993") {
+		t.Errorf("id = %#v; want json.Number", id)
+	}
+}
@@ -0,0 +1,35 @@
+package tests
+
+import "time"
+
+//easyjson:json
+type PatchUser struct {
+	Name     string                  `json:"name"`
+	Email    *string                 `json:"email"`
+	Tags     []string                `json:"tags"`
+	Address  PatchAddress            `json:"address"`
+	Manager  *PatchUser              `json:"manager"`
+	Labels   map[string]string       `json:"labels"`
+	Settings map[string]PatchAddress `json:"settings"`
+	Updated  time.Time               `json:"updated"`
+	Age      int                     `json:"age" jsonschema:"minimum=0"`
+	Data     interface{}             `json:"data"`
+	*PatchMeta
+
+	Extra map[string]interface{} `json:",inline"`
+}
+
+type PatchAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street"`
+}
+
+type PatchMeta struct {
+	Version int `json:"version"`
+}
+
+//easyjson:json PatchBox[int]
+type PatchBox[T any] struct {
+	Value T      `json:"value"`
+	Note  string `json:"note"`
+}
@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+)
+
+func TestMergePatch(t *testing.T) {
+	for _, test := range []struct {
+		Name  string
+		Value PatchUser
+		Patch string
+		Want  PatchUser
+	}{
+		{
+			Name:  "empty",
+			Value: PatchUser{Name: "alice", Tags: []string{"a", "b"}, PatchMeta: &PatchMeta{Version: 1}},
+			Patch: `{}`,
+			Want:  PatchUser{Name: "alice", Tags: []string{"a", "b"}, PatchMeta: &PatchMeta{Version: 1}},
+		},
+		{
+			Name:  "replace",
+			Value: PatchUser{Name: "alice", Tags: []string{"a", "b"}, Age: 30, PatchMeta: &PatchMeta{Version: 1}},
+			Patch: `{"name":"bob","tags":["c"],"age":31,"version":2}`,
+			Want:  PatchUser{Name: "bob", Tags: []string{"c"}, Age: 31, PatchMeta: &PatchMeta{Version: 2}},
+		},
+		{
+			Name: "clear",
+			Value: PatchUser{
+				Name:      "alice",
+				Email:     new(string),
+				Tags:      []string{"a", "b"},
+				Address:   PatchAddress{City: "Paris", Street: "Rivoli"},
+				Labels:    map[string]string{"team": "core"},
+				Updated:   time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+				PatchMeta: &PatchMeta{Version: 1},
+			},
+			Patch: `{"name":null,"email":null,"tags":null,"address":null,"labels":null,"updated":null,"version":null}`,
+			Want:  PatchUser{PatchMeta: &PatchMeta{}},
+		},
+		{
+			Name: "nested",
+			Value: PatchUser{
+				Address:   PatchAddress{City: "Paris", Street: "Rivoli"},
+				Labels:    map[string]string{"team": "core", "role": "dev"},
+				Settings:  map[string]PatchAddress{"home": {City: "Lyon", Street: "Garibaldi"}},
+				PatchMeta: &PatchMeta{},
+			},
+			Patch: `{"address":{"city":"Nice"},"labels":{"role":null,"site":"eu"},"settings":{"home":{"street":"Jaures"},"work":{"city":"Lille"}}}`,
+			Want: PatchUser{
+				Address: PatchAddress{City: "Nice", Street: "Rivoli"},
+				Labels:  map[string]string{"team": "core", "site": "eu"},
+				Settings: map[string]PatchAddress{
+					"home": {City: "Lyon", Street: "Jaures"},
+					"work": {City: "Lille"},
+				},
+				PatchMeta: &PatchMeta{},
+			},
+		},
+		{
+			Name:  "allocate",
+			Value: PatchUser{Name: "alice", PatchMeta: &PatchMeta{Version: 1}},
+			Patch: `{"manager":{"name":"carol","address":{"city":"Rome"}}}`,
+			Want: PatchUser{
+				Name:      "alice",
+				Manager:   &PatchUser{Name: "carol", Address: PatchAddress{City: "Rome"}, PatchMeta: &PatchMeta{}},
+				PatchMeta: &PatchMeta{Version: 1},
+			},
+		},
+		{
+			Name:  "inline",
+			Value: PatchUser{PatchMeta: &PatchMeta{}, Extra: map[string]interface{}{"x": 1.0, "y": "z"}},
+			Patch: `{"x":null,"w":[1]}`,
+			Want:  PatchUser{PatchMeta: &PatchMeta{}, Extra: map[string]interface{}{"y": "z", "w": []interface{}{1.0}}},
+		},
+	} {
+		got := test.Value
+		if err := easyjson.MergePatch([]byte(test.Patch), &got); err != nil {
+			t.Errorf("[%s] MergePatch() error: %v", test.Name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("[%s] MergePatch() = %+v; want %+v", test.Name, got, test.Want)
+		}
+	}
+}
+
+func TestMergePatchInterface(t *testing.T) {
+	u := PatchUser{
+		Data: map[string]interface{}{"k": []interface{}{1.0}, "m": map[string]interface{}{"n": 1.0}},
+		Extra: map[string]interface{}{
+			"x": map[string]interface{}{"a": 1.0, "b": 2.0},
+			"y": "z",
+		},
+	}
+
+	patch := `{"x":{"a":null,"c":{"d":null,"e":1}},"v":{"w":null},"data":{"k":{"a":null},"m":{"n":null,"o":[null]}}}`
+	if err := easyjson.MergePatch([]byte(patch), &u); err != nil {
+		t.Fatalf("MergePatch() error: %v", err)
+	}
+
+	wantExtra := map[string]interface{}{
+		"x": map[string]interface{}{"b": 2.0, "c": map[string]interface{}{"e": 1.0}},
+		"y": "z",
+		"v": map[string]interface{}{},
+	}
+	if !reflect.DeepEqual(u.Extra, wantExtra) {
+		t.Errorf("MergePatch() extra = %v; want %v", u.Extra, wantExtra)
+	}
+	wantData := map[string]interface{}{
+		"k": map[string]interface{}{},
+		"m": map[string]interface{}{"o": []interface{}{nil}},
+	}
+	if !reflect.DeepEqual(u.Data, wantData) {
+		t.Errorf("MergePatch() data = %v; want %v", u.Data, wantData)
+	}
+}
+
+func TestMergePatchNestedPointer(t *testing.T) {
+	manager := &PatchUser{Name: "carol", Age: 50}
+	u := PatchUser{Name: "alice", Manager: manager}
+
+	if err := easyjson.MergePatch([]byte(`{"manager":{"age":51}}`), &u); err != nil {
+		t.Fatalf("MergePatch() error: %v", err)
+	}
+	if u.Manager != manager || u.Manager.Name != "carol" || u.Manager.Age != 51 {
+		t.Errorf("MergePatch() manager = %+v; want the same manager with the age updated", u.Manager)
+	}
+}
+
+func TestMergePatchNull(t *testing.T) {
+	u := PatchUser{Name: "alice", Tags: []string{"a", "b"}, PatchMeta: &PatchMeta{Version: 1}}
+	if err := easyjson.MergePatch([]byte(`null`), &u); err != nil {
+		t.Fatalf("MergePatch() error: %v", err)
+	}
+	if !reflect.DeepEqual(u, PatchUser{}) {
+		t.Errorf("MergePatch() = %+v; want the zero value", u)
+	}
+}
+
+func TestMergePatchErrors(t *testing.T) {
+	for _, patch := range []string{
+		`[]`,
+		`{"name":1}`,
+		`{"address":"Paris"}`,
+		`{"age":-1}`,
+		`{} {}`,
+	} {
+		u := PatchUser{Name: "alice", Address: PatchAddress{City: "Paris"}}
+		if err := easyjson.MergePatch([]byte(patch), &u); err == nil {
+			t.Errorf("MergePatch(%s) error = nil; want an error", patch)
+		}
+	}
+}
+
+func TestMergePatchGeneric(t *testing.T) {
+	b := PatchBox[int]{Value: 1, Note: "a"}
+
+	l := jlexer.Lexer{Data: []byte(`{"value":2}`)}
+	b.ApplyMergePatch(&l)
+	if err := l.Error(); err != nil {
+		t.Fatalf("ApplyMergePatch() error: %v", err)
+	}
+	if want := (PatchBox[int]{Value: 2, Note: "a"}); b != want {
+		t.Errorf("ApplyMergePatch() = %+v; want %+v", b, want)
+	}
+}
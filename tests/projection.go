@@ -0,0 +1,22 @@
+package tests
+
+//easyjson:json
+type ProjectedDoc struct {
+	ID       string            `json:"id"`
+	Title    string            `json:"title"`
+	Author   *ProjectedAuthor  `json:"author"`
+	Tags     []ProjectedTag    `json:"tags"`
+	Attrs    map[string]string `json:"attrs"`
+	Rank     int               `json:"rank,required"`
+	Language string            `json:"language" default:"en"`
+}
+
+type ProjectedAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type ProjectedTag struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
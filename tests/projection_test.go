@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+)
+
+const projectedDocData = `{
+  "id": "d1",
+  "title": "Projections",
+  "author": {"name": "Ann", "email": "ann@example.com"},
+  "tags": [{"name": "json", "score": 0.5}, {"name": "go", "score": 1}],
+  "attrs": {"color": "red", "size": "xl"},
+  "rank": 3,
+  "language": "fr"
+}`
+
+func TestProjectedDecoding(t *testing.T) {
+	for _, test := range []struct {
+		Paths []string
+		Want  ProjectedDoc
+		Err   bool
+	}{
+		{
+			Paths: []string{"$"},
+			Want: ProjectedDoc{
+				ID:       "d1",
+				Title:    "Projections",
+				Author:   &ProjectedAuthor{Name: "Ann", Email: "ann@example.com"},
+				Tags:     []ProjectedTag{{Name: "json", Score: 0.5}, {Name: "go", Score: 1}},
+				Attrs:    map[string]string{"color": "red", "size": "xl"},
+				Rank:     3,
+				Language: "fr",
+			},
+		},
+		{
+			Paths: []string{"id", "author.name", "tags[*].name", "attrs.color"},
+			Want: ProjectedDoc{
+				ID:     "d1",
+				Author: &ProjectedAuthor{Name: "Ann"},
+				Tags:   []ProjectedTag{{Name: "json"}, {Name: "go"}},
+				Attrs:  map[string]string{"color": "red"},
+			},
+		},
+		{
+			Paths: []string{"$.author", "$.rank", "$.language"},
+			Want: ProjectedDoc{
+				Author:   &ProjectedAuthor{Name: "Ann", Email: "ann@example.com"},
+				Rank:     3,
+				Language: "fr",
+			},
+		},
+	} {
+		p, err := jlexer.NewProjection(test.Paths...)
+		if err != nil {
+			t.Fatalf("NewProjection(%q) error: %v", test.Paths, err)
+		}
+
+		var got ProjectedDoc
+		if err := easyjson.UnmarshalProjected([]byte(projectedDocData), &got, p); err != nil {
+			t.Errorf("UnmarshalProjected(%q) error: %v", test.Paths, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("UnmarshalProjected(%q) = %+v; want %+v", test.Paths, got, test.Want)
+		}
+
+		got = ProjectedDoc{}
+		l := jlexer.Lexer{Reader: strings.NewReader(projectedDocData), Projection: p}
+		got.UnmarshalEasyJSON(&l)
+		if err := l.Error(); err != nil {
+			t.Errorf("UnmarshalEasyJSON(%q) from reader error: %v", test.Paths, err)
+		} else if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("UnmarshalEasyJSON(%q) from reader = %+v; want %+v", test.Paths, got, test.Want)
+		}
+	}
+}
+
+func TestProjectedDefaultsAndRequired(t *testing.T) {
+	p, err := jlexer.NewProjection("title", "language", "rank")
+	if err != nil {
+		t.Fatalf("NewProjection() error: %v", err)
+	}
+	var got ProjectedDoc
+	if err := easyjson.UnmarshalProjected([]byte(`{"title":"x"}`), &got, p); err == nil {
+		t.Errorf("UnmarshalProjected() succeeded without the projected required key")
+	}
+	if got.Language != "en" {
+		t.Errorf("Language = %q; want the default %q", got.Language, "en")
+	}
+
+	p, err = jlexer.NewProjection("title")
+	if err != nil {
+		t.Fatalf("NewProjection() error: %v", err)
+	}
+	got = ProjectedDoc{}
+	if err := easyjson.UnmarshalProjected([]byte(`{"title":"x"}`), &got, p); err != nil {
+		t.Errorf("UnmarshalProjected() error: %v", err)
+	}
+	if want := (ProjectedDoc{Title: "x"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalProjected() = %+v; want %+v", got, want)
+	}
+}
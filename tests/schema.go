@@ -0,0 +1,32 @@
+package tests
+
+import (
+	"time"
+
+	"github.com/mailru/easyjson/opt"
+)
+
+//easyjson:json
+type SchemaUser struct {
+	ID        int64   `json:"id,required"`
+	FullName  string  `json:"name,required"`
+	Email     string  `json:",omitempty" jsonschema:"maxLength=100"`
+	Age       uint8   `json:",omitempty" jsonschema:"maximum=150"`
+	Score     float64 `json:",string"`
+	Tags      []string
+	Labels    map[string]string `json:",omitempty"`
+	Manager   *SchemaUser
+	Address   SchemaAddress
+	Nickname  opt.String
+	CreatedAt time.Time
+	Avatar    []byte
+	Password  string `json:"-"`
+}
+
+type SchemaAddress struct {
+	City string
+	Zip  string `json:"zip_code"`
+}
+
+//easyjson:json
+type SchemaUsers []SchemaUser
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var schemaAddressDef = `{
+	"type": "object",
+	"properties": {
+		"city": {"type": "string"},
+		"zip_code": {"type": "string"}
+	}
+}`
+
+var schemaUserProperties = `{
+	"id": {"type": "integer"},
+	"name": {"type": "string"},
+	"email": {"type": "string", "maxLength": 100},
+	"age": {"type": "integer", "minimum": 0, "maximum": 150},
+	"score": {"type": "string"},
+	"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+	"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+	"manager": {"anyOf": [{"$ref": "%s"}, {"type": "null"}]},
+	"address": {"$ref": "#/$defs/SchemaAddress"},
+	"nickname": {"type": ["string", "null"]},
+	"created_at": {"type": "string", "format": "date-time"},
+	"avatar": {"type": ["string", "null"], "contentEncoding": "base64"}
+}`
+
+func TestJSONSchema(t *testing.T) {
+	for i, test := range []struct {
+		Value interface{ JSONSchema() []byte }
+		Want  string
+	}{
+		{
+			Value: SchemaUser{},
+			Want: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"title": "SchemaUser",
+				"type": "object",
+				"properties": ` + withRef(schemaUserProperties, "#") + `,
+				"required": ["id", "name"],
+				"$defs": {"SchemaAddress": ` + schemaAddressDef + `}
+			}`,
+		},
+		{
+			Value: SchemaUsers{},
+			Want: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"title": "SchemaUsers",
+				"type": "array",
+				"items": {"$ref": "#/$defs/SchemaUser"},
+				"$defs": {
+					"SchemaAddress": ` + schemaAddressDef + `,
+					"SchemaUser": {
+						"type": "object",
+						"properties": ` + withRef(schemaUserProperties, "#/$defs/SchemaUser") + `,
+						"required": ["id", "name"]
+					}
+				}
+			}`,
+		},
+	} {
+		var got, want interface{}
+		if err := json.Unmarshal(test.Value.JSONSchema(), &got); err != nil {
+			t.Errorf("[%d, %T] JSONSchema() is not valid JSON: %v", i, test.Value, err)
+			continue
+		}
+		if err := json.Unmarshal([]byte(test.Want), &want); err != nil {
+			t.Fatalf("[%d, %T] invalid expected schema: %v", i, test.Value, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("[%d, %T] JSONSchema() = %s", i, test.Value, test.Value.JSONSchema())
+		}
+	}
+}
+
+func withRef(properties, ref string) string {
+	return strings.Replace(properties, "%s", ref, 1)
+}
@@ -0,0 +1,15 @@
+package tests
+
+import "github.com/mailru/easyjson"
+
+type SortedMapKey string
+
+//easyjson:json
+type SortedMaps struct {
+	Counts  map[string]int                 `json:"counts"`
+	Named   map[SortedMapKey]bool          `json:"named"`
+	Nested  []map[string]map[string]string `json:"nested"`
+	ByInt   map[int]string                 `json:"by_int"`
+	Ordered easyjson.OrderedMap            `json:"ordered"`
+	Extra   map[string]interface{}         `json:",inline"`
+}
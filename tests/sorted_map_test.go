@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/mailru/easyjson"
+)
+
+func TestSortedMapKeys(t *testing.T) {
+	var v SortedMaps
+	data := `{"counts":{"b":2,"c":3,"a":1},"named":{"y":true,"x":false},` +
+		`"nested":[{"q":{"2":"b","1":"a"},"p":{}}],"by_int":{"1":"a"},` +
+		`"ordered":{"z":1,"a":2},"zeta":1,"alpha":2}`
+	if err := easyjson.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+
+	want := `{"counts":{"a":1,"b":2,"c":3},"named":{"x":false,"y":true},` +
+		`"nested":[{"p":{},"q":{"1":"a","2":"b"}}],"by_int":{"1":"a"},` +
+		`"ordered":{"z":1,"a":2},"alpha":2,"zeta":1}`
+	// Encode several times, as the map iteration order is random.
+	for i := 0; i < 10; i++ {
+		got, err := easyjson.Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal() error: %v", err)
+		}
+		if string(got) != want {
+			t.Fatalf("Marshal() = %s; want %s", got, want)
+		}
+	}
+}
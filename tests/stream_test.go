@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/mailru/easyjson"
+)
+
+func TestEncoder(t *testing.T) {
+	var want bytes.Buffer
+	var got bytes.Buffer
+
+	enc := easyjson.NewEncoder(&got)
+	for i, test := range testCases {
+		want.WriteString(test.Encoded + "\n")
+
+		if err := enc.Encode(test.Decoded.(easyjson.Marshaler)); err != nil {
+			t.Errorf("[%d, %T] Encode() error: %v", i, test.Decoded, err)
+		}
+	}
+
+	if got.String() != want.String() {
+		t.Errorf("Encode(): got \n%v\n\t\t want \n%v", got.String(), want.String())
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	var data bytes.Buffer
+	for _, test := range testCases {
+		data.WriteString(test.Encoded + "\n")
+	}
+
+	dec := easyjson.NewDecoder(iotest.HalfReader(&data))
+	for i, test := range testCases {
+		v := reflect.New(reflect.TypeOf(test.Decoded).Elem()).Interface()
+
+		if err := dec.Decode(v.(easyjson.Unmarshaler)); err != nil {
+			t.Errorf("[%d, %T] Decode() error: %v", i, test.Decoded, err)
+		}
+		if !reflect.DeepEqual(v, test.Decoded) {
+			t.Errorf("[%d, %T] Decode(): got \n%+v\n\t\t want \n%+v", i, test.Decoded, v, test.Decoded)
+		}
+	}
+
+	if dec.More() {
+		t.Errorf("More() = true at the end of the stream")
+	}
+	if err := dec.Decode(&SubStruct{}); err != io.EOF {
+		t.Errorf("Decode() error = %v at the end of the stream; want %v", err, io.EOF)
+	}
+}
+
+func TestDecoderConcatenated(t *testing.T) {
+	dec := easyjson.NewDecoder(strings.NewReader(` {"Value":"a"}{"Value":"b"}` + "\r\n" + `null {"Value2":"c"}`))
+
+	var got []SubStruct
+	for dec.More() {
+		var v SubStruct
+		if err := dec.Decode(&v); err != nil {
+			t.Fatalf("Decode() error: %v", err)
+		}
+		got = append(got, v)
+	}
+
+	want := []SubStruct{{Value: "a"}, {Value: "b"}, {}, {Value2: "c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %+v; want %+v", got, want)
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	for i, test := range []struct {
+		Data string
+		Err  string
+	}{
+		{Data: `{"Value":"a"}` + "\n" + `{"Value":`, Err: io.ErrUnexpectedEOF.Error()},
+		{Data: `{"Value":"a"}` + "\n" + `{"Value":"b",]}`, Err: "parse error: syntax error near offset 27"},
+	} {
+		dec := easyjson.NewDecoder(strings.NewReader(test.Data))
+
+		var v SubStruct
+		if err := dec.Decode(&v); err != nil {
+			t.Errorf("[%d] Decode() error: %v", i, err)
+		}
+		err := dec.Decode(&v)
+		if err == nil || !strings.HasPrefix(err.Error(), test.Err) {
+			t.Errorf("[%d] Decode() error = %v; want %v", i, err, test.Err)
+		}
+	}
+}
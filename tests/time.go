@@ -0,0 +1,19 @@
+package tests
+
+import "time"
+
+//easyjson:json
+type TimeFormats struct {
+	Default   time.Time       `json:"default"`
+	Unix      time.Time       `json:"unix,format=unix"`
+	UnixMilli time.Time       `json:"unix_milli,format=unixmilli"`
+	Nano      time.Time       `json:"nano,format=rfc3339nano"`
+	Date      time.Time       `json:"date,format=2006-01-02"`
+	Kitchen   time.Time       `json:"kitchen,format=kitchen"`
+	Ptr       *time.Time      `json:"ptr,format=unix"`
+	Days      []time.Time     `json:"days,format=dateonly"`
+	Timeout   time.Duration   `json:"timeout"`
+	Interval  time.Duration   `json:"interval,format=units"`
+	Delays    []time.Duration `json:"delays,format=units"`
+	Quoted    time.Duration   `json:"quoted,string"`
+}
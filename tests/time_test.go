@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mailru/easyjson"
+)
+
+func TestTimeFormats(t *testing.T) {
+	at := time.Date(2016, 1, 2, 14, 15, 10, 123456789, time.UTC)
+	ptr := at.Truncate(time.Second)
+	v := TimeFormats{
+		Default:   at,
+		Unix:      at.Truncate(time.Second),
+		UnixMilli: at.Truncate(time.Millisecond),
+		Nano:      at,
+		Date:      time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC),
+		Kitchen:   time.Date(0, 1, 1, 14, 15, 0, 0, time.UTC),
+		Ptr:       &ptr,
+		Days:      []time.Time{time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 3, 0, 0, 0, 0, time.UTC)},
+		Timeout:   90 * time.Minute,
+		Interval:  90 * time.Minute,
+		Delays:    []time.Duration{time.Second, 1500 * time.Millisecond},
+		Quoted:    time.Second,
+	}
+
+	data, err := easyjson.Marshal(v)
+	if err != nil {
+		t.Fatalf("easyjson.Marshal() error: %v", err)
+	}
+	want := `{"default":"2016-01-02T14:15:10.123456789Z","unix":1451744110,"unix_milli":1451744110123,` +
+		`"nano":"2016-01-02T14:15:10.123456789Z","date":"2016-01-02","kitchen":"2:15PM","ptr":1451744110,` +
+		`"days":["2016-01-02","2016-01-03"],"timeout":5400000000000,"interval":"1h30m0s","delays":["1s","1.5s"],` +
+		`"quoted":"1000000000"}`
+	if string(data) != want {
+		t.Errorf("easyjson.Marshal() = %s; want %s", data, want)
+	}
+
+	var got TimeFormats
+	if err := easyjson.Unmarshal(data, &got); err != nil {
+		t.Fatalf("easyjson.Unmarshal() error: %v", err)
+	}
+	if data2, _ := easyjson.Marshal(got); string(data2) != want {
+		t.Errorf("easyjson.Marshal(easyjson.Unmarshal()) = %s; want %s", data2, want)
+	}
+	if !got.Unix.Equal(v.Unix) || !got.UnixMilli.Equal(v.UnixMilli) || !got.Ptr.Equal(*v.Ptr) || !got.Default.Equal(v.Default) {
+		t.Errorf("easyjson.Unmarshal() = %+v; want %+v", got, v)
+	}
+}
+
+func TestTimeDefaultCompat(t *testing.T) {
+	v := TimeFormats{Default: time.Date(2016, 1, 2, 14, 15, 10, 5e8, time.FixedZone("", 3600)), Timeout: time.Second}
+
+	data, err := easyjson.Marshal(v)
+	if err != nil {
+		t.Fatalf("easyjson.Marshal() error: %v", err)
+	}
+	std, _ := json.Marshal(v.Default)
+	if !strings.HasPrefix(string(data), `{"default":`+string(std)+`,`) {
+		t.Errorf("easyjson.Marshal() = %s; want default %s", data, std)
+	}
+
+	var got TimeFormats
+	if err := easyjson.Unmarshal([]byte(`{"default":null,"unix":null,"timeout":"2s","interval":3000000000}`), &got); err != nil {
+		t.Fatalf("easyjson.Unmarshal() error: %v", err)
+	}
+	if !got.Default.IsZero() || !got.Unix.IsZero() || got.Timeout != 2*time.Second || got.Interval != 3*time.Second {
+		t.Errorf("easyjson.Unmarshal() = %+v", got)
+	}
+}
+
+func TestTimeErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"default":"2016-01-02"}`,
+		`{"date":"02.01.2016"}`,
+		`{"unix":"1451744110"}`,
+		`{"interval":"1 hour"}`,
+	} {
+		var v TimeFormats
+		if err := easyjson.Unmarshal([]byte(data), &v); err == nil {
+			t.Errorf("easyjson.Unmarshal(%s) ok; want error", data)
+		}
+	}
+
+	if _, err := easyjson.Marshal(TimeFormats{Default: time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)}); err == nil {
+		t.Errorf("easyjson.Marshal() ok for year 10000; want error")
+	}
+}
@@ -0,0 +1,26 @@
+package tests
+
+//easyjson:union type circle=Circle square=*Square
+type Shape interface {
+	Area() float64
+}
+
+//easyjson:json
+type Circle struct {
+	R float64 `json:"r"`
+}
+
+func (c Circle) Area() float64 { return 3 * c.R * c.R }
+
+type Square struct {
+	Side float64 `json:"side"`
+}
+
+func (s *Square) Area() float64 { return s.Side * s.Side }
+
+//easyjson:json
+type Drawing struct {
+	Name   string  `json:"name"`
+	Main   Shape   `json:"main"`
+	Shapes []Shape `json:"shapes,omitempty"`
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+)
+
+func TestUnionMarshal(t *testing.T) {
+	for i, test := range []struct {
+		In   Drawing
+		Want string
+	}{
+		{
+			In:   Drawing{Name: "a", Main: Circle{R: 1}},
+			Want: `{"name":"a","main":{"type":"circle","r":1}}`,
+		},
+		{
+			In:   Drawing{Name: "b", Main: &Square{Side: 2}, Shapes: []Shape{Circle{R: 3}, nil, &Square{}}},
+			Want: `{"name":"b","main":{"type":"square","side":2},"shapes":[{"type":"circle","r":3},null,{"type":"square","side":0}]}`,
+		},
+		{
+			In:   Drawing{Name: "c", Main: (*Square)(nil)},
+			Want: `{"name":"c","main":null}`,
+		},
+	} {
+		data, err := easyjson.Marshal(test.In)
+		if err != nil {
+			t.Errorf("[%d] Marshal() error: %v", i, err)
+			continue
+		}
+		if string(data) != test.Want {
+			t.Errorf("[%d] Marshal() = %s; want %s", i, data, test.Want)
+		}
+	}
+}
+
+type triangle struct{}
+
+func (triangle) Area() float64 { return 0 }
+
+func TestUnionMarshalUnknownVariant(t *testing.T) {
+	_, err := easyjson.Marshal(Drawing{Main: triangle{}})
+	if err == nil || !strings.Contains(err.Error(), "tests.triangle is not a variant of union Shape") {
+		t.Errorf("Marshal() error = %v; want an unknown variant error", err)
+	}
+}
+
+func TestUnionUnmarshal(t *testing.T) {
+	for i, test := range []struct {
+		Data string
+		Want Drawing
+	}{
+		{
+			Data: `{"name":"a","main":{"type":"circle","r":1}}`,
+			Want: Drawing{Name: "a", Main: Circle{R: 1}},
+		},
+		{
+			Data: `{"main":{"side":2,"extra":[{"type":"circle"}],"type":"square"},"name":"b"}`,
+			Want: Drawing{Name: "b", Main: &Square{Side: 2}},
+		},
+		{
+			Data: `{"main":null,"shapes":[{"r":3,"type":"circle"},null,{"type":"square"}]}`,
+			Want: Drawing{Shapes: []Shape{Circle{R: 3}, nil, &Square{}}},
+		},
+	} {
+		var got Drawing
+		if err := easyjson.Unmarshal([]byte(test.Data), &got); err != nil {
+			t.Errorf("[%d] Unmarshal() error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("[%d] Unmarshal() = %+v; want %+v", i, got, test.Want)
+		}
+
+		got = Drawing{}
+		if err := easyjson.UnmarshalFromReader(strings.NewReader(test.Data), &got); err != nil {
+			t.Errorf("[%d] UnmarshalFromReader() error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("[%d] UnmarshalFromReader() = %+v; want %+v", i, got, test.Want)
+		}
+	}
+}
+
+func TestUnionUnmarshalErrors(t *testing.T) {
+	for i, test := range []struct {
+		Data   string
+		Reason string
+		Path   string
+	}{
+		{Data: `{"main":{"type":"triangle","a":1}}`, Reason: "unknown union discriminator value", Path: "$.main"},
+		{Data: `{"main":{"r":1}}`, Reason: "missing union discriminator", Path: "$.main"},
+		{Data: `{"shapes":[{"type":"circle"},{"type":1}]}`, Reason: "missing union discriminator", Path: "$.shapes[1]"},
+	} {
+		var got Drawing
+		err := easyjson.Unmarshal([]byte(test.Data), &got)
+		lexErr, ok := err.(*jlexer.LexerError)
+		if !ok {
+			t.Errorf("[%d] Unmarshal() error = %v; want a *jlexer.LexerError", i, err)
+			continue
+		}
+		if lexErr.Reason != test.Reason || lexErr.Path != test.Path {
+			t.Errorf("[%d] Unmarshal() error = %v; want reason %q at %v", i, err, test.Reason, test.Path)
+		}
+	}
+}
+
+func TestUnionVariantIgnoresDiscriminator(t *testing.T) {
+	var got Circle
+	if err := easyjson.Unmarshal([]byte(`{"type":"circle","r":2}`), &got); err != nil {
+		t.Errorf("Unmarshal() error: %v", err)
+	}
+	if got != (Circle{R: 2}) {
+		t.Errorf("Unmarshal() = %+v; want %+v", got, Circle{R: 2})
+	}
+}
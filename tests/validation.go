@@ -0,0 +1,15 @@
+package tests
+
+import "github.com/mailru/easyjson/opt"
+
+//easyjson:json
+type Validated struct {
+	Name  string   `json:"name" jsonschema:"minLength=2,maxLength=5"`
+	Code  string   `json:"code" jsonschema:"pattern=^[A-Z]{2\\,3}$"`
+	Age   int      `json:"age" jsonschema:"minimum=0,maximum=150"`
+	Ratio *float64 `json:"ratio" jsonschema:"min=0,max=1"`
+	Level opt.Int  `json:"level" jsonschema:"enum=1,enum=2,enum=3"`
+	Color string   `json:"color" jsonschema:"enum=red,enum=green"`
+	Tags  []string `json:"tags" jsonschema:"minItems=1,maxItems=2"`
+	Zip   string   `json:"zip" jsonschema:"minLength=5,pattern=^[0-9]+$"`
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/mailru/easyjson/jlexer"
+)
+
+func TestValidation(t *testing.T) {
+	for i, test := range []struct {
+		Data  string
+		Paths []string
+	}{
+		{
+			Data: `{"name":"ab","code":"AB","age":0,"ratio":1,"level":2,"color":"red","tags":["a"]}`,
+		},
+		{
+			Data: `{}`,
+		},
+		{
+			Data:  `{"name":"a","code":"abc","age":-1,"ratio":1.5,"level":4,"color":"blue","tags":[]}`,
+			Paths: []string{"$.name", "$.code", "$.age", "$.ratio", "$.level", "$.color", "$.tags"},
+		},
+		{
+			Data:  `{"name":"абвгде","code":"ABCD","age":151,"tags":["a","b","c"]}`,
+			Paths: []string{"$.name", "$.code", "$.age", "$.tags"},
+		},
+	} {
+		l := jlexer.Lexer{Data: []byte(test.Data), UseMultipleErrors: true}
+
+		var v Validated
+		v.UnmarshalEasyJSON(&l)
+		if err := l.Error(); err != nil {
+			t.Errorf("[%d] UnmarshalEasyJSON() error: %v", i, err)
+			continue
+		}
+
+		errs := l.GetNonFatalErrors()
+		if len(errs) != len(test.Paths) {
+			t.Errorf("[%d] got %d errors %v; want %d", i, len(errs), errs, len(test.Paths))
+			continue
+		}
+		for j, err := range errs {
+			if err.Path != test.Paths[j] {
+				t.Errorf("[%d] error %d at %s (%v); want at %s", i, j, err.Path, err, test.Paths[j])
+			}
+		}
+	}
+}
+
+func TestValidationFatal(t *testing.T) {
+	var v Validated
+	err := v.UnmarshalJSON([]byte(`{"age":200,"name":"a"}`))
+
+	lerr, ok := err.(*jlexer.LexerError)
+	if !ok {
+		t.Fatalf("UnmarshalJSON() error = %v; want *jlexer.LexerError", err)
+	}
+	if lerr.Path != "$.age" || lerr.Reason != "value is greater than maximum 150" {
+		t.Errorf("UnmarshalJSON() error = %v", err)
+	}
+}
+
+func TestValidationMultipleViolations(t *testing.T) {
+	l := jlexer.Lexer{Data: []byte(`{"zip":"ab"}`), UseMultipleErrors: true}
+
+	var v Validated
+	v.UnmarshalEasyJSON(&l)
+	if err := l.Error(); err != nil {
+		t.Fatalf("UnmarshalEasyJSON() error: %v", err)
+	}
+
+	errs := l.GetNonFatalErrors()
+	if len(errs) != 2 {
+		t.Fatalf("got %d errors %v; want 2", len(errs), errs)
+	}
+	for _, err := range errs {
+		if err.Path != "$.zip" {
+			t.Errorf("error at %s (%v); want at $.zip", err.Path, err)
+		}
+	}
+	if errs[0].Reason == errs[1].Reason {
+		t.Errorf("got the same error twice: %v", errs[0])
+	}
+}
@@ -25,8 +25,9 @@ func (s UnknownFieldsProxy) MarshalUnknowns(out *jwriter.Writer, first bool) {
 		} else {
 			out.RawByte(',')
 		}
+		out.ElemStart()
 		out.String(string(key))
-		out.RawByte(':')
+		out.Colon()
 		out.Raw(val, nil)
 	}
 }